@@ -0,0 +1,305 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package analyzer implements static analysis of format strings passed to
+// the formatter package functions. It is a separate module, so the formatter
+// module doesn't depend on golang.org/x/tools. It can be used with go vet:
+//
+//	cd analyzer && go install ./cmd/formattervet
+//	go vet -vettool=$(which formattervet) ./...
+//
+// Constant format strings passed to formatter.Format, formatter.MustFormat,
+// formatter.FormatWriter, formatter.FormatContext and formatter.AppendFormat
+// are checked against number, names and types of arguments. Invalid format
+// strings, placeholders referring to missing arguments, unknown named
+// placeholders and unused arguments are reported.
+package analyzer
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"strings"
+
+	"gitlab.com/tymonx/go-formatter/formatter"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const formatterPackage = "gitlab.com/tymonx/go-formatter/formatter"
+
+// gMessageArguments maps checked functions to position of message argument.
+var gMessageArguments = map[string]int{ // nolint: gochecknoglobals
+	"Format":        0,
+	"MustFormat":    0,
+	"FormatWriter":  1,
+	"FormatContext": 1,
+	"AppendFormat":  1,
+}
+
+// Analyzer checks constant format strings passed to the formatter package
+// functions.
+var Analyzer = &analysis.Analyzer{ // nolint: gochecknoglobals
+	Name:     "formatter",
+	Doc:      "check format strings passed to the formatter package functions",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+var gReportUnused = true // nolint: gochecknoglobals
+
+func init() { // nolint: gochecknoinits
+	Analyzer.Flags.BoolVar(&gReportUnused, "unused", gReportUnused, "report arguments not used by format string")
+}
+
+// arguments describes what arguments of a call can provide.
+type arguments struct {
+	count        int
+	names        map[string]bool
+	unknownNames bool
+	object       bool
+	providers    map[int]bool
+	unknown      map[int]bool
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect, _ := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	inspect.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(node ast.Node) {
+		call, _ := node.(*ast.CallExpr)
+
+		index, ok := messageArgument(pass, call)
+
+		if !ok || (index >= len(call.Args)) {
+			return
+		}
+
+		value := pass.TypesInfo.Types[call.Args[index]].Value
+
+		if (value == nil) || (value.Kind() != constant.String) {
+			return
+		}
+
+		check(pass, call, constant.StringVal(value), withoutOptions(pass, call.Args[index+1:]))
+	})
+
+	return nil, nil
+}
+
+// messageArgument returns position of message argument if call is a call to
+// checked function.
+func messageArgument(pass *analysis.Pass, call *ast.CallExpr) (int, bool) {
+	function, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+
+	if !ok || (function.Pkg() == nil) || (function.Pkg().Path() != formatterPackage) {
+		return 0, false
+	}
+
+	if signature, ok := function.Type().(*types.Signature); !ok || (signature.Recv() != nil) {
+		return 0, false
+	}
+
+	index, ok := gMessageArguments[function.Name()]
+
+	return index, ok
+}
+
+func check(pass *analysis.Pass, call *ast.CallExpr, message string, expressions []ast.Expr) {
+	references, err := formatter.References(message)
+
+	if err != nil {
+		pass.Reportf(call.Pos(), "invalid format string: %v", err)
+		return
+	}
+
+	if call.Ellipsis.IsValid() {
+		return
+	}
+
+	args := describe(pass, expressions)
+	used := make(map[int]bool)
+	automatic := false
+
+	for _, reference := range references {
+		switch {
+		case reference.Automatic:
+			automatic = true
+		case reference.Position >= 0:
+			used[reference.Position] = true
+
+			if !reference.Fallback && (reference.Position >= args.count) {
+				pass.Reportf(call.Pos(), "placeholder %s refers to missing argument, got %d arguments", reference.Name, args.count)
+			}
+		case strings.HasPrefix(reference.Name, "."):
+			if !args.object {
+				pass.Reportf(call.Pos(), "field placeholder %s requires struct argument", reference.Name)
+			}
+		case !reference.Fallback && !args.unknownNames && !args.names[reference.Name]:
+			pass.Reportf(call.Pos(), "unknown placeholder %s", reference.Name)
+		}
+	}
+
+	if !gReportUnused || automatic {
+		return
+	}
+
+	for position, expression := range expressions {
+		if !used[position] && !args.providers[position] && !args.unknown[position] {
+			pass.Reportf(expression.Pos(), "argument %d is not used by format string", position)
+		}
+	}
+}
+
+// withoutOptions returns arguments without formatter.Option values. Options
+// are removed from arguments before formatting, so they don't take positions.
+func withoutOptions(pass *analysis.Pass, expressions []ast.Expr) []ast.Expr {
+	var filtered []ast.Expr
+
+	for _, expression := range expressions {
+		if t := pass.TypesInfo.TypeOf(expression); (t == nil) || (t.String() != formatterPackage+".Option") {
+			filtered = append(filtered, expression)
+		}
+	}
+
+	return filtered
+}
+
+// describe returns names and objects that can be provided by arguments.
+func describe(pass *analysis.Pass, expressions []ast.Expr) *arguments {
+	args := &arguments{
+		count:     len(expressions),
+		names:     make(map[string]bool),
+		providers: make(map[int]bool),
+		unknown:   make(map[int]bool),
+	}
+
+	for position, expression := range expressions {
+		if name, ok := argumentName(pass, expression); ok {
+			args.providers[position] = true
+
+			if name == "" {
+				args.unknownNames = true
+			} else {
+				args.names[name] = true
+			}
+
+			continue
+		}
+
+		argumentType := pass.TypesInfo.TypeOf(expression)
+
+		if argumentType == nil {
+			args.unknown[position] = true
+			args.unknownNames = true
+
+			continue
+		}
+
+		if types.Implements(argumentType, errorType()) {
+			continue
+		}
+
+		switch t := argumentType.Underlying().(type) {
+		case *types.Interface:
+			args.unknown[position] = true
+			args.unknownNames = true
+			args.object = true
+		case *types.Struct:
+			args.providers[position] = true
+			args.object = true
+		case *types.Pointer:
+			if _, ok := t.Elem().Underlying().(*types.Struct); ok {
+				args.providers[position] = true
+				args.object = true
+			}
+		case *types.Map:
+			if basic, ok := t.Key().Underlying().(*types.Basic); ok && (basic.Kind() == types.String) {
+				args.providers[position] = true
+
+				if !mapKeys(pass, expression, args.names) {
+					args.unknownNames = true
+				}
+			}
+		}
+	}
+
+	return args
+}
+
+// argumentName returns placeholder name of formatter.Arg call. Empty name is
+// returned for name that is not constant.
+func argumentName(pass *analysis.Pass, expression ast.Expr) (string, bool) {
+	if t := pass.TypesInfo.TypeOf(expression); (t == nil) || (t.String() != formatterPackage+".Argument") {
+		return "", false
+	}
+
+	call, ok := unparen(expression).(*ast.CallExpr)
+
+	if !ok || (len(call.Args) != 2) {
+		return "", true
+	}
+
+	if value := pass.TypesInfo.Types[call.Args[0]].Value; (value != nil) && (value.Kind() == constant.String) {
+		return constant.StringVal(value), true
+	}
+
+	return "", true
+}
+
+// mapKeys adds constant keys of map composite literal to names. It returns
+// false if expression is not a map literal with constant keys.
+func mapKeys(pass *analysis.Pass, expression ast.Expr, names map[string]bool) bool {
+	literal, ok := unparen(expression).(*ast.CompositeLit)
+
+	if !ok {
+		return false
+	}
+
+	for _, element := range literal.Elts {
+		pair, ok := element.(*ast.KeyValueExpr)
+
+		if !ok {
+			return false
+		}
+
+		value := pass.TypesInfo.Types[pair.Key].Value
+
+		if (value == nil) || (value.Kind() != constant.String) {
+			return false
+		}
+
+		names[constant.StringVal(value)] = true
+	}
+
+	return true
+}
+
+func errorType() *types.Interface {
+	t, _ := types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+	return t
+}
+
+func unparen(expression ast.Expr) ast.Expr {
+	for {
+		paren, ok := expression.(*ast.ParenExpr)
+
+		if !ok {
+			return expression
+		}
+
+		expression = paren.X
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+module gitlab.com/tymonx/go-formatter/analyzer
+
+go 1.22.0
+
+require (
+	gitlab.com/tymonx/go-formatter v0.0.0-20261014072035-a0d56ae0085f
+	golang.org/x/tools v0.30.0
+)
+
+require (
+	github.com/BurntSushi/toml v1.2.1 // indirect
+	golang.org/x/mod v0.23.0 // indirect
+	golang.org/x/sync v0.11.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+)
+
+// Local development uses the formatter module from this repository.
+replace gitlab.com/tymonx/go-formatter => ../
@@ -0,0 +1,42 @@
+package a
+
+import (
+	"errors"
+	"os"
+
+	"gitlab.com/tymonx/go-formatter/formatter"
+)
+
+type person struct {
+	Name string
+}
+
+const message = "Hello {p0} {p1}"
+
+func calls(name string, values []interface{}, any interface{}, m map[string]string) {
+	formatter.Format("Hello {p0}", "world")
+	formatter.Format(message, "a", "b")
+	formatter.Format("Hello {p1}", "world") // want `placeholder p1 refers to missing argument, got 1 arguments` `argument 0 is not used by format string`
+	formatter.Format("Hello {if}")          // want `invalid format string: .*`
+	formatter.MustFormat("Hello {name}", formatter.Named{"name": "Bob"})
+	formatter.MustFormat("Hello {nick}", formatter.Named{"name": "Bob"}) // want `unknown placeholder nick`
+	formatter.MustFormat("Hello {nick}", m)
+	formatter.MustFormat("Hello {nick}") // want `unknown placeholder nick`
+	formatter.MustFormat(`Hello {nick | fallback "x"}`)
+	formatter.MustFormat("Hello {nick}", formatter.Arg("nick", name))
+	formatter.MustFormat("Hello {nick}", formatter.Arg(name, name))
+	formatter.MustFormat("Hello {.Name}", person{Name: name})
+	formatter.MustFormat("Hello {.Name}", &person{Name: name})
+	formatter.MustFormat("Hello {.Name}", any)
+	formatter.MustFormat("Hello {.Name}", name) // want `field placeholder .Name requires struct argument` `argument 0 is not used by format string`
+	formatter.MustFormat("Hello {p0}", name, 3) // want `argument 1 is not used by format string`
+	formatter.MustFormat("Hello {p0}", formatter.WithLocale("pl"), name)
+	formatter.MustFormat("Hello {p0} {p1}", name, formatter.WithLocale("pl")) // want `placeholder p1 refers to missing argument, got 1 arguments`
+	formatter.MustFormat("Hello {p}", name, 3)
+	formatter.MustFormat("Hello", errors.New("error")) // want `argument 0 is not used by format string`
+	formatter.MustFormat("Hello {p5}", values...)
+	formatter.MustFormat("Hello {lower}")
+	formatter.FormatWriter(os.Stdout, "Hello {p0}") // want `placeholder p0 refers to missing argument, got 0 arguments`
+	formatter.New().Format("Hello {p3}")
+	formatter.MustFormat(name)
+}
@@ -0,0 +1,29 @@
+// Package formatter is a stub of the formatter package used in tests.
+package formatter
+
+import "io"
+
+type Named map[string]interface{}
+
+type Argument struct {
+	Name  string
+	Value interface{}
+}
+
+type Formatter struct{}
+
+type Option func(f *Formatter)
+
+func WithLocale(locale string) Option { return func(f *Formatter) {} }
+
+func New() *Formatter { return &Formatter{} }
+
+func Arg(name string, value interface{}) Argument { return Argument{Name: name, Value: value} }
+
+func Format(message string, arguments ...interface{}) (string, error) { return "", nil }
+
+func MustFormat(message string, arguments ...interface{}) string { return "" }
+
+func FormatWriter(writer io.Writer, message string, arguments ...interface{}) error { return nil }
+
+func (f *Formatter) Format(message string, arguments ...interface{}) (string, error) { return "", nil }
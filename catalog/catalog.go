@@ -0,0 +1,508 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"gitlab.com/tymonx/go-formatter/formatter"
+	"gopkg.in/yaml.v3"
+)
+
+// KeySeparator is used to join keys of nested tables.
+const KeySeparator = "."
+
+// entries defines messages loaded from a single file.
+type entries struct {
+	messages map[string]string
+	plurals  map[string][]string
+	plural   formatter.Plural
+}
+
+type decoder func(data []byte) (*entries, error)
+
+var gDecoders = map[string]decoder{ // nolint: gochecknoglobals
+	".json": decodeTable(json.Unmarshal),
+	".yaml": decodeTable(yaml.Unmarshal),
+	".yml":  decodeTable(yaml.Unmarshal),
+	".toml": decodeTable(toml.Unmarshal),
+	".po":   decodePO,
+	".mo":   decodeMO,
+}
+
+// Catalog defines a message catalog. It maps message keys to format strings
+// rendered by formatter. Catalog is safe for concurrent use.
+type Catalog struct {
+	mutex     sync.RWMutex
+	messages  map[string]string
+	plurals   map[string][]string
+	plural    formatter.Plural
+	formatter *formatter.Formatter
+}
+
+// New creates a new empty message catalog that uses default formatter.
+func New() *Catalog {
+	return &Catalog{
+		messages:  make(map[string]string),
+		plurals:   make(map[string][]string),
+		formatter: formatter.New(),
+	}
+}
+
+// Load creates a new message catalog with messages loaded from all files
+// matching pattern in file system. Supported file formats are YAML, JSON,
+// TOML and gettext PO and MO detected by file extension.
+func Load(fsys fs.FS, pattern string) (*Catalog, error) {
+	c := New()
+
+	if err := c.Load(fsys, pattern); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// LoadFiles creates a new message catalog with messages loaded from named
+// files in file system. Unlike Load names are not patterns.
+func LoadFiles(fsys fs.FS, names ...string) (*Catalog, error) {
+	c := New()
+
+	if err := c.LoadFiles(fsys, names...); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Load loads messages from all files matching pattern in file system.
+// Supported file formats are YAML, JSON, TOML and gettext PO and MO detected
+// by file extension. In YAML, JSON and TOML files list of strings defines
+// plural forms. Message key defined more than once is reported as an error.
+// Plural rule from gettext Plural-Forms header replaces plural rule of
+// catalog.
+func (c *Catalog) Load(fsys fs.FS, pattern string) error {
+	names, err := fs.Glob(fsys, pattern)
+
+	if err != nil {
+		return err
+	}
+
+	if len(names) == 0 {
+		return fmt.Errorf("no files match pattern %q", pattern)
+	}
+
+	return c.LoadFiles(fsys, names...)
+}
+
+// LoadFiles loads messages from named files in file system the same way as
+// Load. Unlike Load names are not patterns, so they can contain characters
+// like [ or *. Message key defined more than once is reported as an error.
+func (c *Catalog) LoadFiles(fsys fs.FS, names ...string) error {
+	loaded := New()
+	defined := make(map[string]bool)
+
+	for _, name := range names {
+		e, err := loadFile(fsys, name)
+
+		if err != nil {
+			return err
+		}
+
+		for _, key := range e.keys() {
+			if defined[key] {
+				return fmt.Errorf("%s: message %q is already defined", name, key)
+			}
+
+			defined[key] = true
+		}
+
+		loaded.add(e)
+	}
+
+	c.Merge(loaded)
+
+	return nil
+}
+
+// Merge adds messages, plural forms and plural rule from other catalog.
+// Existing messages are replaced.
+func (c *Catalog) Merge(other *Catalog) *Catalog {
+	e := other.copyEntries()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.add(e)
+}
+
+// copyEntries returns copy of messages, plural forms and plural rule made
+// under read lock, so they can be used after the lock is released.
+func (c *Catalog) copyEntries() *entries {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	e := &entries{
+		messages: make(map[string]string, len(c.messages)),
+		plurals:  make(map[string][]string, len(c.plurals)),
+		plural:   c.plural,
+	}
+
+	for key, message := range c.messages {
+		e.messages[key] = message
+	}
+
+	for key, forms := range c.plurals {
+		e.plurals[key] = forms
+	}
+
+	return e
+}
+
+// Add adds message under provided key. Existing message is replaced.
+func (c *Catalog) Add(key, message string) *Catalog {
+	return c.AddMessages(map[string]string{key: message})
+}
+
+// AddMessages adds messages. Existing messages are replaced.
+func (c *Catalog) AddMessages(messages map[string]string) *Catalog {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.add(&entries{messages: messages})
+}
+
+// AddPlural adds message plural forms under provided key. Existing message
+// is replaced.
+func (c *Catalog) AddPlural(key string, forms ...string) *Catalog {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.add(&entries{plurals: map[string][]string{key: forms}})
+}
+
+// Get returns message under provided key. For message with plural forms it
+// returns the first form.
+func (c *Catalog) Get(key string) (message string, ok bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if forms, ok := c.plurals[key]; ok && (len(forms) > 0) {
+		return forms[0], true
+	}
+
+	message, ok = c.messages[key]
+
+	return message, ok
+}
+
+// GetPlural returns message plural forms under provided key.
+func (c *Catalog) GetPlural(key string) (forms []string, ok bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	forms, ok = c.plurals[key]
+
+	return append([]string(nil), forms...), ok
+}
+
+// Has returns true if message under provided key exists.
+func (c *Catalog) Has(key string) bool {
+	_, ok := c.Get(key)
+	return ok
+}
+
+// IsPlural returns true if message under provided key has plural forms.
+func (c *Catalog) IsPlural(key string) bool {
+	_, ok := c.GetPlural(key)
+	return ok
+}
+
+// Keys returns sorted message keys.
+func (c *Catalog) Keys() []string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return (&entries{messages: c.messages, plurals: c.plurals}).keys()
+}
+
+// SetFormatter sets formatter used to render messages.
+func (c *Catalog) SetFormatter(f *formatter.Formatter) *Catalog {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.formatter = f
+
+	return c
+}
+
+// GetFormatter returns formatter used to render messages.
+func (c *Catalog) GetFormatter() *formatter.Formatter {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.formatter
+}
+
+// SetPluralRule sets plural rule used to select plural form. By default
+// plural rule of formatter locale is used.
+func (c *Catalog) SetPluralRule(plural formatter.Plural) *Catalog {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.plural = plural
+
+	return c
+}
+
+// GetPluralRule returns plural rule used to select plural form.
+func (c *Catalog) GetPluralRule() formatter.Plural {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if c.plural != nil {
+		return c.plural
+	}
+
+	return formatter.GetLocale(c.formatter.GetLocale()).Plural
+}
+
+// Format renders message under provided key with arguments.
+func (c *Catalog) Format(key string, arguments ...interface{}) (string, error) {
+	message, f, err := c.message(key)
+
+	if err != nil {
+		return "", err
+	}
+
+	return f.Format(message, arguments...)
+}
+
+// MustFormat is like Format but panics if message cannot be rendered.
+func (c *Catalog) MustFormat(key string, arguments ...interface{}) string {
+	formatted, err := c.Format(key, arguments...)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return formatted
+}
+
+// FormatWriter renders message under provided key with arguments to writer.
+func (c *Catalog) FormatWriter(writer io.Writer, key string, arguments ...interface{}) error {
+	message, f, err := c.message(key)
+
+	if err != nil {
+		return err
+	}
+
+	return f.FormatWriter(writer, message, arguments...)
+}
+
+// FormatPlural renders plural form of message under provided key selected
+// for count. Count is only used to select plural form, it must be also
+// provided in arguments if message uses it. Message without plural forms is
+// rendered like with Format.
+func (c *Catalog) FormatPlural(key string, count int64, arguments ...interface{}) (string, error) {
+	message, f, err := c.pluralMessage(key, count)
+
+	if err != nil {
+		return "", err
+	}
+
+	return f.Format(message, arguments...)
+}
+
+// FormatWithLayout renders layout message under layout key with slots filled
+// by body message under body key, see formatter.Formatter.FormatWithLayout.
+func (c *Catalog) FormatWithLayout(layoutKey, bodyKey string, arguments ...interface{}) (string, error) {
+	layout, f, err := c.message(layoutKey)
+
+	if err != nil {
+		return "", err
+	}
+
+	body, _, err := c.message(bodyKey)
+
+	if err != nil {
+		return "", err
+	}
+
+	return f.Clone().AddTemplates(formatter.Templates{
+		layoutKey: layout,
+		bodyKey:   body,
+	}).FormatWithLayout(layoutKey, bodyKey, arguments...)
+}
+
+func (c *Catalog) message(key string) (string, *formatter.Formatter, error) {
+	message, ok := c.Get(key)
+
+	if !ok {
+		return "", nil, fmt.Errorf("message %q not found", key)
+	}
+
+	return message, c.GetFormatter(), nil
+}
+
+func (c *Catalog) pluralMessage(key string, count int64) (string, *formatter.Formatter, error) {
+	forms, ok := c.GetPlural(key)
+
+	if !ok {
+		return c.message(key)
+	}
+
+	if len(forms) == 0 {
+		return "", nil, fmt.Errorf("message %q has no plural forms", key)
+	}
+
+	index := c.GetPluralRule()(count)
+
+	if (index < 0) || (index >= len(forms)) {
+		index = len(forms) - 1
+	}
+
+	return forms[index], c.GetFormatter(), nil
+}
+
+// add adds entries to catalog. It must be called with lock held.
+func (c *Catalog) add(e *entries) *Catalog {
+	for key, message := range e.messages {
+		delete(c.plurals, key)
+		c.messages[key] = message
+	}
+
+	for key, forms := range e.plurals {
+		delete(c.messages, key)
+		c.plurals[key] = append([]string(nil), forms...)
+	}
+
+	if e.plural != nil {
+		c.plural = e.plural
+	}
+
+	return c
+}
+
+// keys returns sorted keys of messages and plural forms.
+func (e *entries) keys() []string {
+	keys := make([]string, 0, len(e.messages)+len(e.plurals))
+
+	for key := range e.messages {
+		keys = append(keys, key)
+	}
+
+	for key := range e.plurals {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func loadFile(fsys fs.FS, name string) (*entries, error) {
+	decode, ok := gDecoders[strings.ToLower(path.Ext(name))]
+
+	if !ok {
+		return nil, fmt.Errorf("%s: unsupported file format", name)
+	}
+
+	data, err := fs.ReadFile(fsys, name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := decode(data)
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return e, nil
+}
+
+// decodeTable returns decoder for YAML, JSON and TOML tables.
+func decodeTable(unmarshal func(data []byte, value interface{}) error) decoder {
+	return func(data []byte) (*entries, error) {
+		var table map[string]interface{}
+
+		if err := unmarshal(data, &table); err != nil {
+			return nil, err
+		}
+
+		e := &entries{messages: make(map[string]string), plurals: make(map[string][]string)}
+
+		if err := e.flatten("", table); err != nil {
+			return nil, err
+		}
+
+		return e, nil
+	}
+}
+
+// flatten adds values from nested tables with keys joined by KeySeparator.
+func (e *entries) flatten(prefix string, table map[string]interface{}) error {
+	for key, value := range table {
+		if prefix != "" {
+			key = prefix + KeySeparator + key
+		}
+
+		switch v := value.(type) {
+		case string:
+			e.messages[key] = v
+		case []interface{}:
+			forms := make([]string, 0, len(v))
+
+			for _, form := range v {
+				text, ok := form.(string)
+
+				if !ok {
+					return fmt.Errorf("plural form of message %q must be a string, got %T", key, form)
+				}
+
+				forms = append(forms, text)
+			}
+
+			e.plurals[key] = forms
+		case map[string]interface{}:
+			if err := e.flatten(key, v); err != nil {
+				return err
+			}
+		case map[interface{}]interface{}:
+			nested := make(map[string]interface{}, len(v))
+
+			for nestedKey, nestedValue := range v {
+				nested[fmt.Sprint(nestedKey)] = nestedValue
+			}
+
+			if err := e.flatten(key, nested); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("message %q must be a string, got %T", key, value)
+		}
+	}
+
+	return nil
+}
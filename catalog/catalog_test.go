@@ -0,0 +1,178 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalog_test
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/tymonx/go-formatter/catalog"
+	"gitlab.com/tymonx/go-formatter/formatter"
+)
+
+func ExampleLoad() {
+	files := fstest.MapFS{
+		"messages.yaml": {Data: []byte("greeting: \"Hello {name}!\"\nerrors:\n  notFound: \"File {p} not found\"\n")},
+	}
+
+	c, err := catalog.Load(files, "*.yaml")
+
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(c.MustFormat("greeting", formatter.Named{"name": "Bob"}))
+	fmt.Println(c.MustFormat("errors.notFound", "config.json"))
+	// Output:
+	// Hello Bob!
+	// File config.json not found
+}
+
+func TestCatalogLoad(test *testing.T) {
+	files := fstest.MapFS{
+		"locales/a.json": {Data: []byte(`{"json": "JSON {p}", "nested": {"key": "Nested {p}"}}`)},
+		"locales/b.yml":  {Data: []byte("yaml: YAML {p}\n")},
+		"locales/c.toml": {Data: []byte("toml = \"TOML {p}\"\n[table]\nkey = \"Table {p}\"\n")},
+	}
+
+	c, err := catalog.Load(files, "locales/*")
+
+	assert.NoError(test, err)
+	assert.Equal(test, []string{"json", "nested.key", "table.key", "toml", "yaml"}, c.Keys())
+
+	for key, expected := range map[string]string{
+		"json":       "JSON 3",
+		"nested.key": "Nested 3",
+		"yaml":       "YAML 3",
+		"toml":       "TOML 3",
+		"table.key":  "Table 3",
+	} {
+		formatted, err := c.Format(key, 3)
+
+		assert.NoError(test, err)
+		assert.Equal(test, expected, formatted)
+	}
+}
+
+func TestCatalogLoadFiles(test *testing.T) {
+	files := fstest.MapFS{
+		"msgs[en].yaml": {Data: []byte("greeting: Hello {p}!\n")},
+		"msgs*.json":    {Data: []byte(`{"farewell": "Bye {p}!"}`)},
+		"other.json":    {Data: []byte(`{"greeting": "Hi {p}!"}`)},
+	}
+
+	c, err := catalog.LoadFiles(files, "msgs[en].yaml", "msgs*.json")
+
+	assert.NoError(test, err)
+	assert.Equal(test, []string{"farewell", "greeting"}, c.Keys())
+
+	c, err = catalog.LoadFiles(files, "msgs[en].yaml", "other.json")
+
+	assert.Error(test, err)
+	assert.Contains(test, err.Error(), `other.json: message "greeting" is already defined`)
+	assert.Nil(test, c)
+
+	_, err = catalog.LoadFiles(files, "missing.yaml")
+
+	assert.Error(test, err)
+}
+
+func TestCatalogLoadError(test *testing.T) {
+	for pattern, files := range map[string]fstest.MapFS{
+		"*.yaml": {},
+		"[":      {},
+		"*.txt":  {"a.txt": {Data: []byte("text")}},
+		"*.json": {"a.json": {Data: []byte("{")}},
+		"*.toml": {"a.toml": {Data: []byte("key = 3")}},
+		"*.yml":  {"a.yml": {Data: []byte("key: a")}, "b.yml": {Data: []byte("key: b")}},
+	} {
+		c, err := catalog.Load(files, pattern)
+
+		assert.Error(test, err, pattern)
+		assert.Nil(test, c)
+	}
+}
+
+func TestCatalogFormat(test *testing.T) {
+	c := catalog.New().Add("key", "<p>").SetFormatter(formatter.New(formatter.WithDelimiters("<", ">")))
+
+	message, ok := c.Get("key")
+
+	assert.True(test, ok)
+	assert.Equal(test, "<p>", message)
+	assert.True(test, c.Has("key"))
+	assert.False(test, c.Has("missing"))
+	assert.Equal(test, "<", c.GetFormatter().GetLeftDelimiter())
+
+	formatted, err := c.Format("key", 3)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "3", formatted)
+
+	_, err = c.Format("missing")
+
+	assert.EqualError(test, err, `message "missing" not found`)
+	assert.Panics(test, func() { c.MustFormat("missing") })
+}
+
+func TestCatalogFormatWithLayout(test *testing.T) {
+	c := catalog.New().
+		Add("email.layout", "Hi {name},\n{template \"body\"}\n{block \"signature\" .}Team{end}").
+		Add("email.reset", "Reset your password.{define \"signature\"}Security team{end}")
+
+	formatted, err := c.FormatWithLayout("email.layout", "email.reset", formatter.Named{"name": "Bob"})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Hi Bob,\nReset your password.\nSecurity team", formatted)
+	assert.Empty(test, c.GetFormatter().GetTemplates())
+
+	_, err = c.FormatWithLayout("missing", "email.reset")
+
+	assert.Error(test, err)
+
+	_, err = c.FormatWithLayout("email.layout", "missing")
+
+	assert.Error(test, err)
+}
+
+func TestCatalogMergeConcurrent(test *testing.T) {
+	c := catalog.New()
+	other := catalog.New().Add("greeting", "Hello {p}!")
+
+	var group sync.WaitGroup
+
+	group.Add(1)
+
+	go func() {
+		defer group.Done()
+
+		for index := 0; index < 100; index++ {
+			other.Add("key"+strconv.Itoa(index), "value").AddPlural("files", "{p} file", "{p} files")
+		}
+	}()
+
+	for index := 0; index < 100; index++ {
+		c.Merge(other)
+	}
+
+	group.Wait()
+	c.Merge(other).Merge(c)
+
+	assert.Equal(test, other.Keys(), c.Keys())
+}
@@ -0,0 +1,67 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package catalog implements message catalogs that map message keys to format
+strings rendered by the formatter package.
+
+Messages are loaded from YAML, JSON or TOML files. Nested tables are
+flattened to keys joined with a dot. Example:
+
+	# messages.yaml
+	greeting: "Hello {name}!"
+	errors:
+	  notFound: "File {p} not found"
+
+	c, err := catalog.Load(os.DirFS("locales"), "*.yaml")
+
+	formatted, err := c.Format("errors.notFound", "config.json")
+
+# Plural forms
+
+List of strings defines message plural forms. Plural form is selected by
+plural rule of formatter locale:
+
+	files: ["{p} file", "{p} files"]
+
+	formatted, err := c.FormatPlural("files", 3, 3)
+
+# Gettext
+
+Translations can be loaded from gettext PO and MO files. Message identifier
+is used as message key. Message context is joined with message identifier
+using ContextSeparator. Plural rule is taken from the Plural-Forms header.
+Fuzzy, obsolete and untranslated messages are skipped.
+
+# Lint
+
+Lint compares translations against source catalog and reports missing and
+extra keys and messages whose placeholders differ from source message:
+
+	for _, issue := range catalog.Lint(english, map[string]*catalog.Catalog{"de": german}) {
+		fmt.Println(issue)
+	}
+
+# Hot reload
+
+Watch polls catalog files and replaces catalog messages atomically when they
+change. Failed reload keeps previous messages:
+
+	w := c.Watch(os.DirFS("locales"), "*.yaml", time.Second, func(err error) {
+		log.Println("cannot reload messages:", err)
+	})
+
+	defer w.Stop()
+*/
+package catalog
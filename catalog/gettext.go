@@ -0,0 +1,305 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ContextSeparator joins gettext message context and message identifier in
+// message key like in gettext MO files.
+const ContextSeparator = "\x04"
+
+// These constants define magic numbers of gettext MO files.
+const (
+	moMagicLittleEndian = 0x950412de
+	moMagicBigEndian    = 0xde120495
+	moHeaderSize        = 28
+)
+
+// poEntry defines a single entry from gettext PO file.
+type poEntry struct {
+	context   string
+	id        string
+	idPlural  string
+	strings   map[int]*string
+	fuzzy     bool
+	obsolete  bool
+	hasFields bool
+}
+
+// decodePO decodes gettext PO file. Fuzzy, obsolete and untranslated entries
+// are skipped.
+func decodePO(data []byte) (*entries, error) {
+	e := &entries{messages: make(map[string]string), plurals: make(map[string][]string)}
+
+	entry := &poEntry{strings: make(map[int]*string)}
+
+	var field *string
+
+	flush := func() error {
+		if entry.hasFields {
+			if err := e.addGettext(entry.context, entry.id, entry.idPlural, entry.forms(), entry.fuzzy || entry.obsolete); err != nil {
+				return err
+			}
+		}
+
+		entry, field = &poEntry{strings: make(map[int]*string)}, nil
+
+		return nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "":
+			if err := flush(); err != nil {
+				return nil, err
+			}
+
+			continue
+		case strings.HasPrefix(line, "#~"):
+			entry.obsolete = true
+			line = strings.TrimSpace(line[2:])
+		case strings.HasPrefix(line, "#,"):
+			entry.fuzzy = entry.fuzzy || strings.Contains(line, "fuzzy")
+			continue
+		case strings.HasPrefix(line, "#"):
+			continue
+		}
+
+		if strings.HasPrefix(line, `"`) {
+			if field == nil {
+				return nil, fmt.Errorf("line %d: unexpected string", number)
+			}
+
+			text, err := strconv.Unquote(line)
+
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", number, err)
+			}
+
+			*field += text
+
+			continue
+		}
+
+		keyword, value, _ := cut(line, " ")
+
+		if ((keyword == "msgctxt") || (keyword == "msgid")) && (len(entry.strings) > 0) {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+
+		text, err := strconv.Unquote(strings.TrimSpace(value))
+
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", number, err)
+		}
+
+		if field, err = entry.field(keyword); err != nil {
+			return nil, fmt.Errorf("line %d: %w", number, err)
+		}
+
+		*field = text
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// field returns pointer to entry field for PO keyword.
+func (p *poEntry) field(keyword string) (*string, error) {
+	p.hasFields = true
+
+	switch keyword {
+	case "msgctxt":
+		return &p.context, nil
+	case "msgid":
+		return &p.id, nil
+	case "msgid_plural":
+		return &p.idPlural, nil
+	case "msgstr":
+		return p.form(0), nil
+	}
+
+	if strings.HasPrefix(keyword, "msgstr[") && strings.HasSuffix(keyword, "]") {
+		if index, err := strconv.Atoi(keyword[len("msgstr[") : len(keyword)-1]); (err == nil) && (index >= 0) {
+			return p.form(index), nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown keyword %q", keyword)
+}
+
+func (p *poEntry) form(index int) *string {
+	if p.strings[index] == nil {
+		p.strings[index] = new(string)
+	}
+
+	return p.strings[index]
+}
+
+// forms returns translations ordered by plural form index. Missing plural
+// forms are empty.
+func (p *poEntry) forms() []string {
+	length := 0
+
+	for index := range p.strings {
+		if index >= length {
+			length = index + 1
+		}
+	}
+
+	forms := make([]string, length)
+
+	for index, text := range p.strings {
+		forms[index] = *text
+	}
+
+	return forms
+}
+
+// decodeMO decodes gettext MO file.
+func decodeMO(data []byte) (*entries, error) {
+	if len(data) < moHeaderSize {
+		return nil, fmt.Errorf("invalid MO file size %d", len(data))
+	}
+
+	var order binary.ByteOrder
+
+	switch binary.LittleEndian.Uint32(data) {
+	case moMagicLittleEndian:
+		order = binary.LittleEndian
+	case moMagicBigEndian:
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("invalid MO file magic number %#x", binary.LittleEndian.Uint32(data))
+	}
+
+	count := int(order.Uint32(data[8:]))
+	originals := int(order.Uint32(data[12:]))
+	translations := int(order.Uint32(data[16:]))
+
+	text := func(table, index int) (string, error) {
+		offset := table + 8*index
+
+		if (offset < 0) || (offset+8 > len(data)) {
+			return "", fmt.Errorf("invalid MO file string table offset %d", offset)
+		}
+
+		length, start := int(order.Uint32(data[offset:])), int(order.Uint32(data[offset+4:]))
+
+		if (start < 0) || (length < 0) || (start+length > len(data)) {
+			return "", fmt.Errorf("invalid MO file string at offset %d", start)
+		}
+
+		return string(data[start : start+length]), nil
+	}
+
+	e := &entries{messages: make(map[string]string), plurals: make(map[string][]string)}
+
+	for index := 0; index < count; index++ {
+		original, err := text(originals, index)
+
+		if err != nil {
+			return nil, err
+		}
+
+		translation, err := text(translations, index)
+
+		if err != nil {
+			return nil, err
+		}
+
+		context := ""
+
+		if before, after, ok := cut(original, ContextSeparator); ok {
+			context, original = before, after
+		}
+
+		id, idPlural, _ := cut(original, "\x00")
+
+		if err := e.addGettext(context, id, idPlural, strings.Split(translation, "\x00"), false); err != nil {
+			return nil, err
+		}
+	}
+
+	return e, nil
+}
+
+// addGettext adds gettext message. Header entry with empty identifier
+// defines plural rule. Untranslated and skipped messages are ignored.
+func (e *entries) addGettext(context, id, idPlural string, forms []string, skip bool) error {
+	if len(forms) == 0 {
+		return nil
+	}
+
+	if (id == "") && (context == "") {
+		for _, line := range strings.Split(forms[0], "\n") {
+			if name, value, ok := cut(line, ":"); ok && strings.EqualFold(strings.TrimSpace(name), "Plural-Forms") {
+				plural, err := parsePluralForms(value)
+
+				if err != nil {
+					return err
+				}
+
+				e.plural = plural
+			}
+		}
+
+		return nil
+	}
+
+	translated := false
+
+	for _, form := range forms {
+		translated = translated || (form != "")
+	}
+
+	if skip || !translated {
+		return nil
+	}
+
+	key := id
+
+	if context != "" {
+		key = context + ContextSeparator + id
+	}
+
+	if idPlural != "" {
+		e.plurals[key] = forms
+	} else {
+		e.messages[key] = forms[0]
+	}
+
+	return nil
+}
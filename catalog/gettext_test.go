@@ -0,0 +1,225 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalog_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/tymonx/go-formatter/catalog"
+	"gitlab.com/tymonx/go-formatter/formatter"
+)
+
+const polishPO = `# Polish translation.
+msgid ""
+msgstr ""
+"Language: pl\n"
+"Plural-Forms: nplurals=3; plural=(n==1 ? 0 : n%10>=2 && n%10<=4 && "
+"(n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+#: main.go:10
+msgid "Hello {name}!"
+msgstr "Cześć {name}!"
+
+msgctxt "menu"
+msgid "Open"
+msgstr "Otwórz"
+
+msgid "{p} file"
+msgid_plural "{p} files"
+msgstr[0] "{p} plik"
+msgstr[1] "{p} pliki"
+msgstr[2] "{p} plików"
+
+#, fuzzy
+msgid "Fuzzy"
+msgstr "Niepewne"
+
+msgid "Untranslated"
+msgstr ""
+
+#~ msgid "Obsolete"
+#~ msgstr "Przestarzałe"
+`
+
+// encodeMO encodes messages to gettext MO file.
+func encodeMO(messages map[string]string) []byte {
+	keys := make([]string, 0, len(messages))
+
+	for key := range messages {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	var strings bytes.Buffer
+
+	count := uint32(len(keys))
+	offset := 28 + 16*count
+	originals := make([]uint32, 0, 2*count)
+	translations := make([]uint32, 0, 2*count)
+
+	for _, key := range keys {
+		originals = append(originals, uint32(len(key)), offset+uint32(strings.Len()))
+		strings.WriteString(key + "\x00")
+	}
+
+	for _, key := range keys {
+		translations = append(translations, uint32(len(messages[key])), offset+uint32(strings.Len()))
+		strings.WriteString(messages[key] + "\x00")
+	}
+
+	var buffer bytes.Buffer
+
+	for _, value := range append([]uint32{0x950412de, 0, count, 28, 28 + 8*count, 0, 0}, append(originals, translations...)...) {
+		_ = binary.Write(&buffer, binary.LittleEndian, value)
+	}
+
+	buffer.Write(strings.Bytes())
+
+	return buffer.Bytes()
+}
+
+func TestCatalogLoadPO(test *testing.T) {
+	c, err := catalog.Load(fstest.MapFS{"pl.po": {Data: []byte(polishPO)}}, "*.po")
+
+	assert.NoError(test, err)
+	assert.Equal(test, []string{"Hello {name}!", "menu\x04Open", "{p} file"}, c.Keys())
+	assert.True(test, c.IsPlural("{p} file"))
+	assert.False(test, c.Has("Fuzzy"))
+	assert.False(test, c.Has("Untranslated"))
+	assert.False(test, c.Has("Obsolete"))
+
+	formatted, err := c.Format("Hello {name}!", formatter.Named{"name": "Bob"})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Cześć Bob!", formatted)
+	assert.Equal(test, "Otwórz", c.MustFormat("menu"+catalog.ContextSeparator+"Open"))
+
+	for count, expected := range map[int64]string{
+		1:   "1 plik",
+		3:   "3 pliki",
+		5:   "5 plików",
+		12:  "12 plików",
+		22:  "22 pliki",
+		101: "101 plików",
+	} {
+		formatted, err := c.FormatPlural("{p} file", count, count)
+
+		assert.NoError(test, err)
+		assert.Equal(test, expected, formatted)
+	}
+}
+
+func TestCatalogLoadMO(test *testing.T) {
+	data := encodeMO(map[string]string{
+		"":                          "Plural-Forms: nplurals=2; plural=n != 1;\n",
+		"Hello":                     "Hallo",
+		"menu\x04Open":              "Öffnen",
+		"{p} file\x00{p} files":     "{p} Datei\x00{p} Dateien",
+		"Untranslated\x00Plural id": "\x00",
+	})
+
+	c, err := catalog.Load(fstest.MapFS{"de.mo": {Data: data}}, "*.mo")
+
+	assert.NoError(test, err)
+	assert.Equal(test, []string{"Hello", "menu\x04Open", "{p} file"}, c.Keys())
+	assert.Equal(test, "Hallo", c.MustFormat("Hello"))
+	assert.Equal(test, "Öffnen", c.MustFormat("menu\x04Open"))
+
+	forms, ok := c.GetPlural("{p} file")
+
+	assert.True(test, ok)
+	assert.Equal(test, []string{"{p} Datei", "{p} Dateien"}, forms)
+
+	formatted, err := c.FormatPlural("{p} file", 1, 1)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "1 Datei", formatted)
+
+	formatted, err = c.FormatPlural("{p} file", 0, 0)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "0 Dateien", formatted)
+
+	binary.LittleEndian.PutUint32(data[12:], 1<<20)
+
+	_, err = catalog.Load(fstest.MapFS{"de.mo": {Data: data}}, "*.mo")
+
+	assert.Error(test, err)
+}
+
+func TestCatalogLoadGettextError(test *testing.T) {
+	for name, data := range map[string]string{
+		"a.po": "msgid \"a\"\nmsgstr \"b\n",
+		"b.po": "\"continuation\"\n",
+		"c.po": "msgid \"a\"\nmsgkey \"b\"\n",
+		"d.po": "msgid \"\"\nmsgstr \"Plural-Forms: nplurals=2; plural=(n;\\n\"\n",
+		"e.po": "msgid \"\"\nmsgstr \"Plural-Forms: nplurals=2;\\n\"\n",
+		"f.po": "msgid \"\"\nmsgstr \"Plural-Forms: nplurals=2; plural=n ? 1;\\n\"\n",
+		"g.po": "msgid \"\"\nmsgstr \"Plural-Forms: nplurals=2; plural=n 1;\\n\"\n",
+		"h.mo": "short",
+		"i.mo": "invalid magic number of MO file",
+	} {
+		_, err := catalog.Load(fstest.MapFS{name: {Data: []byte(data)}}, name)
+
+		assert.Error(test, err, name)
+	}
+}
+
+func TestCatalogPlural(test *testing.T) {
+	files := fstest.MapFS{"en.yaml": {Data: []byte("files: [\"{p} file\", \"{p} files\"]\n")}}
+
+	c, err := catalog.Load(files, "*.yaml")
+
+	assert.NoError(test, err)
+	assert.True(test, c.IsPlural("files"))
+
+	formatted, err := c.FormatPlural("files", 1, 1)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "1 file", formatted)
+
+	formatted, err = c.FormatPlural("files", 2, 2)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "2 files", formatted)
+
+	formatted, err = c.SetPluralRule(func(int64) int { return 5 }).FormatPlural("files", 1, 1)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "1 files", formatted)
+
+	formatted, err = c.Add("single", "{p} single").FormatPlural("single", 2, 2)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "2 single", formatted)
+
+	_, err = c.AddPlural("empty").FormatPlural("empty", 1)
+
+	assert.Error(test, err)
+
+	_, err = c.FormatPlural("missing", 1)
+
+	assert.Error(test, err)
+
+	_, err = catalog.Load(fstest.MapFS{"en.yaml": {Data: []byte("files: [1, 2]\n")}}, "*.yaml")
+
+	assert.Error(test, err)
+}
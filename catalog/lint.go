@@ -0,0 +1,275 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gitlab.com/tymonx/go-formatter/formatter"
+)
+
+// These constants define kinds of lint issues.
+const (
+	MissingKey IssueKind = iota
+	ExtraKey
+	PlaceholderMismatch
+	InvalidMessage
+)
+
+// SourceLanguage is language of issues found by Lint in source catalog.
+// Catalogs don't know their languages.
+const SourceLanguage = "source"
+
+// IssueKind defines kind of lint issue.
+type IssueKind int
+
+// Issue defines a single problem found by Lint.
+type Issue struct {
+	Language string
+	Key      string
+	Kind     IssueKind
+	Message  string
+}
+
+// String returns issue description.
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %q: %s", i.Language, i.Key, i.Message)
+}
+
+// String returns name of issue kind.
+func (k IssueKind) String() string {
+	switch k {
+	case MissingKey:
+		return "missing key"
+	case ExtraKey:
+		return "extra key"
+	case PlaceholderMismatch:
+		return "placeholder mismatch"
+	case InvalidMessage:
+		return "invalid message"
+	default:
+		return fmt.Sprintf("IssueKind(%d)", int(k))
+	}
+}
+
+// Lint compares translations against source catalog. It reports keys missing
+// in translations, keys not defined in source catalog, messages that cannot
+// be parsed and messages whose placeholder sets differ from source message.
+// Issues are sorted by language and key. Issues found in source catalog are
+// reported with SourceLanguage.
+func Lint(source *Catalog, translations map[string]*Catalog) []Issue {
+	var issues []Issue
+
+	sourcePlaceholders := make(map[string][]string)
+
+	for _, key := range source.Keys() {
+		placeholders, err := source.Placeholders(key)
+
+		if err != nil {
+			issues = append(issues, Issue{Language: SourceLanguage, Key: key, Kind: InvalidMessage, Message: err.Error()})
+			continue
+		}
+
+		sourcePlaceholders[key] = placeholders
+	}
+
+	languages := make([]string, 0, len(translations))
+
+	for language := range translations {
+		languages = append(languages, language)
+	}
+
+	sort.Strings(languages)
+
+	for _, language := range languages {
+		issues = append(issues, lintTranslation(language, source, sourcePlaceholders, translations[language])...)
+	}
+
+	return issues
+}
+
+// Placeholders returns sorted names of placeholders used in message under
+// provided key. For message with plural forms placeholders from all forms are
+// returned.
+func (c *Catalog) Placeholders(key string) ([]string, error) {
+	forms, ok := c.GetPlural(key)
+
+	if !ok {
+		message, ok := c.Get(key)
+
+		if !ok {
+			return nil, fmt.Errorf("message %q not found", key)
+		}
+
+		forms = []string{message}
+	}
+
+	f := c.GetFormatter()
+	found := make(map[string]bool)
+
+	for _, form := range forms {
+		placeholders, err := f.Placeholders(form)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, placeholder := range placeholders {
+			found[placeholder] = true
+		}
+	}
+
+	placeholders := make([]string, 0, len(found))
+
+	for placeholder := range found {
+		placeholders = append(placeholders, placeholder)
+	}
+
+	sort.Strings(placeholders)
+
+	return placeholders, nil
+}
+
+// Comments returns comments like {# translator note #} used in message under
+// provided key. For message with plural forms comments from all forms are
+// returned in order of forms.
+func (c *Catalog) Comments(key string) ([]formatter.Comment, error) {
+	forms, ok := c.GetPlural(key)
+
+	if !ok {
+		message, ok := c.Get(key)
+
+		if !ok {
+			return nil, fmt.Errorf("message %q not found", key)
+		}
+
+		forms = []string{message}
+	}
+
+	f := c.GetFormatter()
+	comments := []formatter.Comment{}
+
+	for _, form := range forms {
+		comments = append(comments, f.Comments(form)...)
+	}
+
+	return comments, nil
+}
+
+// Diagnose returns suspicious delimiter sequences used in message under
+// provided key like unbalanced delimiters. For message with plural forms
+// diagnostics from all forms are returned in order of forms.
+func (c *Catalog) Diagnose(key string) ([]formatter.Diagnostic, error) {
+	forms, ok := c.GetPlural(key)
+
+	if !ok {
+		message, ok := c.Get(key)
+
+		if !ok {
+			return nil, fmt.Errorf("message %q not found", key)
+		}
+
+		forms = []string{message}
+	}
+
+	f := c.GetFormatter()
+	diagnostics := []formatter.Diagnostic{}
+
+	for _, form := range forms {
+		diagnostics = append(diagnostics, f.Diagnose(form)...)
+	}
+
+	return diagnostics, nil
+}
+
+func lintTranslation(language string, source *Catalog, sourcePlaceholders map[string][]string, translation *Catalog) []Issue {
+	var issues []Issue
+
+	for _, key := range source.Keys() {
+		if !translation.Has(key) {
+			issues = append(issues, Issue{Language: language, Key: key, Kind: MissingKey, Message: "message is missing"})
+		}
+	}
+
+	for _, key := range translation.Keys() {
+		if !source.Has(key) {
+			issues = append(issues, Issue{Language: language, Key: key, Kind: ExtraKey, Message: "message is not defined in source"})
+			continue
+		}
+
+		placeholders, err := translation.Placeholders(key)
+
+		if err != nil {
+			issues = append(issues, Issue{Language: language, Key: key, Kind: InvalidMessage, Message: err.Error()})
+			continue
+		}
+
+		expected, ok := sourcePlaceholders[key]
+
+		if !ok {
+			continue
+		}
+
+		if missing, extra := difference(expected, placeholders), difference(placeholders, expected); (len(missing) > 0) || (len(extra) > 0) {
+			issues = append(issues, Issue{
+				Language: language,
+				Key:      key,
+				Kind:     PlaceholderMismatch,
+				Message:  placeholderMismatch(missing, extra),
+			})
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Key < issues[j].Key
+	})
+
+	return issues
+}
+
+// difference returns names from a that are not in b.
+func difference(a, b []string) []string {
+	found := make(map[string]bool, len(b))
+
+	for _, name := range b {
+		found[name] = true
+	}
+
+	var result []string
+
+	for _, name := range a {
+		if !found[name] {
+			result = append(result, name)
+		}
+	}
+
+	return result
+}
+
+func placeholderMismatch(missing, extra []string) string {
+	var parts []string
+
+	if len(missing) > 0 {
+		parts = append(parts, "missing placeholders "+strings.Join(missing, ", "))
+	}
+
+	if len(extra) > 0 {
+		parts = append(parts, "extra placeholders "+strings.Join(extra, ", "))
+	}
+
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,119 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalog_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/tymonx/go-formatter/catalog"
+	"gitlab.com/tymonx/go-formatter/formatter"
+)
+
+func ExampleLint() {
+	source := catalog.New().
+		Add("greeting", "Hello {name}!").
+		Add("count", "{count} new messages").
+		Add("bye", "Bye")
+
+	german := catalog.New().
+		Add("greeting", "Hallo {name}!").
+		Add("count", "Neue Nachrichten").
+		Add("extra", "Extra")
+
+	for _, issue := range catalog.Lint(source, map[string]*catalog.Catalog{"de": german}) {
+		fmt.Println(issue)
+	}
+	// Output:
+	// de: "bye": message is missing
+	// de: "count": missing placeholders count
+	// de: "extra": message is not defined in source
+}
+
+func TestCatalogLint(test *testing.T) {
+	source := catalog.New().
+		Add("invalid", "{if}").
+		Add("object", "{.Name} {p}").
+		AddPlural("files", "{p} file", "{p} files")
+
+	polish := catalog.New().
+		Add("invalid", "{p}").
+		Add("object", "{.Name} {p} {extra}").
+		AddPlural("files", "{p} plik", "{p} pliki", "{p} plików")
+
+	broken := catalog.New().Add("object", "{end}")
+
+	issues := catalog.Lint(source, map[string]*catalog.Catalog{"pl": polish, "xx": broken})
+
+	assert.Len(test, issues, 5)
+	assert.Equal(test, catalog.Issue{Language: catalog.SourceLanguage, Key: "invalid", Kind: catalog.InvalidMessage, Message: issues[0].Message}, issues[0])
+	assert.Equal(test, catalog.PlaceholderMismatch, issues[1].Kind)
+	assert.Equal(test, "extra placeholders extra", issues[1].Message)
+	assert.Equal(test, []catalog.IssueKind{catalog.MissingKey, catalog.MissingKey, catalog.InvalidMessage},
+		[]catalog.IssueKind{issues[2].Kind, issues[3].Kind, issues[4].Kind})
+	assert.Equal(test, "xx", issues[4].Language)
+
+	assert.Equal(test, "missing key", catalog.MissingKey.String())
+	assert.Equal(test, "extra key", catalog.ExtraKey.String())
+	assert.Equal(test, "placeholder mismatch", catalog.PlaceholderMismatch.String())
+	assert.Equal(test, "invalid message", catalog.InvalidMessage.String())
+	assert.Equal(test, "IssueKind(9)", catalog.IssueKind(9).String())
+
+	_, err := source.Placeholders("missing")
+
+	assert.Error(test, err)
+}
+
+func TestCatalogComments(test *testing.T) {
+	c := catalog.New().
+		Add("greeting", "{# name is user first name #}Hello {name}!").
+		AddPlural("files", "{p} file{# singular #}", "{p} files")
+
+	comments, err := c.Comments("greeting")
+
+	assert.NoError(test, err)
+	assert.Equal(test, []formatter.Comment{{Text: "name is user first name", Location: ":1:0"}}, comments)
+
+	comments, err = c.Comments("files")
+
+	assert.NoError(test, err)
+	assert.Equal(test, []formatter.Comment{{Text: "singular", Location: ":1:8"}}, comments)
+
+	_, err = c.Comments("missing")
+
+	assert.Error(test, err)
+}
+
+func TestCatalogDiagnose(test *testing.T) {
+	c := catalog.New().
+		Add("greeting", "Hello {name}!").
+		AddPlural("files", "{p} file }", "{p} files")
+
+	diagnostics, err := c.Diagnose("greeting")
+
+	assert.NoError(test, err)
+	assert.Empty(test, diagnostics)
+
+	diagnostics, err = c.Diagnose("files")
+
+	assert.NoError(test, err)
+	assert.Len(test, diagnostics, 1)
+	assert.Equal(test, ":1:9", diagnostics[0].Location)
+
+	_, err = c.Diagnose("missing")
+
+	assert.Error(test, err)
+}
@@ -0,0 +1,257 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gitlab.com/tymonx/go-formatter/formatter"
+)
+
+type expression func(n int64) int64
+
+// pluralParser parses C-like plural expression used in gettext Plural-Forms
+// header like n==1 ? 0 : n%10>=2 && n%10<=4 ? 1 : 2.
+type pluralParser struct {
+	text string
+}
+
+// parsePluralForms returns plural rule defined by gettext Plural-Forms header
+// value like nplurals=2; plural=(n != 1);.
+func parsePluralForms(header string) (formatter.Plural, error) {
+	var rule string
+
+	for _, part := range strings.Split(header, ";") {
+		if name, value, ok := cut(strings.TrimSpace(part), "="); ok && (strings.TrimSpace(name) == "plural") {
+			rule = value
+		}
+	}
+
+	if strings.TrimSpace(rule) == "" {
+		return nil, fmt.Errorf("plural rule not found in %q", header)
+	}
+
+	p := &pluralParser{text: rule}
+	e, err := p.ternary()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if p.skip(); p.text != "" {
+		return nil, fmt.Errorf("unexpected %q in plural rule %q", p.text, rule)
+	}
+
+	return func(count int64) int {
+		return int(e(count))
+	}, nil
+}
+
+func (p *pluralParser) ternary() (expression, error) {
+	condition, err := p.binary(0)
+
+	if (err != nil) || !p.accept("?") {
+		return condition, err
+	}
+
+	then, err := p.ternary()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.accept(":") {
+		return nil, fmt.Errorf("expected : in plural rule at %q", p.text)
+	}
+
+	otherwise, err := p.ternary()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return func(n int64) int64 {
+		if condition(n) != 0 {
+			return then(n)
+		}
+
+		return otherwise(n)
+	}, nil
+}
+
+// gPluralOperators defines binary operators grouped by precedence from the
+// lowest one.
+var gPluralOperators = [][]string{ // nolint: gochecknoglobals
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<=", ">=", "<", ">"},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *pluralParser) binary(level int) (expression, error) {
+	if level == len(gPluralOperators) {
+		return p.unary()
+	}
+
+	left, err := p.binary(level + 1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		operator := ""
+
+		for _, candidate := range gPluralOperators[level] {
+			if p.accept(candidate) {
+				operator = candidate
+				break
+			}
+		}
+
+		if operator == "" {
+			return left, nil
+		}
+
+		right, err := p.binary(level + 1)
+
+		if err != nil {
+			return nil, err
+		}
+
+		left = binaryExpression(operator, left, right)
+	}
+}
+
+func (p *pluralParser) unary() (expression, error) {
+	switch {
+	case p.accept("!"):
+		e, err := p.unary()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return func(n int64) int64 { return boolean(e(n) == 0) }, nil
+	case p.accept("("):
+		e, err := p.ternary()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !p.accept(")") {
+			return nil, fmt.Errorf("expected ) in plural rule at %q", p.text)
+		}
+
+		return e, nil
+	case p.accept("n"):
+		return func(n int64) int64 { return n }, nil
+	}
+
+	length := 0
+
+	for (length < len(p.text)) && (p.text[length] >= '0') && (p.text[length] <= '9') {
+		length++
+	}
+
+	value, err := strconv.ParseInt(p.text[:length], 10, 64)
+
+	if err != nil {
+		return nil, fmt.Errorf("unexpected %q in plural rule", p.text)
+	}
+
+	p.text = p.text[length:]
+
+	return func(int64) int64 { return value }, nil
+}
+
+// accept consumes token if it is next in text.
+func (p *pluralParser) accept(token string) bool {
+	p.skip()
+
+	if !strings.HasPrefix(p.text, token) {
+		return false
+	}
+
+	// Operators like ! and < must not consume != and <=.
+	if (len(token) == 1) && (len(p.text) > 1) && (p.text[1] == '=') && strings.Contains("!<>=", token) {
+		return false
+	}
+
+	p.text = p.text[len(token):]
+
+	return true
+}
+
+func (p *pluralParser) skip() {
+	p.text = strings.TrimLeft(p.text, " \t\r\n")
+}
+
+func binaryExpression(operator string, left, right expression) expression {
+	operations := map[string]func(a, b int64) int64{
+		"||": func(a, b int64) int64 { return boolean((a != 0) || (b != 0)) },
+		"&&": func(a, b int64) int64 { return boolean((a != 0) && (b != 0)) },
+		"==": func(a, b int64) int64 { return boolean(a == b) },
+		"!=": func(a, b int64) int64 { return boolean(a != b) },
+		"<=": func(a, b int64) int64 { return boolean(a <= b) },
+		">=": func(a, b int64) int64 { return boolean(a >= b) },
+		"<":  func(a, b int64) int64 { return boolean(a < b) },
+		">":  func(a, b int64) int64 { return boolean(a > b) },
+		"+":  func(a, b int64) int64 { return a + b },
+		"-":  func(a, b int64) int64 { return a - b },
+		"*":  func(a, b int64) int64 { return a * b },
+		"/":  func(a, b int64) int64 { return divide(a, b, false) },
+		"%":  func(a, b int64) int64 { return divide(a, b, true) },
+	}
+
+	operation := operations[operator]
+
+	return func(n int64) int64 {
+		return operation(left(n), right(n))
+	}
+}
+
+func divide(a, b int64, remainder bool) int64 {
+	switch {
+	case b == 0:
+		return 0
+	case remainder:
+		return a % b
+	default:
+		return a / b
+	}
+}
+
+func boolean(value bool) int64 {
+	if value {
+		return 1
+	}
+
+	return 0
+}
+
+// cut is like strings.Cut.
+func cut(s, separator string) (before, after string, found bool) {
+	if index := strings.Index(s, separator); index >= 0 {
+		return s[:index], s[index+len(separator):], true
+	}
+
+	return s, "", false
+}
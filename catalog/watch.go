@@ -0,0 +1,172 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalog
+
+import (
+	"io/fs"
+	"sync"
+	"time"
+)
+
+// DefaultWatchInterval is used by Watch when provided interval is not
+// positive.
+const DefaultWatchInterval = time.Second
+
+// Watcher polls files of message catalog and reloads catalog when they
+// change.
+type Watcher struct {
+	catalog  *Catalog
+	fsys     fs.FS
+	pattern  string
+	onError  func(err error)
+	state    map[string]fileState
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// fileState defines file properties used to detect changes.
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// Watch starts polling all files matching pattern in file system with
+// provided interval. When matched files change, messages are loaded again
+// and catalog messages are replaced atomically. Callback is called with
+// error when reload fails, catalog keeps previous messages in that case.
+// Callback can be nil. DefaultWatchInterval is used if interval is not
+// positive.
+func (c *Catalog) Watch(fsys fs.FS, pattern string, interval time.Duration, onError func(err error)) *Watcher {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	w := &Watcher{
+		catalog: c,
+		fsys:    fsys,
+		pattern: pattern,
+		onError: onError,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	w.state, _ = w.scan()
+
+	go w.run(interval)
+
+	return w
+}
+
+// Stop stops polling files. It waits for reload in progress.
+func (w *Watcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+
+	<-w.done
+}
+
+// Reload loads messages again and replaces catalog messages atomically.
+// Plural rule is replaced by plural rule from loaded files, plural rule of
+// formatter locale is used if they don't define it.
+func (c *Catalog) Reload(fsys fs.FS, pattern string) error {
+	loaded, err := Load(fsys, pattern)
+
+	if err != nil {
+		return err
+	}
+
+	loaded.mutex.RLock()
+	defer loaded.mutex.RUnlock()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.messages, c.plurals, c.plural = loaded.messages, loaded.plurals, loaded.plural
+
+	return nil
+}
+
+func (w *Watcher) run(interval time.Duration) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.poll()
+		}
+	}
+}
+
+func (w *Watcher) poll() {
+	state, err := w.scan()
+
+	if err == nil {
+		if isSameState(w.state, state) {
+			return
+		}
+
+		err = w.catalog.Reload(w.fsys, w.pattern)
+	}
+
+	// Failed reload is not retried until files change again.
+	w.state = state
+
+	if (err != nil) && (w.onError != nil) {
+		w.onError(err)
+	}
+}
+
+func (w *Watcher) scan() (map[string]fileState, error) {
+	names, err := fs.Glob(w.fsys, w.pattern)
+
+	if err != nil {
+		return nil, err
+	}
+
+	state := make(map[string]fileState, len(names))
+
+	for _, name := range names {
+		info, err := fs.Stat(w.fsys, name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		state[name] = fileState{size: info.Size(), modTime: info.ModTime()}
+	}
+
+	return state, nil
+}
+
+func isSameState(a, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for name, state := range a {
+		if other, ok := b[name]; !ok || (other.size != state.size) || !other.modTime.Equal(state.modTime) {
+			return false
+		}
+	}
+
+	return true
+}
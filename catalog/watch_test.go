@@ -0,0 +1,113 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalog_test
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/tymonx/go-formatter/catalog"
+)
+
+func TestCatalogWatch(test *testing.T) {
+	directory := test.TempDir()
+	name := filepath.Join(directory, "en.yaml")
+
+	assert.NoError(test, os.WriteFile(name, []byte("key: first\n"), 0o600))
+
+	fsys := os.DirFS(directory)
+
+	c, err := catalog.Load(fsys, "*.yaml")
+
+	assert.NoError(test, err)
+
+	var mutex sync.Mutex
+
+	var errs []error
+
+	w := c.Watch(fsys, "*.yaml", time.Millisecond, func(err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		errs = append(errs, err)
+	})
+
+	defer w.Stop()
+
+	assert.NoError(test, os.WriteFile(name, []byte("key: second value\n"), 0o600))
+
+	assert.Eventually(test, func() bool {
+		message, _ := c.Get("key")
+		return message == "second value"
+	}, time.Second, time.Millisecond)
+
+	assert.NoError(test, os.WriteFile(name, []byte("key: [\n"), 0o600))
+
+	assert.Eventually(test, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		return len(errs) > 0
+	}, time.Second, time.Millisecond)
+
+	message, _ := c.Get("key")
+
+	assert.Equal(test, "second value", message)
+
+	w.Stop()
+	w.Stop()
+}
+
+func TestCatalogReload(test *testing.T) {
+	c := catalog.New().Add("old", "old")
+
+	assert.Error(test, c.Reload(os.DirFS(test.TempDir()), "*.yaml"))
+	assert.True(test, c.Has("old"))
+
+	files := fstest.MapFS{"en.po": {Data: []byte("msgid \"\"\nmsgstr \"Plural-Forms: nplurals=2; plural=0;\\n\"\n\n" +
+		"msgid \"{p} file\"\nmsgid_plural \"{p} files\"\nmsgstr[0] \"{p} file\"\nmsgstr[1] \"{p} files\"\n")}}
+
+	assert.NoError(test, c.Reload(files, "*.po"))
+	assert.False(test, c.Has("old"))
+
+	formatted, err := c.FormatPlural("{p} file", 2, 2)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "2 file", formatted)
+
+	files["en.po"] = &fstest.MapFile{Data: []byte("msgid \"{p} file\"\nmsgid_plural \"{p} files\"\n" +
+		"msgstr[0] \"{p} file\"\nmsgstr[1] \"{p} files\"\n")}
+
+	assert.NoError(test, c.Reload(files, "*.po"))
+
+	formatted, err = c.FormatPlural("{p} file", 2, 2)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "2 files", formatted)
+}
+
+func TestCatalogWatchInterval(test *testing.T) {
+	c := catalog.New()
+
+	assert.NotPanics(test, func() {
+		c.Watch(fstest.MapFS{}, "*.yaml", 0, nil).Stop()
+		c.Watch(fstest.MapFS{}, "*.yaml", -time.Second, nil).Stop()
+	})
+}
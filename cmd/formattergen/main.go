@@ -0,0 +1,138 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command formattergen generates typed Go functions for messages from message
+// catalog files. It is intended to be used with go generate:
+//
+//	//go:generate formattergen -package msg -o messages_gen.go messages.yaml
+//
+// Catalog files can be given with relative or absolute paths. Message key
+// defined in more than one file is reported as an error.
+//
+// Parameter types are set per placeholder name with repeated -type flag:
+//
+//	formattergen -type name=string -type count=int messages.yaml
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gitlab.com/tymonx/go-formatter/catalog"
+	"gitlab.com/tymonx/go-formatter/codegen"
+	"gitlab.com/tymonx/go-formatter/formatter"
+)
+
+// values implements flag.Value collecting repeated flag values.
+type values []string
+
+func (v *values) String() string {
+	return strings.Join(*v, ",")
+}
+
+func (v *values) Set(value string) error {
+	*v = append(*v, value)
+	return nil
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs command and returns exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	switch err := generate(args, stdout, stderr); {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	default:
+		fmt.Fprintln(stderr, "formattergen:", err)
+		return 1
+	}
+}
+
+func generate(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("formattergen", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	var types values
+
+	pkg := flags.String("package", os.Getenv("GOPACKAGE"), "name of generated package, default is $GOPACKAGE")
+	output := flags.String("o", "", "output file, standard output if not set")
+	locale := flags.String("locale", formatter.DefaultLocale, "locale used to render messages")
+
+	flags.Var(&types, "type", "parameter type name=type for placeholder, can be repeated")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		return errors.New("no catalog files")
+	}
+
+	options := codegen.Options{
+		Package: *pkg,
+		Locale:  *locale,
+		Types:   make(map[string]string),
+		Source:  strings.Join(flags.Args(), " "),
+	}
+
+	for _, t := range types {
+		name, value, ok := strings.Cut(t, "=")
+
+		if !ok {
+			return fmt.Errorf("invalid parameter type %q, expected name=type", t)
+		}
+
+		options.Types[name] = value
+	}
+
+	c := catalog.New()
+
+	for _, name := range flags.Args() {
+		loaded, err := catalog.LoadFiles(os.DirFS(filepath.Dir(name)), filepath.Base(name))
+
+		if err != nil {
+			return err
+		}
+
+		for _, key := range loaded.Keys() {
+			if c.Has(key) {
+				return fmt.Errorf("%s: message %q is already defined", name, key)
+			}
+		}
+
+		c.Merge(loaded)
+	}
+
+	source, err := codegen.Generate(c, options)
+
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		_, err = stdout.Write(source)
+		return err
+	}
+
+	return os.WriteFile(*output, source, 0o644) // nolint: gosec
+}
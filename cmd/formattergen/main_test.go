@@ -0,0 +1,76 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateCommand(test *testing.T) {
+	directory := test.TempDir()
+	output := filepath.Join(directory, "messages_gen.go")
+
+	working, err := os.Getwd()
+
+	assert.NoError(test, err)
+	assert.NoError(test, os.Chdir(directory))
+
+	defer func() { assert.NoError(test, os.Chdir(working)) }()
+
+	assert.NoError(test, os.WriteFile("messages.yaml", []byte("greeting: Hello {name}!\n"), 0o600))
+
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(test, 0, run([]string{"-package", "msg", "-type", "name=string", "-o", output, "messages.yaml"}, &stdout, &stderr))
+
+	source, err := os.ReadFile(output)
+
+	assert.NoError(test, err)
+	assert.Contains(test, string(source), "func Greeting(name string) string {")
+
+	assert.Equal(test, 0, run([]string{"-package", "msg", "messages.yaml"}, &stdout, &stderr))
+	assert.Contains(test, stdout.String(), "func Greeting(name interface{}) string {")
+
+	assert.Equal(test, 1, run([]string{"-package", "msg"}, &stdout, &stderr))
+	assert.Contains(test, stderr.String(), "no catalog files")
+
+	assert.Equal(test, 1, run([]string{"-package", "msg", "-type", "invalid", "messages.yaml"}, &stdout, &stderr))
+	assert.Contains(test, stderr.String(), "invalid parameter type")
+
+	absolute := filepath.Join(directory, "messages.yaml")
+
+	assert.NoError(test, os.Mkdir("sub", 0o700))
+	assert.NoError(test, os.Chdir("sub"))
+
+	stdout.Reset()
+
+	assert.Equal(test, 0, run([]string{"-package", "msg", absolute}, &stdout, &stderr))
+	assert.Contains(test, stdout.String(), "func Greeting(name interface{}) string {")
+
+	stdout.Reset()
+
+	assert.Equal(test, 0, run([]string{"-package", "msg", "../messages.yaml"}, &stdout, &stderr))
+	assert.Contains(test, stdout.String(), "func Greeting(name interface{}) string {")
+
+	assert.NoError(test, os.WriteFile("other.yaml", []byte("greeting: Hi {name}!\n"), 0o600))
+
+	assert.Equal(test, 1, run([]string{"-package", "msg", "../messages.yaml", "other.yaml"}, &stdout, &stderr))
+	assert.Contains(test, stderr.String(), `other.yaml: message "greeting" is already defined`)
+}
@@ -0,0 +1,336 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command go-formatter formats and validates format strings and message
+// catalogs from command line.
+//
+// Usage:
+//
+//	go-formatter render -m 'Hello {p0}' -a World
+//	go-formatter render -m 'Hello {name}' -n name=World
+//	go-formatter validate messages.yaml
+//	go-formatter validate -strict messages.yaml
+//	go-formatter placeholders file.tmpl
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gitlab.com/tymonx/go-formatter/catalog"
+	"gitlab.com/tymonx/go-formatter/formatter"
+)
+
+const usage = `Usage: go-formatter <command> [options] [arguments]
+
+Commands:
+  render        Format message with arguments
+  validate      Validate message catalog files and report suspicious delimiters
+  placeholders  Print placeholders used in message files
+`
+
+// errFailed is returned when command reported errors on its own.
+var errFailed = errors.New("failed") // nolint: gochecknoglobals
+
+// values implements flag.Value collecting repeated flag values.
+type values []string
+
+func (v *values) String() string {
+	return strings.Join(*v, ",")
+}
+
+func (v *values) Set(value string) error {
+	*v = append(*v, value)
+	return nil
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs command and returns exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	var err error
+
+	switch args[0] {
+	case "render":
+		err = render(args[1:], stdin, stdout, stderr)
+	case "validate":
+		err = validate(args[1:], stdout, stderr)
+	case "placeholders":
+		err = placeholders(args[1:], stdin, stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errFailed):
+		return 1
+	default:
+		fmt.Fprintln(stderr, "go-formatter:", err)
+		return 1
+	}
+}
+
+// newFlagSet returns flag set with options shared by commands.
+func newFlagSet(name string, stderr io.Writer) (*flag.FlagSet, *formatterFlags) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	options := &formatterFlags{}
+
+	flags.StringVar(&options.left, "left", formatter.DefaultLeftDelimiter, "left delimiter")
+	flags.StringVar(&options.right, "right", formatter.DefaultRightDelimiter, "right delimiter")
+	flags.StringVar(&options.placeholder, "placeholder", formatter.DefaultPlaceholder, "placeholder prefix")
+
+	return flags, options
+}
+
+type formatterFlags struct {
+	left        string
+	right       string
+	placeholder string
+}
+
+func (o *formatterFlags) formatter() *formatter.Formatter {
+	return formatter.New(formatter.WithDelimiters(o.left, o.right), formatter.WithPlaceholder(o.placeholder))
+}
+
+func render(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags, options := newFlagSet("render", stderr)
+
+	var arguments, named values
+
+	message := flags.String("m", "", "message to format, read from standard input if not set")
+	file := flags.String("f", "", "file with message to format")
+	strict := flags.Bool("strict", false, "report unused arguments as an error")
+	html := flags.Bool("html", false, "enable HTML-safe mode")
+	locale := flags.String("locale", formatter.DefaultLocale, "locale used by built-in functions")
+	color := flags.Bool("color", false, "enable colors")
+	explain := flags.Bool("explain", false, "print placeholder resolution after formatted message")
+
+	flags.Var(&arguments, "a", "positional argument, can be repeated")
+	flags.Var(&named, "n", "named argument name=value, can be repeated")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	text, err := readMessage(*message, *file, stdin)
+
+	if err != nil {
+		return err
+	}
+
+	colorMode := formatter.ColorNever
+
+	if *color {
+		colorMode = formatter.ColorAlways
+	}
+
+	f := options.formatter().SetStrict(*strict).SetSafeHTML(*html).SetLocale(*locale).SetColorMode(colorMode)
+
+	formatArguments := make([]interface{}, 0, len(arguments)+len(named))
+
+	for _, argument := range arguments {
+		formatArguments = append(formatArguments, argument)
+	}
+
+	for _, argument := range named {
+		name, value, ok := strings.Cut(argument, "=")
+
+		if !ok {
+			return fmt.Errorf("invalid named argument %q, expected name=value", argument)
+		}
+
+		formatArguments = append(formatArguments, formatter.Arg(name, value))
+	}
+
+	if *explain {
+		explanation, err := f.Explain(text, formatArguments...)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(stdout, "%s\n%s\n", explanation.Output, explanation)
+
+		return err
+	}
+
+	formatted, err := f.Format(text, formatArguments...)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(stdout, formatted)
+
+	return err
+}
+
+func validate(args []string, stdout, stderr io.Writer) error {
+	flags, options := newFlagSet("validate", stderr)
+
+	strict := flags.Bool("strict", false, "report suspicious delimiter sequences as errors")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		return errors.New("no catalog files to validate")
+	}
+
+	failed := false
+
+	for _, name := range flags.Args() {
+		c, err := catalog.LoadFiles(os.DirFS(filepath.Dir(name)), filepath.Base(name))
+
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			failed = true
+
+			continue
+		}
+
+		c.SetFormatter(options.formatter())
+
+		valid := true
+
+		for _, key := range c.Keys() {
+			if _, err := c.Placeholders(key); err != nil {
+				fmt.Fprintf(stderr, "%s: %q: %v\n", name, key, err)
+				valid = false
+			}
+
+			diagnostics, _ := c.Diagnose(key)
+
+			for _, diagnostic := range diagnostics {
+				fmt.Fprintf(stderr, "%s: %q%s: warning: %s, %s\n", name, key, diagnostic.Location, diagnostic.Message,
+					diagnostic.Suggestion)
+				valid = valid && !*strict
+			}
+		}
+
+		if valid {
+			fmt.Fprintf(stdout, "%s: %d messages ok\n", name, len(c.Keys()))
+		}
+
+		failed = failed || !valid
+	}
+
+	if failed {
+		return errFailed
+	}
+
+	return nil
+}
+
+func placeholders(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags, options := newFlagSet("placeholders", stderr)
+
+	message := flags.String("m", "", "message, files or standard input are used if not set")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var messages []string
+
+	switch {
+	case *message != "":
+		messages = append(messages, *message)
+	case flags.NArg() == 0:
+		text, err := readMessage("", "", stdin)
+
+		if err != nil {
+			return err
+		}
+
+		messages = append(messages, text)
+	}
+
+	for _, name := range flags.Args() {
+		text, err := readMessage("", name, stdin)
+
+		if err != nil {
+			return err
+		}
+
+		messages = append(messages, text)
+	}
+
+	f := options.formatter()
+	found := make(map[string]bool)
+
+	for _, text := range messages {
+		names, err := f.Placeholders(text)
+
+		if err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			found[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(found))
+
+	for name := range found {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintln(stdout, name)
+	}
+
+	return nil
+}
+
+// readMessage returns message, content of file or standard input.
+func readMessage(message, file string, stdin io.Reader) (string, error) {
+	switch {
+	case message != "":
+		return message, nil
+	case file != "":
+		data, err := os.ReadFile(file)
+		return strings.TrimSuffix(string(data), "\n"), err
+	default:
+		data, err := io.ReadAll(stdin)
+		return strings.TrimSuffix(string(data), "\n"), err
+	}
+}
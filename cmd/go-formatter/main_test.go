@@ -0,0 +1,177 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func execute(stdin string, args ...string) (code int, stdout, stderr string) {
+	var out, err bytes.Buffer
+
+	code = run(args, strings.NewReader(stdin), &out, &err)
+
+	return code, out.String(), err.String()
+}
+
+func TestRender(test *testing.T) {
+	code, stdout, _ := execute("", "render", "-m", "Hello {p0} {name}", "-a", "World", "-n", "name=Bob")
+
+	assert.Equal(test, 0, code)
+	assert.Equal(test, "Hello World Bob\n", stdout)
+
+	code, stdout, _ = execute("Hello <p>\n", "render", "-left", "<", "-right", ">", "-a", "stdin")
+
+	assert.Equal(test, 0, code)
+	assert.Equal(test, "Hello stdin\n", stdout)
+
+	code, stdout, _ = execute("", "render", "-m", "{p | upper}", "-a", "<b>", "-html")
+
+	assert.Equal(test, 0, code)
+	assert.Equal(test, "&lt;B&gt;\n", stdout)
+
+	code, _, stderr := execute("", "render", "-m", "Hello", "-a", "unused", "-strict")
+
+	assert.Equal(test, 1, code)
+	assert.Contains(test, stderr, "unused arguments")
+
+	code, _, stderr = execute("", "render", "-m", "Hello", "-n", "invalid")
+
+	assert.Equal(test, 1, code)
+	assert.Contains(test, stderr, "invalid named argument")
+
+	code, _, _ = execute("", "render", "-f", filepath.Join(test.TempDir(), "missing"))
+
+	assert.Equal(test, 1, code)
+
+	code, stdout, _ = execute("", "render", "-m", "Hello {p0} {name}", "-a", "World", "-a", "extra", "-n", "name=Bob", "-explain")
+
+	assert.Equal(test, 0, code)
+	assert.Equal(test, `Hello World Bob extra
+{p0} at :1:7 from argument 0: "World"
+{name} at :1:12 from argument 2 key "name": "Bob"
+unused arguments: 1
+`, stdout)
+
+	code, _, _ = execute("", "render", "-unknown")
+
+	assert.Equal(test, 1, code)
+}
+
+func TestValidate(test *testing.T) {
+	directory := test.TempDir()
+	valid := filepath.Join(directory, "en.yaml")
+	invalid := filepath.Join(directory, "pl.yaml")
+
+	assert.NoError(test, os.WriteFile(valid, []byte("a: Hello {name}\nb: Bye\n"), 0o600))
+	assert.NoError(test, os.WriteFile(invalid, []byte("a: Hello {if}\n"), 0o600))
+
+	code, stdout, _ := execute("", "validate", valid)
+
+	assert.Equal(test, 0, code)
+	assert.Equal(test, valid+": 2 messages ok\n", stdout)
+
+	code, stdout, stderr := execute("", "validate", invalid, valid, filepath.Join(directory, "missing.yaml"))
+
+	assert.Equal(test, 1, code)
+	assert.Equal(test, valid+": 2 messages ok\n", stdout)
+	assert.Contains(test, stderr, invalid+`: "a": `)
+	assert.Contains(test, stderr, "missing.yaml")
+
+	bracketed := filepath.Join(directory, "msgs[en].yaml")
+
+	assert.NoError(test, os.WriteFile(bracketed, []byte("a: Hello {name}\n"), 0o600))
+
+	code, stdout, _ = execute("", "validate", bracketed)
+
+	assert.Equal(test, 0, code)
+	assert.Equal(test, bracketed+": 1 messages ok\n", stdout)
+
+	code, _, stderr = execute("", "validate")
+
+	assert.Equal(test, 1, code)
+	assert.Contains(test, stderr, "no catalog files")
+
+	suspicious := filepath.Join(directory, "de.yaml")
+
+	assert.NoError(test, os.WriteFile(suspicious, []byte("a: Hallo {name} }\n"), 0o600))
+
+	code, stdout, stderr = execute("", "validate", suspicious)
+
+	assert.Equal(test, 0, code)
+	assert.Equal(test, suspicious+": 1 messages ok\n", stdout)
+	assert.Equal(test, suspicious+`: "a":1:13: warning: unbalanced right delimiter, escape it as "}}"`+"\n", stderr)
+
+	code, stdout, _ = execute("", "validate", "-strict", suspicious)
+
+	assert.Equal(test, 1, code)
+	assert.Empty(test, stdout)
+}
+
+func TestPlaceholders(test *testing.T) {
+	file := filepath.Join(test.TempDir(), "file.tmpl")
+
+	assert.NoError(test, os.WriteFile(file, []byte("{name} {p0} {.Email}\n"), 0o600))
+
+	code, stdout, _ := execute("", "placeholders", file)
+
+	assert.Equal(test, 0, code)
+	assert.Equal(test, ".Email\nname\np0\n", stdout)
+
+	code, stdout, _ = execute("{b} {a}", "placeholders")
+
+	assert.Equal(test, 0, code)
+	assert.Equal(test, "a\nb\n", stdout)
+
+	code, stdout, _ = execute("", "placeholders", "-m", "{count}")
+
+	assert.Equal(test, 0, code)
+	assert.Equal(test, "count\n", stdout)
+
+	code, _, _ = execute("", "placeholders", "-m", "{if}")
+
+	assert.Equal(test, 1, code)
+
+	code, _, _ = execute("", "placeholders", filepath.Join(test.TempDir(), "missing"))
+
+	assert.Equal(test, 1, code)
+}
+
+func TestUsage(test *testing.T) {
+	code, _, stderr := execute("")
+
+	assert.Equal(test, 2, code)
+	assert.Contains(test, stderr, "Usage:")
+
+	code, stdout, _ := execute("", "help")
+
+	assert.Equal(test, 0, code)
+	assert.Contains(test, stdout, "Commands:")
+
+	code, _, stderr = execute("", "unknown")
+
+	assert.Equal(test, 2, code)
+	assert.Contains(test, stderr, `unknown command "unknown"`)
+
+	code, _, _ = execute("", "render", "-h")
+
+	assert.Equal(test, 0, code)
+}
@@ -0,0 +1,362 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package codegen generates typed Go functions for messages from a message
+// catalog. Every message key becomes a function with one parameter per
+// placeholder, for example message user.greeting "Hello {name}!" becomes:
+//
+//	func UserGreeting(name interface{}) string
+//
+// Positional placeholders p0, p1, ... become parameters in order of
+// positions, named placeholders are passed as formatter.Named and object
+// fields like .Name require an object parameter. Message using automatic
+// placeholder p accepts variadic arguments, it cannot use named placeholders
+// or object fields. Message with plural forms takes
+// count as the first parameter, it is also bound to named placeholder count.
+// Parameter types default to interface{} and
+// can be set per placeholder name.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gitlab.com/tymonx/go-formatter/catalog"
+	"gitlab.com/tymonx/go-formatter/formatter"
+)
+
+// DefaultType is used for parameters without configured type.
+const DefaultType = "interface{}"
+
+// Options defines code generation options.
+type Options struct {
+	// Package is name of generated package.
+	Package string
+
+	// Locale is locale of formatter used to render messages. Default is en.
+	Locale string
+
+	// Types maps placeholder names to parameter types like int or string.
+	Types map[string]string
+
+	// Source describes source of messages in generated header comment.
+	Source string
+}
+
+// parameter defines a single parameter of generated function.
+type parameter struct {
+	name        string
+	placeholder string
+	kind        int
+}
+
+// These constants define kinds of parameters.
+const (
+	positionalParameter = iota
+	namedParameter
+	objectParameter
+	variadicParameter
+)
+
+// Generate returns formatted Go source code with functions for all messages
+// from catalog.
+func Generate(c *catalog.Catalog, options Options) ([]byte, error) {
+	if options.Package == "" {
+		return nil, fmt.Errorf("package name is not set")
+	}
+
+	if options.Locale == "" {
+		options.Locale = formatter.DefaultLocale
+	}
+
+	var body bytes.Buffer
+
+	names := make(map[string]string)
+
+	for _, key := range c.Keys() {
+		name := functionName(key)
+
+		if other, ok := names[name]; ok {
+			return nil, fmt.Errorf("messages %q and %q have the same function name %s", other, key, name)
+		}
+
+		names[name] = key
+
+		if err := generateFunction(&body, c, key, name, options); err != nil {
+			return nil, err
+		}
+	}
+
+	var source bytes.Buffer
+
+	source.WriteString("// Code generated by formattergen. DO NOT EDIT.\n")
+
+	if options.Source != "" {
+		source.WriteString("// Source: " + options.Source + "\n")
+	}
+
+	fmt.Fprintf(&source, "\npackage %s\n\n", options.Package)
+	source.WriteString("import (\n\t\"gitlab.com/tymonx/go-formatter/catalog\"\n\t\"gitlab.com/tymonx/go-formatter/formatter\"\n)\n\n")
+	source.WriteString("var gCatalog = newCatalog() // nolint: gochecknoglobals\n\n")
+
+	generateCatalog(&source, c, options)
+
+	source.WriteString(`func render(key string, arguments ...interface{}) string {
+	formatted, err := gCatalog.Format(key, arguments...)
+
+	if err != nil {
+		return "%!(ERROR=" + err.Error() + ")"
+	}
+
+	return formatted
+}
+
+func renderPlural(key string, count int64, arguments ...interface{}) string {
+	formatted, err := gCatalog.FormatPlural(key, count, arguments...)
+
+	if err != nil {
+		return "%!(ERROR=" + err.Error() + ")"
+	}
+
+	return formatted
+}
+`)
+
+	source.Write(body.Bytes())
+
+	formatted, err := format.Source(source.Bytes())
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot format generated code: %w", err)
+	}
+
+	return formatted, nil
+}
+
+func generateCatalog(source *bytes.Buffer, c *catalog.Catalog, options Options) {
+	source.WriteString("func newCatalog() *catalog.Catalog {\n")
+	fmt.Fprintf(source, "\tc := catalog.New().SetFormatter(formatter.New(formatter.WithLocale(%q)))\n\n", options.Locale)
+
+	for _, key := range c.Keys() {
+		if forms, ok := c.GetPlural(key); ok {
+			quoted := make([]string, 0, len(forms))
+
+			for _, form := range forms {
+				quoted = append(quoted, strconv.Quote(form))
+			}
+
+			fmt.Fprintf(source, "\tc.AddPlural(%q, %s)\n", key, strings.Join(quoted, ", "))
+		} else {
+			message, _ := c.Get(key)
+			fmt.Fprintf(source, "\tc.Add(%q, %q)\n", key, message)
+		}
+	}
+
+	source.WriteString("\n\treturn c\n}\n\n")
+}
+
+func generateFunction(body *bytes.Buffer, c *catalog.Catalog, key, name string, options Options) error {
+	parameters, err := functionParameters(c, key)
+
+	if err != nil {
+		return fmt.Errorf("message %q: %w", key, err)
+	}
+
+	plural := c.IsPlural(key)
+	message, _ := c.Get(key)
+
+	var declarations, arguments, named []string
+
+	if plural {
+		declarations = append(declarations, "count int64")
+	}
+
+	for _, p := range parameters {
+		switch p.kind {
+		case variadicParameter:
+			declarations = append(declarations, p.name+" ..."+DefaultType)
+			arguments = append(arguments, p.name+"...")
+		case namedParameter:
+			if plural && (p.placeholder == "count") {
+				named = append(named, fmt.Sprintf("%q: count", p.placeholder))
+				continue
+			}
+
+			declarations = append(declarations, p.name+" "+parameterType(p, options))
+			named = append(named, fmt.Sprintf("%q: %s", p.placeholder, p.name))
+		default:
+			declarations = append(declarations, p.name+" "+parameterType(p, options))
+			arguments = append(arguments, p.name)
+		}
+	}
+
+	if len(named) > 0 {
+		arguments = append(arguments, "formatter.Named{"+strings.Join(named, ", ")+"}")
+	}
+
+	call := "render(" + strconv.Quote(key)
+
+	if plural {
+		call = "renderPlural(" + strconv.Quote(key) + ", count"
+	}
+
+	if len(arguments) > 0 {
+		call += ", " + strings.Join(arguments, ", ")
+	}
+
+	fmt.Fprintf(body, "\n// %s renders message %q: %s\n", name, key, commentText(message))
+	fmt.Fprintf(body, "func %s(%s) string {\n\treturn %s)\n}\n", name, strings.Join(declarations, ", "), call)
+
+	return nil
+}
+
+// functionParameters returns parameters for message placeholders. Object
+// parameter is the first one, positional parameters are ordered by position
+// and named parameters by name.
+func functionParameters(c *catalog.Catalog, key string) ([]parameter, error) {
+	forms, ok := c.GetPlural(key)
+
+	if !ok {
+		message, _ := c.Get(key)
+		forms = []string{message}
+	}
+
+	f := c.GetFormatter()
+	placeholder := f.GetPlaceholder()
+
+	positions := make(map[int]bool)
+	named := make(map[string]bool)
+	object, automatic := false, false
+
+	for _, form := range forms {
+		references, err := f.References(form)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, reference := range references {
+			switch {
+			case reference.Automatic:
+				automatic = true
+			case reference.Position >= 0:
+				positions[reference.Position] = true
+			case strings.HasPrefix(reference.Name, "."):
+				object = true
+			default:
+				named[reference.Name] = true
+			}
+		}
+	}
+
+	var parameters []parameter
+
+	if object {
+		parameters = append(parameters, parameter{name: "object", kind: objectParameter})
+	}
+
+	if automatic && (len(named) > 0) {
+		return nil, fmt.Errorf("automatic placeholder cannot be used together with named placeholders")
+	}
+
+	if automatic && object {
+		return nil, fmt.Errorf("object fields cannot be used together with automatic placeholders")
+	}
+
+	if automatic {
+		return append(parameters, parameter{name: "arguments", kind: variadicParameter}), nil
+	}
+
+	count := 0
+
+	for position := range positions {
+		if position+1 > count {
+			count = position + 1
+		}
+	}
+
+	if object && (count > 0) {
+		return nil, fmt.Errorf("object fields cannot be used together with positional placeholders")
+	}
+
+	for position := 0; position < count; position++ {
+		name := placeholder + strconv.Itoa(position)
+		parameters = append(parameters, parameter{name: parameterName(name), placeholder: name, kind: positionalParameter})
+	}
+
+	names := make([]string, 0, len(named))
+
+	for name := range named {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		parameters = append(parameters, parameter{name: parameterName(name), placeholder: name, kind: namedParameter})
+	}
+
+	return parameters, nil
+}
+
+func parameterType(p parameter, options Options) string {
+	if t, ok := options.Types[p.placeholder]; ok && (p.placeholder != "") {
+		return t
+	}
+
+	return DefaultType
+}
+
+// functionName returns exported Go function name for message key, for
+// example user.greeting becomes UserGreeting.
+func functionName(key string) string {
+	var builder strings.Builder
+
+	for _, word := range strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		runes := []rune(word)
+		builder.WriteString(string(unicode.ToUpper(runes[0])) + string(runes[1:]))
+	}
+
+	name := builder.String()
+
+	if (name == "") || !unicode.IsLetter([]rune(name)[0]) {
+		name = "Message" + name
+	}
+
+	return name
+}
+
+// parameterName returns Go parameter name for placeholder name.
+func parameterName(name string) string {
+	runes := []rune(functionName(name))
+	name = string(unicode.ToLower(runes[0])) + string(runes[1:])
+
+	if token.IsKeyword(name) || (name == "count") || (name == "object") || (name == "arguments") {
+		name += "Value"
+	}
+
+	return name
+}
+
+func commentText(message string) string {
+	return strings.ReplaceAll(message, "\n", `\n`)
+}
@@ -0,0 +1,134 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/tymonx/go-formatter/catalog"
+	"gitlab.com/tymonx/go-formatter/codegen"
+	"gitlab.com/tymonx/go-formatter/examples/messages"
+)
+
+type Person struct {
+	Name  string
+	Email string
+}
+
+func TestGenerate(test *testing.T) {
+	c := catalog.New().
+		Add("user.greeting", "Hello {name}!").
+		Add("user.welcome", "Welcome {p1} and {p0}").
+		Add("type", "Type {type} {.Name}").
+		Add("log", "{p} {p}").
+		AddPlural("files", "{count} file of {p0}", "{count} files of {p0}")
+
+	source, err := codegen.Generate(c, codegen.Options{
+		Package: "msg",
+		Types:   map[string]string{"name": "string"},
+		Source:  "messages.yaml",
+	})
+
+	assert.NoError(test, err)
+
+	code := string(source)
+
+	assert.Contains(test, code, "// Code generated by formattergen. DO NOT EDIT.\n// Source: messages.yaml\n\npackage msg\n")
+	assert.Contains(test, code, `formatter.WithLocale("en")`)
+	assert.Contains(test, code, `c.AddPlural("files", "{count} file of {p0}", "{count} files of {p0}")`)
+	assert.Contains(test, code, "func UserGreeting(name string) string {\n"+
+		"\treturn render(\"user.greeting\", formatter.Named{\"name\": name})\n}")
+	assert.Contains(test, code, "func UserWelcome(p0 interface{}, p1 interface{}) string {\n"+
+		"\treturn render(\"user.welcome\", p0, p1)\n}")
+	assert.Contains(test, code, "func Type(object interface{}, typeValue interface{}) string {\n"+
+		"\treturn render(\"type\", object, formatter.Named{\"type\": typeValue})\n}")
+	assert.Contains(test, code, "func Log(arguments ...interface{}) string {\n"+
+		"\treturn render(\"log\", arguments...)\n}")
+	assert.Contains(test, code, "func Files(count int64, p0 interface{}) string {\n"+
+		"\treturn renderPlural(\"files\", count, p0, formatter.Named{\"count\": count})\n}")
+}
+
+func TestGenerateError(test *testing.T) {
+	_, err := codegen.Generate(catalog.New(), codegen.Options{})
+
+	assert.Error(test, err)
+
+	_, err = codegen.Generate(catalog.New().Add("user.name", "A").Add("user-name", "B"), codegen.Options{Package: "msg"})
+
+	assert.Error(test, err)
+	assert.Contains(test, err.Error(), "the same function name UserName")
+
+	_, err = codegen.Generate(catalog.New().Add("invalid", "{if}"), codegen.Options{Package: "msg"})
+
+	assert.Error(test, err)
+	assert.Contains(test, err.Error(), `message "invalid"`)
+
+	_, err = codegen.Generate(catalog.New().Add("mixed", "{p} {name}"), codegen.Options{Package: "msg"})
+
+	assert.Error(test, err)
+
+	_, err = codegen.Generate(catalog.New().Add("object", "{.Name} {p}"), codegen.Options{Package: "msg"})
+
+	assert.Error(test, err)
+	assert.Contains(test, err.Error(), "object fields cannot be used together with automatic placeholders")
+}
+
+func TestGenerateCompile(test *testing.T) {
+	if testing.Short() {
+		test.Skip("compiling generated code is skipped in short mode")
+	}
+
+	compiler, err := exec.LookPath("go")
+
+	if err != nil {
+		test.Skip("go command is not available")
+	}
+
+	c := catalog.New().
+		Add("user.greeting", "Hello {name}!").
+		Add("user.welcome", "Welcome {p1} and {p0}").
+		Add("type", "Type {type} {.Name}").
+		Add("log", "{p} {p}").
+		Add("plain", "Plain").
+		AddPlural("files", "{count} file of {p0}", "{count} files of {p0}")
+
+	source, err := codegen.Generate(c, codegen.Options{Package: "msg", Types: map[string]string{"name": "string"}})
+
+	assert.NoError(test, err)
+
+	directory, err := os.MkdirTemp(".", "generated")
+
+	assert.NoError(test, err)
+
+	defer os.RemoveAll(directory)
+
+	assert.NoError(test, os.WriteFile(filepath.Join(directory, "messages_gen.go"), source, 0o600))
+
+	output, err := exec.Command(compiler, "build", "./"+filepath.Base(directory)).CombinedOutput() // nolint: gosec
+
+	assert.NoError(test, err, string(output))
+}
+
+func TestGenerated(test *testing.T) {
+	assert.Equal(test, "Hello Bob!", messages.UserGreeting("Bob"))
+	assert.Equal(test, "Welcome Alice, you have 3 new messages", messages.UserWelcome("Alice", 3))
+	assert.Equal(test, "1 file", messages.Files(1))
+	assert.Equal(test, "2 files", messages.Files(2))
+	assert.Equal(test, "Bob <bob@example.com>", messages.Profile(Person{Name: "Bob", Email: "bob@example.com"}))
+}
@@ -86,4 +86,6 @@ func main() {
 	fmt.Println()
 	fmt.Println(formatter.MustFormat("Now: {now}"))
 	fmt.Println(formatter.MustFormat("ISO 8601: {now | iso8601}"))
+	fmt.Println(formatter.MustFormat(`Date: {now | date "Monday, 2 January 2006"}`))
+	fmt.Println(formatter.MustFormat(`Time in Warsaw: {now | tz "Europe/Warsaw" | date "Kitchen"}`))
 }
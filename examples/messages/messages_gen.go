@@ -0,0 +1,62 @@
+// Code generated by formattergen. DO NOT EDIT.
+// Source: messages.yaml
+
+package messages
+
+import (
+	"gitlab.com/tymonx/go-formatter/catalog"
+	"gitlab.com/tymonx/go-formatter/formatter"
+)
+
+var gCatalog = newCatalog() // nolint: gochecknoglobals
+
+func newCatalog() *catalog.Catalog {
+	c := catalog.New().SetFormatter(formatter.New(formatter.WithLocale("en")))
+
+	c.AddPlural("files", "{count} file", "{count} files")
+	c.Add("profile", "{.Name} <{.Email}>")
+	c.Add("user.greeting", "Hello {name}!")
+	c.Add("user.welcome", "Welcome {p0}, you have {p1} new messages")
+
+	return c
+}
+
+func render(key string, arguments ...interface{}) string {
+	formatted, err := gCatalog.Format(key, arguments...)
+
+	if err != nil {
+		return "%!(ERROR=" + err.Error() + ")"
+	}
+
+	return formatted
+}
+
+func renderPlural(key string, count int64, arguments ...interface{}) string {
+	formatted, err := gCatalog.FormatPlural(key, count, arguments...)
+
+	if err != nil {
+		return "%!(ERROR=" + err.Error() + ")"
+	}
+
+	return formatted
+}
+
+// Files renders message "files": {count} file
+func Files(count int64) string {
+	return renderPlural("files", count, formatter.Named{"count": count})
+}
+
+// Profile renders message "profile": {.Name} <{.Email}>
+func Profile(object interface{}) string {
+	return render("profile", object)
+}
+
+// UserGreeting renders message "user.greeting": Hello {name}!
+func UserGreeting(name string) string {
+	return render("user.greeting", formatter.Named{"name": name})
+}
+
+// UserWelcome renders message "user.welcome": Welcome {p0}, you have {p1} new messages
+func UserWelcome(p0 interface{}, p1 interface{}) string {
+	return render("user.welcome", p0, p1)
+}
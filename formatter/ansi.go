@@ -16,8 +16,11 @@ package formatter
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"strconv"
 	"strings"
+	"text/template"
 )
 
 const (
@@ -25,6 +28,26 @@ const (
 	greenOffset = 8
 )
 
+// ColorMode defines when ANSI escape codes are generated by built-in text and
+// color functions.
+type ColorMode int
+
+// These constants define supported color modes.
+const (
+	// ColorAuto generates ANSI escape codes only when formatter writes to
+	// terminal and the NO_COLOR environment variable is not set.
+	ColorAuto ColorMode = iota
+
+	// ColorAlways always generates ANSI escape codes.
+	ColorAlways
+
+	// ColorNever never generates ANSI escape codes.
+	ColorNever
+)
+
+// DefaultColorMode defines default color mode used by formatter.
+const DefaultColorMode = ColorAlways
+
 var gBrightMap = map[string]string{ // nolint: gochecknoglobals
 	"\033[30m":  "\033[90m",
 	"\033[31m":  "\033[91m",
@@ -153,6 +176,45 @@ var gForegroundMap = map[string]string{ // nolint: gochecknoglobals,dupl
 	"\033[107m": "\033[97m",
 }
 
+var gNoColorFunctions = template.FuncMap{ // nolint: gochecknoglobals
+	"reset":      noColor,
+	"normal":     noColor,
+	"default":    noColor,
+	"bold":       noColor,
+	"faint":      noColor,
+	"italic":     noColor,
+	"underline":  noColor,
+	"overline":   noColor,
+	"blink":      noColor,
+	"invert":     noColor,
+	"hide":       noColor,
+	"strike":     noColor,
+	"off":        noColorPipe,
+	"black":      noColor,
+	"red":        noColor,
+	"green":      noColor,
+	"yellow":     noColor,
+	"blue":       noColor,
+	"magenta":    noColor,
+	"cyan":       noColor,
+	"white":      noColor,
+	"gray":       noColor,
+	"bgBlack":    noColor,
+	"bgRed":      noColor,
+	"bgGreen":    noColor,
+	"bgYellow":   noColor,
+	"bgBlue":     noColor,
+	"bgMagenta":  noColor,
+	"bgCyan":     noColor,
+	"bgWhite":    noColor,
+	"bgGray":     noColor,
+	"rgb":        noColorRGB,
+	"bright":     noColorPipe,
+	"background": noColorPipe,
+	"foreground": noColorPipe,
+	"color":      noColorPipe,
+}
+
 var gColorMap = map[string]string{ // nolint: gochecknoglobals
 	"default": "\033[0m",
 	"normal":  "\033[0m",
@@ -244,6 +306,42 @@ func setGray() string {
 	return "\033[90m"
 }
 
+func setBackgroundBlack() string {
+	return "\033[40m"
+}
+
+func setBackgroundRed() string {
+	return "\033[41m"
+}
+
+func setBackgroundGreen() string {
+	return "\033[42m"
+}
+
+func setBackgroundYellow() string {
+	return "\033[43m"
+}
+
+func setBackgroundBlue() string {
+	return "\033[44m"
+}
+
+func setBackgroundMagenta() string {
+	return "\033[45m"
+}
+
+func setBackgroundCyan() string {
+	return "\033[46m"
+}
+
+func setBackgroundWhite() string {
+	return "\033[47m"
+}
+
+func setBackgroundGray() string {
+	return "\033[100m"
+}
+
 func setBell() string {
 	return "\a"
 }
@@ -319,3 +417,64 @@ func setForeground(in string) (out string, err error) {
 		return "", fError("foreground can be used only with colors")
 	}
 }
+
+func noColor() string {
+	return ""
+}
+
+func noColorPipe(string) string {
+	return ""
+}
+
+func noColorRGB(_, _, _ uint8) string {
+	return ""
+}
+
+// withColorWriter returns config with automatic color mode resolved for
+// writer. It must be called before writer is wrapped by write buffer, counter
+// or output limit that hide the terminal.
+func (f *config) withColorWriter(writer io.Writer) *config {
+	if f.colorMode != ColorAuto {
+		return f
+	}
+
+	c := *f
+	c.colorMode = ColorNever
+
+	if isColorEnabled(ColorAuto, writer) {
+		c.colorMode = ColorAlways
+	}
+
+	return &c
+}
+
+func isColorEnabled(mode ColorMode, writer io.Writer) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+
+	return isTerminal(writer)
+}
+
+func isTerminal(writer io.Writer) bool {
+	file, ok := writer.(*os.File)
+
+	if !ok {
+		return false
+	}
+
+	info, err := file.Stat()
+
+	if err != nil {
+		return false
+	}
+
+	return (info.Mode() & os.ModeCharDevice) != 0
+}
@@ -0,0 +1,48 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+// AppendFormat formats string and appends it to provided byte slice like the
+// strconv.Append functions. It returns the extended byte slice.
+func AppendFormat(dst []byte, message string, arguments ...interface{}) ([]byte, error) {
+	return New().AppendFormat(dst, message, arguments...)
+}
+
+// AppendFormat formats string and appends it to provided byte slice like the
+// strconv.Append functions. It returns the extended byte slice. On error
+// provided byte slice is returned with unchanged length.
+func (f *Formatter) AppendFormat(dst []byte, message string, arguments ...interface{}) ([]byte, error) {
+	if (len(arguments) == 0) && f.isPlain(message) {
+		return append(dst, message...), nil
+	}
+
+	writer := appendWriter{buffer: dst}
+
+	if err := f.FormatWriter(&writer, message, arguments...); err != nil {
+		return dst, err
+	}
+
+	return writer.buffer, nil
+}
+
+// appendWriter implements io.Writer that appends written bytes to a slice.
+type appendWriter struct {
+	buffer []byte
+}
+
+func (w *appendWriter) Write(data []byte) (int, error) {
+	w.buffer = append(w.buffer, data...)
+	return len(data), nil
+}
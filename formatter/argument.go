@@ -0,0 +1,115 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+// Argument defines argument value available under explicit name.
+type Argument struct {
+	Name  string
+	Value interface{}
+}
+
+// Arg creates argument value available under explicit name like {name} or
+// {name.Field}. Unlike struct arguments it never becomes the data object.
+func Arg(name string, value interface{}) Argument {
+	return Argument{
+		Name:  name,
+		Value: value,
+	}
+}
+
+// Args defines multiple argument values available under explicit names like
+// {user} or {order.ID}. It is a shorthand for many Arg arguments. Unlike
+// struct arguments values never become the data object.
+type Args map[string]interface{}
+
+// unwrapArgument returns value of named argument or argument itself.
+func unwrapArgument(argument interface{}) interface{} {
+	if named, ok := argument.(Argument); ok {
+		return named.Value
+	}
+
+	return argument
+}
+
+// Lazy defines argument value that is evaluated only if message uses it.
+// Arguments of type func() interface{} are also evaluated lazily. Function is
+// called at most once per formatting call.
+type Lazy func() interface{}
+
+// lazyValue holds lazy argument evaluated at most once.
+type lazyValue struct {
+	function  func() interface{}
+	evaluated bool
+	value     interface{}
+}
+
+func (l *lazyValue) get() interface{} {
+	if !l.evaluated {
+		l.value, l.evaluated = l.function(), true
+	}
+
+	return l.value
+}
+
+// lazy returns lazyValue for lazy argument or argument itself.
+func lazy(argument interface{}) interface{} {
+	switch function := argument.(type) {
+	case Lazy:
+		return &lazyValue{function: function}
+	case func() interface{}:
+		return &lazyValue{function: function}
+	default:
+		return argument
+	}
+}
+
+// lazyArguments returns arguments with lazy values, also provided with Arg,
+// replaced by lazyValue, so they are evaluated at most once.
+func lazyArguments(arguments []interface{}) []interface{} {
+	var replaced []interface{}
+
+	for position, argument := range arguments {
+		value := lazy(unwrapArgument(argument))
+
+		if _, ok := value.(*lazyValue); !ok {
+			continue
+		}
+
+		if replaced == nil {
+			replaced = append([]interface{}(nil), arguments...)
+		}
+
+		if named, ok := argument.(Argument); ok {
+			replaced[position] = Argument{Name: named.Name, Value: value}
+		} else {
+			replaced[position] = value
+		}
+	}
+
+	if replaced == nil {
+		return arguments
+	}
+
+	return replaced
+}
+
+// evaluate returns value of lazy argument or argument itself.
+func evaluate(argument interface{}) interface{} {
+	if value, ok := argument.(*lazyValue); ok {
+		return value.get()
+	}
+
+	return argument
+}
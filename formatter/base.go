@@ -0,0 +1,149 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const nibbleSize = 4
+
+// formatHex renders integer in base 16. Optional first argument is minimal number
+// of digits, value is padded with zeros.
+func formatHex(arguments ...interface{}) (string, error) {
+	return formatBase(16, "", arguments)
+}
+
+// formatHexPrefix renders integer in base 16 with 0x prefix. Optional first
+// argument is minimal number of digits, value is padded with zeros.
+func formatHexPrefix(arguments ...interface{}) (string, error) {
+	return formatBase(16, "0x", arguments)
+}
+
+// formatOct renders integer in base 8. Optional first argument is minimal number of
+// digits, value is padded with zeros.
+func formatOct(arguments ...interface{}) (string, error) {
+	return formatBase(8, "", arguments)
+}
+
+// formatBin renders integer in base 2. Optional first argument is minimal number of
+// digits, value is padded with zeros.
+func formatBin(arguments ...interface{}) (string, error) {
+	return formatBase(2, "", arguments)
+}
+
+// formatBits renders integer as bits grouped in nibbles like 0000 1010. Optional
+// first argument is number of bits, default is size of integer type. Negative
+// values are rendered in two's complement.
+func formatBits(arguments ...interface{}) (string, error) {
+	value, width, err := baseArguments(arguments)
+
+	if err != nil {
+		return "", err
+	}
+
+	magnitude, negative, size, err := integerBits(value)
+
+	if err != nil {
+		return "", err
+	}
+
+	if width <= 0 {
+		width = size
+	}
+
+	if negative {
+		magnitude = -magnitude
+	}
+
+	if width < 64 {
+		magnitude &= (1 << uint(width)) - 1
+	}
+
+	digits := strconv.FormatUint(magnitude, 2)
+	digits = strings.Repeat("0", maxInt(width-len(digits), 0)) + digits
+
+	var groups []string
+
+	for end := len(digits); end > 0; end -= nibbleSize {
+		groups = append([]string{digits[maxInt(end-nibbleSize, 0):end]}, groups...)
+	}
+
+	return strings.Join(groups, " "), nil
+}
+
+func formatBase(base int, prefix string, arguments []interface{}) (string, error) {
+	value, width, err := baseArguments(arguments)
+
+	if err != nil {
+		return "", err
+	}
+
+	magnitude, negative, _, err := integerBits(value)
+
+	if err != nil {
+		return "", err
+	}
+
+	digits := strconv.FormatUint(magnitude, base)
+	digits = strings.Repeat("0", maxInt(width-len(digits), 0)) + digits
+
+	if negative {
+		return "-" + prefix + digits, nil
+	}
+
+	return prefix + digits, nil
+}
+
+// baseArguments returns value and optional width from arguments.
+func baseArguments(arguments []interface{}) (value interface{}, width int, err error) {
+	switch len(arguments) {
+	case 1:
+		return arguments[0], 0, nil
+	case 2:
+		number, err := toInt(arguments[0])
+
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid width: %w", err)
+		}
+
+		return arguments[1], int(number), nil
+	default:
+		return nil, 0, fmt.Errorf("expected optional width and value, got %d arguments", len(arguments))
+	}
+}
+
+// integerBits returns magnitude, sign and size in bits of integer.
+func integerBits(value interface{}) (magnitude uint64, negative bool, size int, err error) {
+	valueOf := reflect.ValueOf(value)
+
+	switch valueOf.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number := valueOf.Int()
+
+		if number < 0 {
+			return uint64(-(number + 1)) + 1, true, valueOf.Type().Bits(), nil
+		}
+
+		return uint64(number), false, valueOf.Type().Bits(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return valueOf.Uint(), false, valueOf.Type().Bits(), nil
+	default:
+		return 0, false, 0, fmt.Errorf("expected integer, got %T", value)
+	}
+}
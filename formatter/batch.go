@@ -0,0 +1,150 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// DefaultBatchWorkers defines default number of workers used by FormatAll.
+const DefaultBatchWorkers = 1
+
+// Message pairs message with its arguments for FormatAll.
+type Message struct {
+	Text      string
+	Arguments []interface{}
+}
+
+// MessageError is returned by FormatAll and FormatEach for message or item
+// that cannot be formatted.
+type MessageError struct {
+	Index int
+	Err   error
+}
+
+// Error returns error message.
+func (e *MessageError) Error() string {
+	return fmt.Sprintf("message %d: %v", e.Index, e.Err)
+}
+
+// Unwrap returns formatting error.
+func (e *MessageError) Unwrap() error {
+	return e.Err
+}
+
+// FormatAll formats all messages with their arguments.
+func FormatAll(messages []Message) ([]string, error) {
+	return New().FormatAll(messages)
+}
+
+// FormatAll formats all messages with their arguments. Configuration is taken
+// once for all messages. Messages are formatted by batch workers in parallel
+// if there are more of them. Formatted strings are returned in order of
+// messages, also when some messages cannot be formatted. Errors are returned
+// joined as MessageError for every failed message.
+func (f *Formatter) FormatAll(messages []Message) ([]string, error) {
+	c := f.snapshot()
+
+	results := make([]string, len(messages))
+	errs := make([]error, len(messages))
+
+	format := func(index int) {
+		buffer := getBuffer()
+		defer putBuffer(buffer)
+
+		message := messages[index]
+		config, arguments := c.withOptions(message.Arguments)
+
+		if err := config.formatWriter(context.Background(), buffer, message.Text, arguments...); err != nil {
+			errs[index] = &MessageError{Index: index, Err: err}
+			return
+		}
+
+		results[index] = buffer.String()
+	}
+
+	if workers := c.workers(len(messages)); workers > 1 {
+		parallel(len(messages), workers, format)
+	} else {
+		for index := range messages {
+			format(index)
+		}
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// SetBatchWorkers sets number of workers used by FormatAll to format messages
+// in parallel. Zero or negative number uses as many workers as there are
+// logical CPUs usable by the program.
+func (f *Formatter) SetBatchWorkers(workers int) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.batchWorkers = workers
+
+	return f
+}
+
+// GetBatchWorkers returns number of workers used by FormatAll.
+func (f *Formatter) GetBatchWorkers() int {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.batchWorkers
+}
+
+// workers returns number of workers used to format provided number of
+// messages.
+func (f *config) workers(count int) int {
+	workers := f.batchWorkers
+
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	return minInt(workers, count)
+}
+
+// parallel calls run for every index from zero to count in provided number of
+// goroutines.
+func parallel(count, workers int, run func(index int)) {
+	indexes := make(chan int)
+
+	var group sync.WaitGroup
+
+	for worker := 0; worker < workers; worker++ {
+		group.Add(1)
+
+		go func() {
+			defer group.Done()
+
+			for index := range indexes {
+				run(index)
+			}
+		}()
+	}
+
+	for index := 0; index < count; index++ {
+		indexes <- index
+	}
+
+	close(indexes)
+	group.Wait()
+}
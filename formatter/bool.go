@@ -0,0 +1,88 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"text/template"
+)
+
+// SetBoolStrings sets texts rendered instead of true and false for boolean
+// values. Empty texts restore default rendering.
+func (f *Formatter) SetBoolStrings(trueText, falseText string) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.trueText, f.falseText = trueText, falseText
+
+	return f
+}
+
+// GetBoolStrings returns texts rendered for true and false boolean values.
+func (f *Formatter) GetBoolStrings() (trueText, falseText string) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.trueText, f.falseText
+}
+
+// renderBool returns rendered boolean value. It returns false if value is not
+// a boolean or boolean strings are not set.
+func (f *config) renderBool(value interface{}) (string, bool) {
+	truth, ok := value.(bool)
+
+	if !ok || ((f.trueText == "") && (f.falseText == "")) {
+		return "", false
+	}
+
+	if truth {
+		return f.trueText, true
+	}
+
+	return f.falseText, true
+}
+
+// yesNo renders value truth as localized yes or no. Non-boolean values are
+// evaluated like in the if action.
+func (l *Locale) yesNo(value interface{}) string {
+	if isTrue(value) {
+		return localeText(l.Yes, "yes")
+	}
+
+	return localeText(l.No, "no")
+}
+
+// onOff renders value truth as localized on or off. Non-boolean values are
+// evaluated like in the if action.
+func (l *Locale) onOff(value interface{}) string {
+	if isTrue(value) {
+		return localeText(l.On, "on")
+	}
+
+	return localeText(l.Off, "off")
+}
+
+func isTrue(value interface{}) bool {
+	truth, _ := template.IsTrue(evaluate(value))
+
+	return truth
+}
+
+func localeText(text, fallback string) string {
+	if text == "" {
+		return fallback
+	}
+
+	return text
+}
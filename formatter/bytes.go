@@ -0,0 +1,205 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultBytesPrecision defines default number of decimal places used by the
+// bytesIEC and bytesSI functions.
+const DefaultBytesPrecision = 1
+
+var gIECUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"} // nolint: gochecknoglobals
+
+var gSIUnits = []string{"B", "kB", "MB", "GB", "TB", "PB", "EB"} // nolint: gochecknoglobals
+
+var gByteMultipliers = map[string]float64{ // nolint: gochecknoglobals
+	"":    1,
+	"b":   1,
+	"k":   1e3,
+	"kb":  1e3,
+	"m":   1e6,
+	"mb":  1e6,
+	"g":   1e9,
+	"gb":  1e9,
+	"t":   1e12,
+	"tb":  1e12,
+	"p":   1e15,
+	"pb":  1e15,
+	"e":   1e18,
+	"eb":  1e18,
+	"ki":  1 << 10,
+	"kib": 1 << 10,
+	"mi":  1 << 20,
+	"mib": 1 << 20,
+	"gi":  1 << 30,
+	"gib": 1 << 30,
+	"ti":  1 << 40,
+	"tib": 1 << 40,
+	"pi":  1 << 50,
+	"pib": 1 << 50,
+	"ei":  1 << 60,
+	"eib": 1 << 60,
+}
+
+// ByteSize defines number of bytes. It is rendered with IEC units like
+// 1.4 GiB and it can be used as a Scan target parsing sizes like 1.5 GB,
+// 1.4 GiB or 512 B.
+type ByteSize uint64
+
+// String returns byte size rendered with IEC units like 1.4 GiB.
+func (s ByteSize) String() string {
+	return formatBytes(float64(s), 1024, gIECUnits, DefaultBytesPrecision)
+}
+
+// UnmarshalText parses byte size like 1.5 GB, 1.4 GiB or 512 B.
+func (s *ByteSize) UnmarshalText(text []byte) error {
+	size, err := ParseBytes(string(text))
+
+	if err != nil {
+		return err
+	}
+
+	*s = ByteSize(size)
+
+	return nil
+}
+
+// ParseBytes parses byte size with SI units like 1.5 GB or IEC units like
+// 1.4 GiB. Units are case-insensitive, unit without i like K or kB is a power
+// of 1000.
+func ParseBytes(text string) (uint64, error) {
+	text = strings.TrimSpace(text)
+	index := strings.IndexFunc(text, func(r rune) bool {
+		return !unicode.IsDigit(r) && (r != '.')
+	})
+
+	if index < 0 {
+		index = len(text)
+	}
+
+	number, err := strconv.ParseFloat(text[:index], 64)
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q", text)
+	}
+
+	multiplier, ok := gByteMultipliers[strings.ToLower(strings.TrimSpace(text[index:]))]
+
+	if !ok {
+		return 0, fmt.Errorf("invalid byte size unit in %q", text)
+	}
+
+	size := number * multiplier
+
+	if size >= math.MaxUint64 {
+		return 0, fmt.Errorf("byte size %q is too large", text)
+	}
+
+	return uint64(size), nil
+}
+
+// bytesIEC renders number of bytes with IEC units like 1.4 GiB. Optional
+// first argument is number of decimal places.
+func bytesIEC(arguments ...interface{}) (string, error) {
+	return bytesUnits(1024, gIECUnits, arguments)
+}
+
+// bytesSI renders number of bytes with SI units like 1.5 GB. Optional first
+// argument is number of decimal places.
+func bytesSI(arguments ...interface{}) (string, error) {
+	return bytesUnits(1000, gSIUnits, arguments)
+}
+
+func bytesUnits(base float64, units []string, arguments []interface{}) (string, error) {
+	precision := DefaultBytesPrecision
+
+	switch len(arguments) {
+	case 1:
+	case 2:
+		value, err := toFloat(arguments[0])
+
+		if err != nil {
+			return "", fmt.Errorf("invalid precision: %w", err)
+		}
+
+		precision = int(value)
+	default:
+		return "", fmt.Errorf("expected optional precision and value, got %d arguments", len(arguments))
+	}
+
+	value, err := toFloat(arguments[len(arguments)-1])
+
+	if err != nil {
+		return "", err
+	}
+
+	return formatBytes(value, base, units, precision), nil
+}
+
+// formatBytes renders value with largest unit that keeps it below base.
+// Values in bytes are rendered without decimal places.
+func formatBytes(value, base float64, units []string, precision int) string {
+	if value < 0 {
+		return "-" + formatBytes(-value, base, units, precision)
+	}
+
+	precision = maxInt(precision, 0)
+	scale := math.Pow(10, float64(precision))
+	index := 0
+
+	for index < len(units)-1 {
+		rounded := math.Round(value)
+
+		if index > 0 {
+			rounded = math.Round(value*scale) / scale
+		}
+
+		if rounded < base {
+			break
+		}
+
+		value /= base
+		index++
+	}
+
+	if index == 0 {
+		return strconv.FormatFloat(math.Round(value), 'f', 0, 64) + " " + units[index]
+	}
+
+	return strconv.FormatFloat(value, 'f', precision, 64) + " " + units[index]
+}
+
+// toFloat converts integer and floating point numbers to float64.
+func toFloat(value interface{}) (float64, error) {
+	valueOf := reflect.ValueOf(value)
+
+	switch valueOf.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(valueOf.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(valueOf.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return valueOf.Float(), nil
+	default:
+		return 0, fmt.Errorf("expected number, got %T", value)
+	}
+}
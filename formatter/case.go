@@ -0,0 +1,119 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// camelCase converts text to camelCase like userName.
+func camelCase(text string) string {
+	words := splitWords(text)
+
+	for index, word := range words {
+		if index == 0 {
+			words[index] = strings.ToLower(word)
+		} else {
+			words[index] = titleWord(word)
+		}
+	}
+
+	return strings.Join(words, "")
+}
+
+// pascalCase converts text to PascalCase like UserName.
+func pascalCase(text string) string {
+	words := splitWords(text)
+
+	for index, word := range words {
+		words[index] = titleWord(word)
+	}
+
+	return strings.Join(words, "")
+}
+
+// snakeCase converts text to snake_case like user_name.
+func snakeCase(text string) string {
+	return strings.ToLower(strings.Join(splitWords(text), "_"))
+}
+
+// kebabCase converts text to kebab-case like user-name.
+func kebabCase(text string) string {
+	return strings.ToLower(strings.Join(splitWords(text), "-"))
+}
+
+// screamingSnakeCase converts text to SCREAMING_SNAKE_CASE like USER_NAME.
+func screamingSnakeCase(text string) string {
+	return strings.ToUpper(strings.Join(splitWords(text), "_"))
+}
+
+// sentenceCase converts text to Sentence case like User name.
+func sentenceCase(text string) string {
+	return titleWord(strings.Join(splitWords(text), " "))
+}
+
+// titleWord converts the first letter of word to upper case and the rest to
+// lower case.
+func titleWord(word string) string {
+	first, size := utf8.DecodeRuneInString(word)
+
+	if size == 0 {
+		return word
+	}
+
+	return string(unicode.ToUpper(first)) + strings.ToLower(word[size:])
+}
+
+// splitWords splits text to words on non-alphanumeric characters, on lower
+// case to upper case changes like userName and at the end of acronyms like
+// HTTPServer. Digits belong to preceding word.
+func splitWords(text string) []string {
+	var words []string
+
+	runes := []rune(text)
+	start := -1
+
+	for index, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:index]))
+				start = -1
+			}
+
+			continue
+		}
+
+		if start < 0 {
+			start = index
+			continue
+		}
+
+		previous := runes[index-1]
+		acronymEnd := unicode.IsUpper(previous) && (index+1 < len(runes)) && unicode.IsLower(runes[index+1])
+
+		if unicode.IsUpper(r) && (unicode.IsLower(previous) || unicode.IsDigit(previous) || acronymEnd) {
+			words = append(words, string(runes[start:index]))
+			start = index
+		}
+	}
+
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+
+	return words
+}
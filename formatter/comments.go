@@ -0,0 +1,69 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Comment defines a single comment like {# translator note #} in message.
+// Comments are removed from formatted output.
+type Comment struct {
+	// Text is comment text without delimiters, markers and surrounding
+	// whitespace.
+	Text string
+
+	// Location is comment location in message like :1:5.
+	Location string
+}
+
+// Comments returns all comments used in message in order of appearance.
+func Comments(message string) []Comment {
+	return New().Comments(message)
+}
+
+// Comments returns all comments used in message in order of appearance.
+func (f *Formatter) Comments(message string) []Comment {
+	c := f.snapshot()
+
+	comments := []Comment{}
+	offset := 0
+
+	for _, s := range scan(message, c.leftDelimiter, c.rightDelimiter) {
+		if s.kind == commentSegment {
+			text := strings.TrimPrefix(s.text, c.leftDelimiter+commentStart)
+			text = strings.TrimSuffix(text, commentEnd+c.rightDelimiter)
+
+			comments = append(comments, Comment{
+				Text:     strings.TrimSpace(text),
+				Location: location(message, offset),
+			})
+		}
+
+		offset += len(s.text)
+	}
+
+	return comments
+}
+
+// location returns location of byte offset in message like :1:5 in the same
+// format as it is used by the text/template package.
+func location(message string, offset int) string {
+	line := 1 + strings.Count(message[:offset], "\n")
+	column := offset - strings.LastIndex(message[:offset], "\n") - 1
+
+	return ":" + strconv.Itoa(line) + ":" + strconv.Itoa(column)
+}
@@ -0,0 +1,148 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"context"
+	"io"
+	"text/template/parse"
+)
+
+// Compiled defines message parsed once and formatted with formatter
+// configuration taken at compile time. Later changes of formatter do not
+// affect it.
+type Compiled struct {
+	config  *config
+	message string
+}
+
+// compiledMessage holds message translated and parsed at compile time.
+// Trees are never modified, they are copied for every formatting.
+type compiledMessage struct {
+	source  string
+	message string
+	trees   map[string]*parse.Tree
+}
+
+// Compile checks message syntax and returns compiled message.
+func Compile(message string) (*Compiled, error) {
+	return New().Compile(message)
+}
+
+// Compile parses message and returns compiled message that uses current
+// formatter configuration. Formatting of compiled message reuses parsed
+// message, only steps depending on arguments are done again. Options passed
+// among arguments disable it, because they can change message syntax.
+func (f *Formatter) Compile(message string) (*Compiled, error) {
+	c := f.snapshot()
+
+	if _, err := c.references(message, nil); err != nil {
+		return nil, err
+	}
+
+	compiled := &compiledMessage{source: message, message: c.translate(message)}
+
+	if !c.isPlain(compiled.message) {
+		trees, err := c.parseSource(compiled.message)
+
+		if err != nil {
+			return nil, err
+		}
+
+		compiled.trees = trees
+	}
+
+	c.compiled = compiled
+
+	return &Compiled{config: c, message: message}, nil
+}
+
+// withOptions returns configuration with applied options found in arguments
+// and arguments without options. Compiled message is not used with options.
+func (c *Compiled) withOptions(arguments []interface{}) (*config, []interface{}) {
+	config, arguments := c.config.withOptions(arguments)
+
+	if config != c.config {
+		config.compiled = nil
+	}
+
+	return config, arguments
+}
+
+// translateMessage returns translated message. Compiled message is not
+// translated again.
+func (f *config) translateMessage(message string) string {
+	if (f.compiled != nil) && (f.compiled.source == message) {
+		return f.compiled.message
+	}
+
+	return f.translate(message)
+}
+
+// Message returns compiled message.
+func (c *Compiled) Message() string {
+	return c.message
+}
+
+// Format formats compiled message with provided arguments.
+func (c *Compiled) Format(arguments ...interface{}) (string, error) {
+	buffer := getBuffer()
+	defer putBuffer(buffer)
+
+	config, arguments := c.withOptions(arguments)
+
+	if err := config.formatWriter(context.Background(), buffer, c.message, arguments...); err != nil {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
+
+// Bind returns object that writes compiled message formatted with provided
+// arguments every time it is asked to. It can be used to write response to
+// http.ResponseWriter. It implements also io.Reader, so it can be used with
+// io.Copy that calls WriteTo.
+func (c *Compiled) Bind(arguments ...interface{}) io.WriterTo {
+	return &bound{compiled: c, arguments: arguments}
+}
+
+type bound struct {
+	compiled  *Compiled
+	arguments []interface{}
+	reader    io.Reader
+}
+
+// WriteTo writes formatted message to writer. It returns number of written
+// bytes.
+func (b *bound) WriteTo(writer io.Writer) (int64, error) {
+	counter := &countingWriter{writer: writer}
+
+	config, arguments := b.compiled.withOptions(b.arguments)
+	config = config.withColorWriter(writer)
+
+	err := config.formatWriter(context.Background(), counter, b.compiled.message, arguments...)
+
+	return int64(counter.size), err
+}
+
+// Read reads formatted message like reader returned by FormatReader.
+func (b *bound) Read(data []byte) (int, error) {
+	if b.reader == nil {
+		config, arguments := b.compiled.withOptions(b.arguments)
+		b.reader = config.formatReader(b.compiled.message, arguments...)
+	}
+
+	return b.reader.Read(data)
+}
@@ -0,0 +1,116 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"text/template"
+	"text/template/parse"
+)
+
+const ternaryFunction = "ternary"
+
+// ternary returns first value if condition is true and second value
+// otherwise. Condition is true like in {if condition}. Condition is the last
+// argument like in sprig, so it can be piped like {premium | ternary "a" "b"}.
+func ternary(first, second, condition interface{}) interface{} {
+	if truth, _ := template.IsTrue(condition); truth {
+		return first
+	}
+
+	return second
+}
+
+// missingConditions returns placeholders returning nil for undefined
+// placeholders used as conditions like {if premium},
+// {if not (or trial premium)}, {ternary "yes" "no" premium} or
+// {premium | ternary "yes" "no"}, so missing value is false. Partial formatting
+// still reports them because such conditions cannot be preserved.
+func missingConditions(trees map[string]*parse.Tree, functions []template.FuncMap) template.FuncMap {
+	missing := make(template.FuncMap)
+
+	if isDefined(partialLiteralFunction, functions) {
+		return missing
+	}
+
+	walkTrees(trees, func(_ *parse.Tree, node parse.Node) {
+		switch n := node.(type) {
+		case *parse.IfNode:
+			addCondition(missing, n.Pipe, functions)
+		case *parse.PipeNode:
+			if n != nil {
+				addTernaryConditions(missing, n, functions)
+			}
+		}
+	})
+
+	return missing
+}
+
+// addTernaryConditions adds missing placeholders used as ternary conditions
+// passed as the last argument or piped from the previous command.
+func addTernaryConditions(missing template.FuncMap, pipe *parse.PipeNode, functions []template.FuncMap) {
+	for index, command := range pipe.Cmds {
+		if identifier, ok := command.Args[0].(*parse.IdentifierNode); !ok || (identifier.Ident != ternaryFunction) {
+			continue
+		}
+
+		switch {
+		case len(command.Args) == 4:
+			addConditionArgument(missing, command.Args[3], functions)
+		case (len(command.Args) == 3) && (index > 0) && (len(pipe.Cmds[index-1].Args) == 1):
+			addConditionArgument(missing, pipe.Cmds[index-1].Args[0], functions)
+		}
+	}
+}
+
+// addCondition adds missing placeholders used in condition directly or as
+// arguments of the not, and and or functions.
+func addCondition(missing template.FuncMap, pipe *parse.PipeNode, functions []template.FuncMap) {
+	if (pipe == nil) || (len(pipe.Decl) != 0) || (len(pipe.Cmds) != 1) {
+		return
+	}
+
+	arguments := pipe.Cmds[0].Args
+
+	if identifier, ok := arguments[0].(*parse.IdentifierNode); ok && (len(arguments) > 1) {
+		switch identifier.Ident {
+		case "not", "and", "or":
+			arguments = arguments[1:]
+		default:
+			return
+		}
+	} else if len(arguments) != 1 {
+		return
+	}
+
+	for _, argument := range arguments {
+		addConditionArgument(missing, argument, functions)
+	}
+}
+
+func addConditionArgument(missing template.FuncMap, argument parse.Node, functions []template.FuncMap) {
+	switch n := argument.(type) {
+	case *parse.PipeNode:
+		addCondition(missing, n, functions)
+	case *parse.ChainNode:
+		if identifier, ok := n.Node.(*parse.IdentifierNode); ok && !isDefined(identifier.Ident, functions) {
+			missing[identifier.Ident] = missingPlaceholder
+		}
+	case *parse.IdentifierNode:
+		if !isDefined(n.Ident, functions) {
+			missing[n.Ident] = missingPlaceholder
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// These constants define policies used when many arguments like Named, Args,
+// Arg or maps define the same placeholder name.
+const (
+	// LastWins uses value from the last argument defining name.
+	LastWins ConflictPolicy = iota
+
+	// FirstWins uses value from the first argument defining name.
+	FirstWins
+
+	// ConflictError reports names defined by many arguments as
+	// ErrNameConflict.
+	ConflictError
+)
+
+// ErrNameConflict is returned with ConflictError policy when many arguments
+// define the same placeholder name.
+const ErrNameConflict = fError("placeholder names defined by many arguments")
+
+// ConflictPolicy defines which value is used when many arguments define the
+// same placeholder name.
+type ConflictPolicy int
+
+// Conflicts returns sorted placeholder names defined by more than one of
+// provided arguments like Named, Args, Arg or maps with string keys.
+func Conflicts(arguments ...interface{}) []string {
+	defined := make(map[string]int)
+	conflicts := []string{}
+
+	for position, argument := range arguments {
+		for _, name := range argumentNames(argument) {
+			if previous, ok := defined[name]; ok && (previous != position) && (previous >= 0) {
+				conflicts = append(conflicts, name)
+				defined[name] = -1
+			} else if !ok {
+				defined[name] = position
+			}
+		}
+	}
+
+	sort.Strings(conflicts)
+
+	return conflicts
+}
+
+// SetConflictPolicy sets policy used when many arguments define the same
+// placeholder name. Default is LastWins.
+func (f *Formatter) SetConflictPolicy(policy ConflictPolicy) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.conflictPolicy = policy
+
+	return f
+}
+
+// GetConflictPolicy returns policy used when many arguments define the same
+// placeholder name.
+func (f *Formatter) GetConflictPolicy() ConflictPolicy {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.conflictPolicy
+}
+
+// checkConflicts returns ErrNameConflict with conflicting names for
+// ConflictError policy.
+func (f *config) checkConflicts(arguments []interface{}) error {
+	if f.conflictPolicy != ConflictError {
+		return nil
+	}
+
+	if conflicts := Conflicts(arguments...); len(conflicts) > 0 {
+		return fmt.Errorf("%w: %s", ErrNameConflict, strings.Join(conflicts, ", "))
+	}
+
+	return nil
+}
+
+// argumentNames returns placeholder names defined by argument.
+func argumentNames(argument interface{}) []string {
+	switch value := argument.(type) {
+	case Argument:
+		return []string{value.Name}
+	case Args:
+		return mapNames(reflect.ValueOf(value))
+	case error, *lazyValue:
+		return nil
+	}
+
+	if valueOf := reflect.ValueOf(argument); (valueOf.Kind() == reflect.Map) && (valueOf.Type().Key().Kind() == reflect.String) {
+		return mapNames(valueOf)
+	}
+
+	return nil
+}
+
+func mapNames(value reflect.Value) []string {
+	names := make([]string, 0, value.Len())
+
+	for iterator := value.MapRange(); iterator.Next(); {
+		names = append(names, iterator.Key().String())
+	}
+
+	return names
+}
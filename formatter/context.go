@@ -0,0 +1,206 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+const contextFunction = "_context"
+
+// FormatContext formats string like Format. It aborts formatting when
+// provided context is done. Context is checked at every range iteration and
+// write, function call that doesn't return is not interrupted and it keeps
+// running in background.
+func FormatContext(ctx context.Context, message string, arguments ...interface{}) (string, error) {
+	return New().FormatContext(ctx, message, arguments...)
+}
+
+// FormatContext formats string like Format. It aborts formatting when
+// provided context is done or when execution timeout expires. Context is
+// checked at every range iteration and write, function call that doesn't
+// return is not interrupted and it keeps running in background.
+func (f *Formatter) FormatContext(ctx context.Context, message string, arguments ...interface{}) (string, error) {
+	buffer := getBuffer()
+	defer putBuffer(buffer)
+
+	if err := f.FormatWriterContext(ctx, buffer, message, arguments...); err != nil {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
+
+// FormatWriterContext formats string to writer like FormatWriter. It aborts
+// formatting when provided context is done or when execution timeout expires.
+// Nothing is written to writer in that case.
+func (f *Formatter) FormatWriterContext(ctx context.Context, writer io.Writer, message string, arguments ...interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c, arguments := f.snapshot().withOptions(arguments)
+
+	return c.formatWriter(ctx, writer, message, arguments...)
+}
+
+// SetExecutionTimeout sets maximum duration of template execution. Zero value
+// disables it. Default is 0. Execution is checked at every range iteration and
+// write, function call that doesn't return is not interrupted and it keeps
+// running in background after formatting returned an error.
+func (f *Formatter) SetExecutionTimeout(timeout time.Duration) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.executionTimeout = timeout
+
+	return f
+}
+
+// GetExecutionTimeout returns maximum duration of template execution.
+func (f *Formatter) GetExecutionTimeout() time.Duration {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.executionTimeout
+}
+
+// ResetExecutionTimeout disables execution timeout.
+func (f *Formatter) ResetExecutionTimeout() *Formatter {
+	return f.SetExecutionTimeout(0)
+}
+
+// withContext runs provided function in a separate goroutine when context can
+// be done. Output is buffered and written to writer only when function
+// finished before context is done. Writes made after context is done return
+// an error that stops template execution. Function gets context with applied
+// execution timeout.
+func (f *config) withContext(ctx context.Context, writer io.Writer, run func(ctx context.Context, writer io.Writer) error) error {
+	if f.executionTimeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, f.executionTimeout)
+		defer cancel()
+	}
+
+	if ctx.Done() == nil {
+		return run(ctx, writer)
+	}
+
+	buffer := new(bytes.Buffer)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- run(ctx, &contextWriter{ctx: ctx, writer: buffer})
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			return err
+		}
+
+		_, err = buffer.WriteTo(writer)
+
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// isContextChecked returns true if execution can be aborted by context or
+// execution timeout.
+func (f *config) isContextChecked(ctx context.Context) bool {
+	return (ctx.Done() != nil) || (f.executionTimeout > 0)
+}
+
+// checkContextTrees adds action calling context function at the beginning of
+// every range loop body, so loops that don't write stop when context is done.
+func checkContextTrees(trees map[string]*parse.Tree) {
+	walkTrees(trees, func(tree *parse.Tree, node parse.Node) {
+		if n, ok := node.(*parse.RangeNode); ok && (n.List != nil) && !isContextAction(n.List) {
+			n.List.Nodes = append([]parse.Node{&parse.ActionNode{
+				NodeType: parse.NodeAction,
+				Pos:      n.Pos,
+				Line:     n.Line,
+				Pipe: &parse.PipeNode{
+					NodeType: parse.NodePipe,
+					Pos:      n.Pos,
+					Line:     n.Line,
+					Cmds: []*parse.CommandNode{{
+						NodeType: parse.NodeCommand,
+						Pos:      n.Pos,
+						Args:     []parse.Node{parse.NewIdentifier(contextFunction).SetTree(tree).SetPos(n.Pos)},
+					}},
+				},
+			}}, n.List.Nodes...)
+		}
+	})
+}
+
+func isContextAction(list *parse.ListNode) bool {
+	if len(list.Nodes) == 0 {
+		return false
+	}
+
+	action, ok := list.Nodes[0].(*parse.ActionNode)
+
+	if !ok || (len(action.Pipe.Cmds) != 1) {
+		return false
+	}
+
+	identifier, ok := action.Pipe.Cmds[0].Args[0].(*parse.IdentifierNode)
+
+	return ok && (identifier.Ident == contextFunction)
+}
+
+// contextFunctions returns context function that returns an error when
+// provided context is done.
+func contextFunctions(ctx context.Context) template.FuncMap {
+	return template.FuncMap{contextFunction: func() (string, error) {
+		return "", ctx.Err()
+	}}
+}
+
+// contextWriter implements io.Writer that fails when context is done.
+type contextWriter struct {
+	ctx    context.Context
+	writer io.Writer
+}
+
+func (w *contextWriter) Write(data []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return w.writer.Write(data)
+}
+
+func (w *contextWriter) WriteString(text string) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return io.WriteString(w.writer, text)
+}
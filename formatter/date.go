@@ -0,0 +1,117 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"strings"
+	"time"
+)
+
+var gTimeLayouts = map[string]string{ // nolint: gochecknoglobals
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"ISO8601":     time.RFC3339,
+	"Kitchen":     time.Kitchen,
+	"Stamp":       time.Stamp,
+	"StampMilli":  time.StampMilli,
+	"StampMicro":  time.StampMicro,
+	"StampNano":   time.StampNano,
+	"DateTime":    "2006-01-02 15:04:05",
+	"DateOnly":    "2006-01-02",
+	"TimeOnly":    "15:04:05",
+}
+
+func setTimezone(name string, t time.Time) (time.Time, error) {
+	location, err := time.LoadLocation(name)
+
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return t.In(location), nil
+}
+
+func (l *Locale) formatDate(layout string, t time.Time) string {
+	if alias, ok := gTimeLayouts[layout]; ok {
+		layout = alias
+	}
+
+	var builder strings.Builder
+
+	for layout != "" {
+		name, length := l.dateName(layout, t)
+
+		if length == 0 {
+			length = 1 + dateNameIndex(layout[1:])
+			builder.WriteString(t.Format(layout[:length]))
+		} else {
+			builder.WriteString(name)
+		}
+
+		layout = layout[length:]
+	}
+
+	return builder.String()
+}
+
+func (l *Locale) dateName(layout string, t time.Time) (name string, length int) {
+	switch {
+	case strings.HasPrefix(layout, "January"):
+		return l.Months[t.Month()-1], len("January")
+	case hasShortDateName(layout, "Jan"):
+		return l.ShortMonths[t.Month()-1], len("Jan")
+	case strings.HasPrefix(layout, "Monday"):
+		return l.Days[t.Weekday()], len("Monday")
+	case hasShortDateName(layout, "Mon"):
+		return l.ShortDays[t.Weekday()], len("Mon")
+	default:
+		return "", 0
+	}
+}
+
+func dateNameIndex(layout string) int {
+	for index := range layout {
+		if isDateName(layout[index:]) {
+			return index
+		}
+	}
+
+	return len(layout)
+}
+
+// isDateName returns true if layout starts with month or day name.
+func isDateName(layout string) bool {
+	return strings.HasPrefix(layout, "January") || strings.HasPrefix(layout, "Monday") ||
+		hasShortDateName(layout, "Jan") || hasShortDateName(layout, "Mon")
+}
+
+// hasShortDateName returns true if layout starts with short name like Jan
+// that is not followed by lower case letter like in Janitor, the same as in
+// time package.
+func hasShortDateName(layout, name string) bool {
+	if !strings.HasPrefix(layout, name) {
+		return false
+	}
+
+	return len(layout) == len(name) || layout[len(name)] < 'a' || layout[len(name)] > 'z'
+}
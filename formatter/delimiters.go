@@ -0,0 +1,149 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Delimiters defines additional pair of delimiters.
+type Delimiters struct {
+	Left  string `json:"left" yaml:"left"`
+	Right string `json:"right" yaml:"right"`
+}
+
+// AddDelimiters adds additional pair of delimiters recognized together with
+// main delimiters. For example with added <% %> values can use {} while
+// control actions use <% %>. Actions with additional delimiters work exactly
+// like actions with main delimiters and doubled left delimiter like <%<%
+// prints it literally. Pair with empty delimiter is ignored.
+func (f *Formatter) AddDelimiters(left, right string) *Formatter {
+	if (left == "") || (right == "") {
+		return f
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.addedDelimiters = append(append([]Delimiters(nil), f.addedDelimiters...), Delimiters{Left: left, Right: right})
+
+	return f
+}
+
+// GetAddedDelimiters returns additional pairs of delimiters.
+func (f *Formatter) GetAddedDelimiters() []Delimiters {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return append([]Delimiters(nil), f.addedDelimiters...)
+}
+
+// ResetAddedDelimiters removes all additional pairs of delimiters.
+func (f *Formatter) ResetAddedDelimiters() *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.addedDelimiters = nil
+
+	return f
+}
+
+// translateDelimiters translates actions with additional delimiters to
+// actions with main delimiters. Actions with main delimiters are left intact.
+func (f *config) translateDelimiters(message string) string {
+	if !f.hasAddedDelimiters(message) {
+		return message
+	}
+
+	var builder strings.Builder
+
+	start := 0
+
+	for index := 0; index < len(message); {
+		rest := message[index:]
+
+		if pair, ok := f.addedDelimitersAt(rest); ok {
+			builder.WriteString(message[start:index])
+
+			if strings.HasPrefix(rest, pair.Left+pair.Left) {
+				builder.WriteString(f.leftDelimiter + strconv.Quote(pair.Left) + f.rightDelimiter)
+				index += 2 * len(pair.Left)
+			} else {
+				end := actionEnd(message, index+len(pair.Left), pair.Right)
+				action := message[index+len(pair.Left) : end]
+
+				builder.WriteString(f.leftDelimiter)
+
+				if strings.HasSuffix(action, pair.Right) {
+					builder.WriteString(strings.TrimSuffix(action, pair.Right) + f.rightDelimiter)
+				} else {
+					builder.WriteString(action)
+				}
+
+				index = end
+			}
+
+			start = index
+
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(rest, f.leftDelimiter+f.leftDelimiter):
+			index += 2 * len(f.leftDelimiter)
+		case strings.HasPrefix(rest, f.leftDelimiter+commentStart):
+			index = commentEndIndex(message, index+len(f.leftDelimiter)+len(commentStart), f.rightDelimiter)
+		case strings.HasPrefix(rest, f.leftDelimiter):
+			index = actionEnd(message, index+len(f.leftDelimiter), f.rightDelimiter)
+		default:
+			index++
+		}
+	}
+
+	builder.WriteString(message[start:])
+
+	return builder.String()
+}
+
+// hasAddedDelimiters returns true if message contains any additional left
+// delimiter.
+func (f *config) hasAddedDelimiters(message string) bool {
+	if (f.leftDelimiter == "") || (f.rightDelimiter == "") {
+		return false
+	}
+
+	for _, pair := range f.addedDelimiters {
+		if strings.Contains(message, pair.Left) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addedDelimitersAt returns additional pair of delimiters with left delimiter
+// at the beginning of text. The longest matching left delimiter wins.
+func (f *config) addedDelimitersAt(text string) (Delimiters, bool) {
+	var found Delimiters
+
+	for _, pair := range f.addedDelimiters {
+		if strings.HasPrefix(text, pair.Left) && (len(pair.Left) > len(found.Left)) {
+			found = pair
+		}
+	}
+
+	return found, found.Left != ""
+}
@@ -0,0 +1,216 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Diagnostic describes suspicious delimiter sequence in message like
+// unbalanced delimiter or JSON object that is likely to be misparsed as an
+// action.
+type Diagnostic struct {
+	// Text is suspicious part of message.
+	Text string
+
+	// Location is location of suspicious part in message like :1:5.
+	Location string
+
+	// Message describes the problem like unbalanced right delimiter.
+	Message string
+
+	// Suggestion describes how to fix the problem like escaping delimiters.
+	Suggestion string
+}
+
+// ValidationError is returned by Validate for message that cannot be parsed
+// or that contains suspicious delimiter sequences.
+type ValidationError struct {
+	// Diagnostics holds all found suspicious delimiter sequences.
+	Diagnostics []Diagnostic
+
+	// Err is parse error or nil if message can be parsed.
+	Err error
+}
+
+// String returns diagnostic like :1:5: unbalanced right delimiter, escape it
+// as "}}".
+func (d Diagnostic) String() string {
+	return d.Location + ": " + d.Message + ", " + d.Suggestion
+}
+
+// Error returns parse error and all diagnostics separated by semicolons.
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Diagnostics)+1)
+
+	if e.Err != nil {
+		messages = append(messages, e.Err.Error())
+	}
+
+	for _, diagnostic := range e.Diagnostics {
+		messages = append(messages, diagnostic.String())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns parse error.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Diagnose returns suspicious delimiter sequences used in message in order of
+// appearance.
+func Diagnose(message string) []Diagnostic {
+	return New().Diagnose(message)
+}
+
+// Validate returns ValidationError if message cannot be parsed or if it
+// contains suspicious delimiter sequences reported by Diagnose.
+func Validate(message string) error {
+	return New().Validate(message)
+}
+
+// Diagnose returns suspicious delimiter sequences used in message in order of
+// appearance. Content of raw blocks is not checked.
+func (f *Formatter) Diagnose(message string) []Diagnostic {
+	return f.snapshot().diagnose(message)
+}
+
+// Validate returns ValidationError if message cannot be parsed or if it
+// contains suspicious delimiter sequences reported by Diagnose.
+func (f *Formatter) Validate(message string) error {
+	c := f.snapshot()
+
+	diagnostics := c.diagnose(message)
+	_, err := c.references(message, nil)
+
+	if (err == nil) && (len(diagnostics) == 0) {
+		return nil
+	}
+
+	return &ValidationError{Diagnostics: diagnostics, Err: err}
+}
+
+func (f *config) diagnose(message string) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	offset, skip := 0, 0
+
+	add := func(offset int, text, problem, suggestion string) {
+		diagnostics = append(diagnostics, Diagnostic{
+			Text:       text,
+			Location:   location(message, offset),
+			Message:    problem,
+			Suggestion: suggestion,
+		})
+	}
+
+	for _, s := range scan(message, f.leftDelimiter, f.rightDelimiter) {
+		start := offset
+		offset += len(s.text)
+
+		if start < skip {
+			continue
+		}
+
+		switch s.kind {
+		case textSegment:
+			if index := strings.Index(s.text, f.rightDelimiter); index >= 0 {
+				add(start+index, f.rightDelimiter, "unbalanced right delimiter",
+					"escape it as "+strconv.Quote(f.rightDelimiter+f.rightDelimiter))
+			}
+		case actionSegment:
+			if f.isRawAction(s.text) {
+				if end := strings.Index(message[offset:], f.leftDelimiter+rawEndKeyword+f.rightDelimiter); end >= 0 {
+					skip = offset + end + len(f.leftDelimiter+rawEndKeyword+f.rightDelimiter)
+				}
+
+				continue
+			}
+
+			if problem, suggestion := f.diagnoseAction(s.text); problem != "" {
+				add(start, s.text, problem, suggestion)
+			}
+		}
+	}
+
+	return diagnostics
+}
+
+// diagnoseAction returns problem and suggestion for suspicious action or
+// empty strings for valid looking action.
+func (f *config) diagnoseAction(action string) (problem, suggestion string) {
+	escape := "escape braces as " + strconv.Quote(f.leftDelimiter+f.leftDelimiter) + " and " +
+		strconv.Quote(f.rightDelimiter+f.rightDelimiter) + " or use other delimiters"
+
+	if strings.HasPrefix(action, f.leftDelimiter+commentStart) && !strings.HasSuffix(action, commentEnd+f.rightDelimiter) {
+		return "unclosed comment", "close it with " + strconv.Quote(commentEnd+f.rightDelimiter)
+	}
+
+	if !strings.HasSuffix(action, f.rightDelimiter) || (len(action) < len(f.leftDelimiter)+len(f.rightDelimiter)) {
+		return "unclosed action", "escape left delimiter as " + strconv.Quote(f.leftDelimiter+f.leftDelimiter) +
+			" or use other delimiters"
+	}
+
+	inner := strings.TrimSpace(action[len(f.leftDelimiter) : len(action)-len(f.rightDelimiter)])
+
+	switch {
+	case inner == "":
+		if f.placeholderStyle == PythonStyle {
+			return "", ""
+		}
+
+		return "empty action", "use " + strconv.Quote(f.leftDelimiter+f.placeholder+f.rightDelimiter) +
+			" placeholder or " + escape
+	case isJSONMember(inner):
+		return "action looks like JSON object", escape
+	case containsUnquoted(inner, f.leftDelimiter):
+		return "left delimiter inside action", escape
+	}
+
+	return "", ""
+}
+
+// isJSONMember returns true if text starts with quoted string followed by
+// colon like "id": 3.
+func isJSONMember(text string) bool {
+	if !strings.HasPrefix(text, `"`) {
+		return false
+	}
+
+	end := quotedEnd(text, 1, '"')
+
+	return strings.HasPrefix(strings.TrimSpace(text[end:]), ":")
+}
+
+// containsUnquoted returns true if text contains substring outside of quoted
+// strings and characters.
+func containsUnquoted(text, substring string) bool {
+	for index := 0; index < len(text); {
+		if strings.HasPrefix(text[index:], substring) {
+			return true
+		}
+
+		switch quote := text[index]; quote {
+		case '"', '\'', '`':
+			index = quotedEnd(text, index+1, quote)
+		default:
+			index++
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,283 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// These constants define options of the diff function.
+const (
+	diffInline = "inline"
+	diffColor  = "color"
+)
+
+// DiffContext defines number of unchanged lines around changes in unified
+// diff.
+const DiffContext = 3
+
+// diffOperation defines a single line or token of diff. Kind is one of ' ',
+// '-' or '+'.
+type diffOperation struct {
+	kind byte
+	text string
+}
+
+// diffFunction is the diff function registered in built-in functions. It is
+// replaced by formatter specific function during formatting.
+func diffFunction(a, b interface{}, options ...string) string {
+	return (&config{}).diff(false)(a, b, options...)
+}
+
+// diff returns diff function. Strings are compared directly, other values are
+// compared using their dump forms. Options are inline for word diff and color
+// for colorized output that is used only when colors are enabled.
+func (f *config) diff(colored bool) func(a, b interface{}, options ...string) string {
+	return func(a, b interface{}, options ...string) string {
+		inline, color := false, false
+
+		for _, option := range options {
+			switch option {
+			case diffInline:
+				inline = true
+			case diffColor:
+				color = colored
+			}
+		}
+
+		if inline {
+			return inlineDiff(diffOperations(diffTokens(f.diffText(a)), diffTokens(f.diffText(b))), color)
+		}
+
+		return unifiedDiff(diffOperations(strings.Split(f.diffText(a), "\n"), strings.Split(f.diffText(b), "\n")), color)
+	}
+}
+
+func (f *config) diffText(value interface{}) string {
+	if text, ok := evaluate(value).(string); ok {
+		return text
+	}
+
+	return f.dump(value)
+}
+
+// diffOperations returns operations transforming a into b using the longest
+// common subsequence. Common prefix and suffix are excluded from comparison.
+func diffOperations(a, b []string) []diffOperation {
+	prefix := 0
+
+	for (prefix < len(a)) && (prefix < len(b)) && (a[prefix] == b[prefix]) {
+		prefix++
+	}
+
+	suffix := 0
+
+	for (suffix < len(a)-prefix) && (suffix < len(b)-prefix) && (a[len(a)-1-suffix] == b[len(b)-1-suffix]) {
+		suffix++
+	}
+
+	operations := make([]diffOperation, 0, len(a)+len(b))
+
+	for _, text := range a[:prefix] {
+		operations = append(operations, diffOperation{kind: ' ', text: text})
+	}
+
+	x, y := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	lengths := make([][]int, len(x)+1)
+
+	for i := range lengths {
+		lengths[i] = make([]int, len(y)+1)
+	}
+
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = maxInt(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+
+	for (i < len(x)) || (j < len(y)) {
+		switch {
+		case (i < len(x)) && (j < len(y)) && (x[i] == y[j]):
+			operations = append(operations, diffOperation{kind: ' ', text: x[i]})
+			i++
+			j++
+		case (j >= len(y)) || ((i < len(x)) && (lengths[i+1][j] >= lengths[i][j+1])):
+			operations = append(operations, diffOperation{kind: '-', text: x[i]})
+			i++
+		default:
+			operations = append(operations, diffOperation{kind: '+', text: y[j]})
+			j++
+		}
+	}
+
+	for _, text := range a[len(a)-suffix:] {
+		operations = append(operations, diffOperation{kind: ' ', text: text})
+	}
+
+	return operations
+}
+
+// unifiedDiff renders line operations as hunks of unified diff without file
+// headers. It returns empty string if there are no changes.
+func unifiedDiff(operations []diffOperation, color bool) string {
+	var builder strings.Builder
+
+	for index := 0; index < len(operations); {
+		if operations[index].kind == ' ' {
+			index++
+			continue
+		}
+
+		start, last, end := maxInt(index-DiffContext, 0), index, index
+
+		for (end < len(operations)) && (end-last <= 2*DiffContext) {
+			if operations[end].kind != ' ' {
+				last = end
+			}
+
+			end++
+		}
+
+		end = last + DiffContext + 1
+
+		if end > len(operations) {
+			end = len(operations)
+		}
+
+		writeHunk(&builder, operations, start, end, color)
+		index = end
+	}
+
+	return strings.TrimSuffix(builder.String(), "\n")
+}
+
+func writeHunk(builder *strings.Builder, operations []diffOperation, start, end int, color bool) {
+	lineA, lineB := 1, 1
+
+	for _, operation := range operations[:start] {
+		if operation.kind != '+' {
+			lineA++
+		}
+
+		if operation.kind != '-' {
+			lineB++
+		}
+	}
+
+	countA, countB := 0, 0
+
+	for _, operation := range operations[start:end] {
+		if operation.kind != '+' {
+			countA++
+		}
+
+		if operation.kind != '-' {
+			countB++
+		}
+	}
+
+	writeDiff(builder, "@@ -"+hunkRange(lineA, countA)+" +"+hunkRange(lineB, countB)+" @@", setCyan(), color)
+	builder.WriteByte('\n')
+
+	for _, operation := range operations[start:end] {
+		switch operation.kind {
+		case '-':
+			writeDiff(builder, "-"+operation.text, setRed(), color)
+		case '+':
+			writeDiff(builder, "+"+operation.text, setGreen(), color)
+		default:
+			builder.WriteString(" " + operation.text)
+		}
+
+		builder.WriteByte('\n')
+	}
+}
+
+func hunkRange(line, count int) string {
+	if count == 0 {
+		line--
+	}
+
+	return strconv.Itoa(line) + "," + strconv.Itoa(count)
+}
+
+// inlineDiff renders token operations in place with removed text as
+// [-text-] and added text as {+text+}.
+func inlineDiff(operations []diffOperation, color bool) string {
+	var builder strings.Builder
+
+	for index := 0; index < len(operations); {
+		kind := operations[index].kind
+		end := index
+
+		var text strings.Builder
+
+		for (end < len(operations)) && (operations[end].kind == kind) {
+			text.WriteString(operations[end].text)
+			end++
+		}
+
+		switch kind {
+		case '-':
+			writeDiff(&builder, "[-"+text.String()+"-]", setRed(), color)
+		case '+':
+			writeDiff(&builder, "{+"+text.String()+"+}", setGreen(), color)
+		default:
+			builder.WriteString(text.String())
+		}
+
+		index = end
+	}
+
+	return builder.String()
+}
+
+func writeDiff(builder *strings.Builder, text, code string, color bool) {
+	if color {
+		builder.WriteString(code + text + setNormal())
+	} else {
+		builder.WriteString(text)
+	}
+}
+
+// diffTokens splits text into words and whitespace runs.
+func diffTokens(text string) []string {
+	var tokens []string
+
+	start, space := 0, false
+
+	for index, r := range text {
+		if (index > start) && (unicode.IsSpace(r) != space) {
+			tokens = append(tokens, text[start:index])
+			start = index
+		}
+
+		space = unicode.IsSpace(r)
+	}
+
+	if start < len(text) {
+		tokens = append(tokens, text[start:])
+	}
+
+	return tokens
+}
@@ -15,6 +15,13 @@
 /*
 Package formatter implements “replacement fields” surrounded by curly braces {} format strings.
 
+Escaping delimiters
+
+Doubled delimiters outside of replacement fields are replaced with single
+literal delimiters. Example:
+
+	formatted, err := formatter.Format(`JSON {{"id": {p}}}`, 3)
+
 Built-in functions
 
 Simple example:
@@ -46,6 +53,64 @@ List of built-in functions:
 	upper      - Transform provided string to upper case. Example: upper "text"
 	lower      - Transform provided string to lower case. Example: lower "TEXT"
 	capitalize - Capitalize provided string. Example: capitalize "text"
+	camelCase  - Convert string to camelCase. Example: camelCase "user name"
+	pascalCase - Convert string to PascalCase. Example: pascalCase "user name"
+	snakeCase  - Convert string to snake_case. Example: snakeCase "UserName"
+	kebabCase  - Convert string to kebab-case. Example: kebabCase "UserName"
+
+	screamingSnakeCase - Convert string to SCREAMING_SNAKE_CASE. Example: screamingSnakeCase "userName"
+	sentenceCase       - Convert string to Sentence case. Example: sentenceCase "user_name"
+	fallback   - Use fallback value if argument is missing, nil or empty string. Example: name | fallback "unknown"
+	format     - Format value implementing Formattable using spec. Example: price | format "short"
+	table      - Render rows as aligned table with selected columns. Example: table .Rows "Name" "Age"
+	json       - Marshal value to JSON. Example: p0 | json
+	jsonIndent - Marshal value to indented JSON, optional indentation. Example: p0 | jsonIndent "    "
+	yaml       - Marshal value to YAML. Example: p0 | yaml
+	toml       - Marshal map or struct to TOML. Example: p0 | toml
+	logfmt     - Render map or struct as sorted key=value pairs. Example: p0 | logfmt
+	dump       - Render nested structs, maps and slices with types and indentation. Example: p0 | dump
+	raw        - Underlying value of enum bypassing String method. Example: p0 | raw
+	errorChain - Error with unwrap chain like "a: b: c", options list and stack. Example: p0 | errorChain "list"
+	stack      - Current stack or stack attached to error, optional depth. Example: p0 | stack 10
+	diff       - Unified diff of two strings or values, options inline and color. Example: diff p0 p1 "inline"
+	mdBold     - Markdown bold text with escaped value. Example: mdBold p0
+	mdCode     - Markdown inline code. Example: mdCode p0
+	mdLink     - Markdown link with escaped text. Example: mdLink "docs" p0
+	mdTable    - Render rows as Markdown table with escaped cells. Example: mdTable .Rows "Name"
+	mdEscape   - Escape Markdown special characters. Example: p0 | mdEscape
+	xmlEscape  - Escape value for XML text and attributes. Example: p0 | xmlEscape
+	urlQuery   - Escape value for URL query. Example: p0 | urlQuery
+	urlPath    - Escape value for URL path segment. Example: p0 | urlPath
+	shellQuote - Quote value as POSIX shell word. Example: p0 | shellQuote
+	join       - Join list items with separator. Example: p0 | join ", "
+	joinAnd    - Join list items with localized "and" conjunction like "a, b, and c". Example: p0 | joinAnd
+	joinOr     - Join list items with localized "or" conjunction like "a, b, or c". Example: p0 | joinOr
+	bullets    - Render list items in separate lines prefixed with marker. Example: p0 | bullets "-"
+	numbered   - Render list items in separate lines prefixed with numbers. Example: p0 | numbered
+	wrap       - Wrap text to lines not longer than width columns. Example: p0 | wrap 80
+	width      - Number of columns used to display text. Example: p0 | width
+	pad        - Pad text with spaces on the right to width columns. Example: p0 | pad 10
+	padLeft    - Pad text with spaces on the left to width columns. Example: p0 | padLeft 10
+	center     - Center text with spaces to width columns. Example: p0 | center 10
+	bytesIEC   - Number of bytes with IEC units like 1.4 GiB, optional precision. Example: p0 | bytesIEC 2
+	bytesSI    - Number of bytes with SI units like 1.5 GB, optional precision. Example: p0 | bytesSI
+	ordinal    - Ordinal number like 1st, 2nd or 3rd. Example: p0 | ordinal
+	spell      - Number spelled out in words like forty-two. Example: p0 | spell
+	plural     - Select plural form for count using locale rule. Example: count | plural "file" "files"
+	yesno      - Localized yes or no for value truth. Example: p0 | yesno
+	onoff      - Localized on or off for value truth. Example: p0 | onoff
+	hex        - Integer in base 16, optional zero-padded width. Example: p0 | hex 4
+	hexPrefix  - Integer in base 16 with 0x prefix, optional zero-padded width. Example: p0 | hexPrefix 8
+	oct        - Integer in base 8, optional zero-padded width. Example: p0 | oct
+	bin        - Integer in base 2, optional zero-padded width. Example: p0 | bin 8
+	bits       - Integer as bits grouped in nibbles, optional number of bits. Example: p0 | bits 16
+	indent     - Indent all lines of text with spaces. Example: p0 | indent 4
+
+	hangingIndent - Indent all lines of text except the first one with spaces. Example: p0 | wrap 60 | hangingIndent 13
+
+Width of text is measured in columns used to display grapheme clusters. East
+Asian wide characters and emoji use two columns. Algorithm can be changed with
+SetWidthAlgorithm.
 
 Built-in color functions
 
@@ -60,12 +125,26 @@ List of built-in functions:
 	cyan       - Cyan color
 	white      - White color
 	gray       - Gray color
+	bgBlack    - Black background color, alias to black | background
+	bgRed      - Red background color
+	bgGreen    - Green background color
+	bgYellow   - Yellow background color
+	bgBlue     - Blue background color
+	bgMagenta  - Magenta background color
+	bgCyan     - Cyan background color
+	bgWhite    - White background color
+	bgGray     - Gray background color
 	rgb        - 24-bit color, 3 arguments (red, green, blue), integer values between 0-255
 	color      - Set color, 1 argument, color name like "red" or RGB HEX value in "0xXXXXXX" format
 	bright     - Make color bright, used with standard color function. Example: green | bright
 	foreground - Set as foreground color (default). Example: blue | foreground
 	background - Set as background color. Example: cyan | background
 
+Text and color functions generate ANSI escape codes according to the color mode
+set by SetColorMode. ColorAlways (default) always generates them, ColorNever
+never generates them and ColorAuto generates them only when formatter writes
+directly to terminal and the NO_COLOR environment variable is not set.
+
 Built-in OS functions
 
 List of built-in functions:
@@ -85,6 +164,9 @@ List of built-in functions:
 	ppid       - Get parent process ID
 	bell       - Make a sound
 
+Access to environment variables can be restricted with SetEnvAllowlist. Allowed
+variables are then also available as placeholders like {env.HOME}.
+
 Built-in time functions
 
 List of built-in functions:
@@ -92,6 +174,19 @@ List of built-in functions:
 	now        - Get current time
 	rfc3339    - Format time to RFC 3339. Example: now | rfc3339
 	iso8601    - Format time to ISO 8601. Example: now | iso8601
+	date       - Format time using layout or layout name like "RFC3339" or "Kitchen". Example: now | date "2006-01-02"
+	tz         - Convert time to location. Example: now | tz "Europe/Warsaw" | date "15:04"
+	ago        - Relative time like "3 minutes ago" or "in 2 hours" for future time. Example: p0 | ago
+	until      - Relative time, alias to ago. Example: p0 | until
+
+	humanizeDuration - Compact duration like "2h 15m". Example: p0 | humanizeDuration
+
+Month and day names produced by the date function, time units produced by
+the ago and until functions, conjunctions used by the joinAnd and joinOr
+functions, plural forms, ordinal numbers, spelled out numbers and texts of
+the yesno and onoff functions are translated according to the formatter
+locale set by SetLocale. Built-in locales are en, pl and de. Other locales
+can be added with RegisterLocale.
 
 Built-in path functions
 
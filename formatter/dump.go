@@ -0,0 +1,255 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// These constants define default limits used by the dump function.
+const (
+	DefaultDumpDepth    = 8
+	DefaultDumpElements = 100
+	DefaultDumpIndent   = "  "
+)
+
+// DumpOptions defines how values are rendered by the dump function. Zero
+// values mean defaults.
+type DumpOptions struct {
+	// MaxDepth limits nesting of rendered structs, maps, slices and
+	// pointers. Deeper values are rendered as {...}.
+	MaxDepth int
+
+	// MaxElements limits number of rendered elements of slices, arrays and
+	// maps. Remaining elements are summarized.
+	MaxElements int
+
+	// Indent is used to indent nested values.
+	Indent string
+}
+
+type dumper struct {
+	*config
+	options DumpOptions
+	builder strings.Builder
+	visited map[uintptr]bool
+}
+
+// SetDumpOptions sets options used by the dump function.
+func (f *Formatter) SetDumpOptions(options DumpOptions) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.dumpOptions = options
+
+	return f
+}
+
+// GetDumpOptions returns options used by the dump function.
+func (f *Formatter) GetDumpOptions() DumpOptions {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.dumpOptions
+}
+
+// dumpFunction is the dump function registered in built-in functions. It is
+// replaced by formatter specific function during formatting.
+func dumpFunction(value interface{}) string {
+	return (&config{}).dump(value)
+}
+
+// dump renders value with types, field names and indentation. Pointer cycles
+// are detected and rendered as <cycle>. Redacted values are replaced.
+func (f *config) dump(value interface{}) string {
+	options := f.dumpOptions
+
+	if options.MaxDepth <= 0 {
+		options.MaxDepth = DefaultDumpDepth
+	}
+
+	if options.MaxElements <= 0 {
+		options.MaxElements = DefaultDumpElements
+	}
+
+	if options.Indent == "" {
+		options.Indent = DefaultDumpIndent
+	}
+
+	d := &dumper{config: f, options: options, visited: make(map[uintptr]bool)}
+	d.value(reflect.ValueOf(evaluate(value)), 0)
+
+	return d.builder.String()
+}
+
+func (d *dumper) value(value reflect.Value, depth int) {
+	if !value.IsValid() {
+		d.builder.WriteString("nil")
+		return
+	}
+
+	if d.redacted[value.Type()] {
+		d.builder.WriteString(strconv.Quote(RedactedText))
+		return
+	}
+
+	if value.CanInterface() && implementsPrinter(value.Type()) && !isNil(value) {
+		rendered, err := d.formatValue(value.Interface())
+
+		if err != nil {
+			rendered = "%!(ERROR=" + err.Error() + ")"
+		}
+
+		d.builder.WriteString(fmt.Sprint(rendered))
+
+		return
+	}
+
+	switch value.Kind() {
+	case reflect.Interface:
+		d.value(value.Elem(), depth)
+	case reflect.Ptr:
+		d.pointer(value, depth)
+	case reflect.Struct:
+		d.structure(value, depth)
+	case reflect.Map:
+		d.mapping(value, depth)
+	case reflect.Slice, reflect.Array:
+		d.list(value, depth)
+	case reflect.String:
+		d.builder.WriteString(strconv.Quote(value.String()))
+	default:
+		d.builder.WriteString(fmt.Sprint(value))
+	}
+}
+
+func (d *dumper) pointer(value reflect.Value, depth int) {
+	if value.IsNil() {
+		d.builder.WriteString("(" + value.Type().String() + ")(nil)")
+		return
+	}
+
+	address := value.Pointer()
+
+	if d.visited[address] {
+		d.builder.WriteString("<cycle " + value.Type().String() + ">")
+		return
+	}
+
+	d.visited[address] = true
+	defer delete(d.visited, address)
+
+	d.builder.WriteString("&")
+	d.value(value.Elem(), depth)
+}
+
+func (d *dumper) structure(value reflect.Value, depth int) {
+	t := value.Type()
+
+	d.open(t.String(), t.NumField() == 0, depth, func() {
+		for index := 0; index < t.NumField(); index++ {
+			field := t.Field(index)
+
+			d.line(depth + 1)
+			d.builder.WriteString(field.Name + ": ")
+
+			if redact, hash := tagOptions(field); redact {
+				d.builder.WriteString(strconv.Quote(redactedField(value.Field(index), hash)))
+			} else {
+				d.value(value.Field(index), depth+1)
+			}
+
+			d.builder.WriteString(",")
+		}
+	})
+}
+
+func (d *dumper) mapping(value reflect.Value, depth int) {
+	if value.IsNil() {
+		d.builder.WriteString(value.Type().String() + "(nil)")
+		return
+	}
+
+	keys := value.MapKeys()
+
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+	})
+
+	d.open(value.Type().String(), len(keys) == 0, depth, func() {
+		for index, key := range keys {
+			if index >= d.options.MaxElements {
+				d.more(len(keys)-index, depth+1)
+				break
+			}
+
+			d.line(depth + 1)
+			d.value(key, depth+1)
+			d.builder.WriteString(": ")
+			d.value(value.MapIndex(key), depth+1)
+			d.builder.WriteString(",")
+		}
+	})
+}
+
+func (d *dumper) list(value reflect.Value, depth int) {
+	if (value.Kind() == reflect.Slice) && value.IsNil() {
+		d.builder.WriteString(value.Type().String() + "(nil)")
+		return
+	}
+
+	d.open(value.Type().String(), value.Len() == 0, depth, func() {
+		for index := 0; index < value.Len(); index++ {
+			if index >= d.options.MaxElements {
+				d.more(value.Len()-index, depth+1)
+				break
+			}
+
+			d.line(depth + 1)
+			d.value(value.Index(index), depth+1)
+			d.builder.WriteString(",")
+		}
+	})
+}
+
+// open renders type and braces around elements rendered by body. Elements
+// nested deeper than limit are replaced with ellipsis.
+func (d *dumper) open(name string, empty bool, depth int, body func()) {
+	d.builder.WriteString(name + "{")
+
+	switch {
+	case empty:
+	case depth >= d.options.MaxDepth:
+		d.builder.WriteString("...")
+	default:
+		body()
+		d.line(depth)
+	}
+
+	d.builder.WriteString("}")
+}
+
+func (d *dumper) more(count, depth int) {
+	d.line(depth)
+	d.builder.WriteString("... " + strconv.Itoa(count) + " more")
+}
+
+func (d *dumper) line(depth int) {
+	d.builder.WriteString("\n" + strings.Repeat(d.options.Indent, depth))
+}
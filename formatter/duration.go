@@ -0,0 +1,99 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	day   = 24 * time.Hour
+	week  = 7 * day
+	month = 30 * day
+	year  = 365 * day
+)
+
+// Now is used only in testing and mocking.
+var Now = time.Now // nolint: gochecknoglobals
+
+var gTimeUnits = []struct { // nolint: gochecknoglobals
+	name     string
+	duration time.Duration
+}{
+	{"year", year},
+	{"month", month},
+	{"week", week},
+	{"day", day},
+	{"hour", time.Hour},
+	{"minute", time.Minute},
+	{"second", time.Second},
+}
+
+var gDurationUnits = []struct { // nolint: gochecknoglobals
+	symbol   string
+	duration time.Duration
+}{
+	{"d", day},
+	{"h", time.Hour},
+	{"m", time.Minute},
+	{"s", time.Second},
+}
+
+func (l *Locale) relative(t time.Time) string {
+	duration := Now().Sub(t)
+	layout := l.Ago
+
+	if duration < 0 {
+		duration = -duration
+		layout = l.Until
+	}
+
+	for _, unit := range gTimeUnits {
+		if duration >= unit.duration {
+			count := int64(duration / unit.duration)
+			return fmt.Sprintf(layout, strconv.FormatInt(count, 10)+" "+l.plural(count, l.TimeUnits[unit.name]))
+		}
+	}
+
+	return l.JustNow
+}
+
+// humanizeDuration returns duration like 1d 2h 3s. Sign is handled on
+// unsigned magnitude, so the minimal duration doesn't overflow.
+func humanizeDuration(duration time.Duration) string {
+	if (duration > -time.Second) && (duration < time.Second) {
+		return duration.String()
+	}
+
+	sign, magnitude := "", uint64(duration)
+
+	if duration < 0 {
+		sign, magnitude = "-", -magnitude
+	}
+
+	var parts []string
+
+	for _, unit := range gDurationUnits {
+		if size := uint64(unit.duration); magnitude >= size {
+			parts = append(parts, strconv.FormatUint(magnitude/size, 10)+unit.symbol)
+			magnitude %= size
+		}
+	}
+
+	return sign + strings.Join(parts, " ")
+}
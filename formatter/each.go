@@ -0,0 +1,91 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"text/template"
+)
+
+// ItemPlaceholder is name of placeholder with current item in FormatEach.
+const ItemPlaceholder = "item"
+
+// FormatEach formats message for every item of slice or array.
+func FormatEach(message string, items interface{}) ([]string, error) {
+	return New().FormatEach(message, items)
+}
+
+// FormatEach formats message for every item of slice or array. Message is
+// parsed only once. Current item is available as {item} placeholder and as
+// data object, so its fields can be used like {.Name}. Formatted strings are
+// returned in order of items, also when some items cannot be formatted.
+// Errors are returned joined as MessageError with item index.
+func (f *Formatter) FormatEach(message string, items interface{}) ([]string, error) {
+	return f.snapshot().formatEach(message, items)
+}
+
+func (f *config) formatEach(message string, items interface{}) ([]string, error) {
+	value := reflect.ValueOf(items)
+
+	if (value.Kind() != reflect.Slice) && (value.Kind() != reflect.Array) {
+		return nil, fmt.Errorf("items must be a slice or an array, got %T", items)
+	}
+
+	message = f.translate(message)
+	key := TemplateKey(message)
+
+	buffer := getBuffer()
+	defer putBuffer(buffer)
+
+	var item interface{}
+
+	trees, functions, err := f.parseTraced(context.Background(), key, message, f.functionMaps(buffer, template.FuncMap{
+		ItemPlaceholder: func() interface{} {
+			return item
+		},
+	}))
+
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]string, value.Len())
+	errs := make([]error, value.Len())
+
+	for index := range results {
+		item = evaluate(lazy(f.hookArguments(f.redactArguments([]interface{}{value.Index(index).Interface()}))[0]))
+
+		buffer.Reset()
+
+		if err := f.measure(buffer, func(writer io.Writer) error {
+			return f.executeTraced(context.Background(), key, f.limitWriter(writer), trees, functions, item)
+		}); err != nil {
+			errs[index] = &MessageError{Index: index, Err: err}
+			continue
+		}
+
+		if f.outputHook != nil {
+			results[index] = f.outputHook(buffer.String())
+		} else {
+			results[index] = buffer.String()
+		}
+	}
+
+	return results, errors.Join(errs...)
+}
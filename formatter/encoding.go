@@ -0,0 +1,68 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const yamlIndent = 2
+
+// yamlFunction is the yaml function registered in built-in functions. It is
+// replaced by formatter specific function during formatting.
+func yamlFunction(value interface{}) (string, error) {
+	return (&config{}).yaml(value)
+}
+
+// tomlFunction is the toml function registered in built-in functions. It is
+// replaced by formatter specific function during formatting.
+func tomlFunction(value interface{}) (string, error) {
+	return (&config{}).toml(value)
+}
+
+// yaml marshals value to YAML. Redacted values are replaced.
+func (f *config) yaml(value interface{}) (string, error) {
+	var buffer bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(yamlIndent)
+
+	if err := encoder.Encode(f.redactedCopy(evaluate(value))); err != nil {
+		return "", fmt.Errorf("cannot marshal %T to YAML: %w", value, err)
+	}
+
+	if err := encoder.Close(); err != nil {
+		return "", fmt.Errorf("cannot marshal %T to YAML: %w", value, err)
+	}
+
+	return strings.TrimSuffix(buffer.String(), "\n"), nil
+}
+
+// toml marshals value to TOML. Value must be a map or a struct. Redacted
+// values are replaced.
+func (f *config) toml(value interface{}) (string, error) {
+	var buffer bytes.Buffer
+
+	if err := toml.NewEncoder(&buffer).Encode(f.redactedCopy(evaluate(value))); err != nil {
+		return "", fmt.Errorf("cannot marshal %T to TOML: %w", value, err)
+	}
+
+	return strings.TrimSuffix(buffer.String(), "\n"), nil
+}
@@ -0,0 +1,383 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"reflect"
+	"text/template"
+	"text/template/parse"
+)
+
+// ErrUnsupportedNode is returned by SubstitutionEngine for message with
+// actions other than placeholders and function pipelines.
+const ErrUnsupportedNode = fError("node is not supported by substitution engine")
+
+// gSubstitutionFunctions defines text/template built-in functions supported
+// by SubstitutionEngine.
+var gSubstitutionFunctions = template.FuncMap{ // nolint: gochecknoglobals
+	"html":     template.HTMLEscaper,
+	"js":       template.JSEscaper,
+	"len":      substitutionLen,
+	"print":    fmt.Sprint,
+	"printf":   fmt.Sprintf,
+	"println":  fmt.Sprintln,
+	"urlquery": template.URLQueryEscaper,
+}
+
+// Engine executes parsed message. Trees hold templates by name, main template
+// has empty name. Functions are applied in order, so later functions replace
+// earlier ones with the same name.
+type Engine interface {
+	Execute(writer io.Writer, trees map[string]*parse.Tree, functions []template.FuncMap, data interface{}) error
+}
+
+// TextEngine executes message with text/template. It is the default engine.
+type TextEngine struct{}
+
+// HTMLEngine executes message with html/template that escapes values
+// depending on context. It is the default engine in HTML-safe mode.
+type HTMLEngine struct{}
+
+// SubstitutionEngine executes message without text/template. It supports only
+// text, comments and actions with placeholders, constants and function
+// pipelines like {p0 | upper}. It does not escape values. Other actions like
+// {if}, {range}, {template} or variables fail with ErrUnsupportedNode.
+type SubstitutionEngine struct{}
+
+// SetEngine sets engine that executes parsed message. Nil restores default
+// engine, TextEngine or HTMLEngine in HTML-safe mode.
+func (f *Formatter) SetEngine(engine Engine) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.engine = engine
+
+	return f
+}
+
+// GetEngine returns engine that executes parsed message. It returns nil if
+// default engine is used.
+func (f *Formatter) GetEngine() Engine {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.engine
+}
+
+// executionEngine returns configured or default engine.
+func (f *config) executionEngine() Engine {
+	switch {
+	case f.engine != nil:
+		return f.engine
+	case f.safeHTML:
+		return HTMLEngine{}
+	default:
+		return TextEngine{}
+	}
+}
+
+// Execute executes message with text/template.
+func (TextEngine) Execute(writer io.Writer, trees map[string]*parse.Tree, functions []template.FuncMap, data interface{}) error {
+	t := template.New("")
+
+	for _, funcs := range functions {
+		t.Funcs(funcs)
+	}
+
+	for name, tree := range trees {
+		if _, err := t.AddParseTree(name, tree); err != nil {
+			return err
+		}
+	}
+
+	return t.Execute(writer, data)
+}
+
+// Execute executes message with html/template.
+func (HTMLEngine) Execute(writer io.Writer, trees map[string]*parse.Tree, functions []template.FuncMap, data interface{}) error {
+	t := htmltemplate.New("")
+
+	for _, funcs := range functions {
+		t.Funcs(htmltemplate.FuncMap(funcs))
+	}
+
+	main := t
+
+	for name, tree := range trees {
+		added, err := t.AddParseTree(name, tree)
+
+		if err != nil {
+			return err
+		}
+
+		if name == t.Name() {
+			main = added
+		}
+	}
+
+	return main.Execute(writer, data)
+}
+
+// Execute executes main template by substituting placeholders with values.
+func (SubstitutionEngine) Execute(writer io.Writer, trees map[string]*parse.Tree, functions []template.FuncMap, data interface{}) error {
+	tree, ok := trees[""]
+
+	if !ok || (tree.Root == nil) {
+		return nil
+	}
+
+	s := &substitution{functions: append([]template.FuncMap{gSubstitutionFunctions}, functions...), data: reflect.ValueOf(data)}
+
+	return s.list(writer, tree.Root)
+}
+
+type substitution struct {
+	functions []template.FuncMap
+	data      reflect.Value
+}
+
+func (s *substitution) list(writer io.Writer, list *parse.ListNode) error {
+	for _, node := range list.Nodes {
+		switch n := node.(type) {
+		case *parse.TextNode:
+			if _, err := writer.Write(n.Text); err != nil {
+				return err
+			}
+		case *parse.CommentNode:
+		case *parse.ActionNode:
+			if len(n.Pipe.Decl) != 0 {
+				return unsupportedNode(n)
+			}
+
+			value, err := s.pipe(n.Pipe)
+
+			if err != nil {
+				return err
+			}
+
+			printable, err := printableValue(value)
+
+			if err != nil {
+				return err
+			}
+
+			if _, err := fmt.Fprint(writer, printable); err != nil {
+				return err
+			}
+		default:
+			return unsupportedNode(node)
+		}
+	}
+
+	return nil
+}
+
+func (s *substitution) pipe(pipe *parse.PipeNode) (reflect.Value, error) {
+	if (pipe == nil) || (len(pipe.Decl) != 0) {
+		return reflect.Value{}, unsupportedNode(pipe)
+	}
+
+	var value reflect.Value
+
+	for position, command := range pipe.Cmds {
+		var err error
+
+		if value, err = s.command(command, value, position != 0); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+
+	return value, nil
+}
+
+func (s *substitution) command(command *parse.CommandNode, final reflect.Value, piped bool) (reflect.Value, error) {
+	if identifier, ok := command.Args[0].(*parse.IdentifierNode); ok {
+		return s.call(identifier, command.Args[1:], final, piped)
+	}
+
+	if piped || (len(command.Args) != 1) {
+		return reflect.Value{}, unsupportedNode(command)
+	}
+
+	return s.argument(command.Args[0], nil)
+}
+
+// call calls function with arguments and final value piped from previous
+// command.
+func (s *substitution) call(identifier *parse.IdentifierNode, nodes []parse.Node, final reflect.Value,
+	piped bool) (reflect.Value, error) {
+	function, ok := s.function(identifier.Ident)
+
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("function %q not defined", identifier.Ident)
+	}
+
+	functionType := function.Type()
+	count := len(nodes)
+
+	if piped {
+		count++
+	}
+
+	if (!functionType.IsVariadic() && (count != functionType.NumIn())) ||
+		(functionType.IsVariadic() && (count < functionType.NumIn()-1)) {
+		return reflect.Value{}, fmt.Errorf("wrong number of arguments for %q: want %d got %d",
+			identifier.Ident, functionType.NumIn(), count)
+	}
+
+	arguments := make([]reflect.Value, 0, count)
+
+	for _, node := range nodes {
+		value, err := s.argument(node, parameterType(functionType, len(arguments)))
+
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		arguments = append(arguments, value)
+	}
+
+	if piped {
+		arguments = append(arguments, final)
+	}
+
+	for index, argument := range arguments {
+		converted, err := convertArgument(argument, parameterType(functionType, index))
+
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("wrong type of argument %d for %q: %w", index, identifier.Ident, err)
+		}
+
+		arguments[index] = converted
+	}
+
+	results := function.Call(arguments)
+
+	if len(results) == 0 {
+		return reflect.Value{}, fmt.Errorf("function %q returns no value", identifier.Ident)
+	}
+
+	if (len(results) == 2) && !results[1].IsNil() {
+		return reflect.Value{}, fmt.Errorf("error calling %s: %w", identifier.Ident, results[1].Interface().(error))
+	}
+
+	return results[0], nil
+}
+
+func (s *substitution) function(name string) (reflect.Value, bool) {
+	for index := len(s.functions) - 1; index >= 0; index-- {
+		if function, ok := s.functions[index][name]; ok {
+			value := reflect.ValueOf(function)
+
+			return value, value.Kind() == reflect.Func
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+// argument returns value of argument node. Numbers are converted to
+// parameter type if it is provided.
+func (s *substitution) argument(node parse.Node, parameter reflect.Type) (reflect.Value, error) {
+	switch n := node.(type) {
+	case *parse.DotNode:
+		return s.data, nil
+	case *parse.StringNode:
+		return reflect.ValueOf(n.Text), nil
+	case *parse.BoolNode:
+		return reflect.ValueOf(n.True), nil
+	case *parse.NilNode:
+		return reflect.Value{}, nil
+	case *parse.NumberNode:
+		return number(n, parameter)
+	case *parse.PipeNode:
+		return s.pipe(n)
+	case *parse.IdentifierNode:
+		return s.call(n, nil, reflect.Value{}, false)
+	default:
+		return reflect.Value{}, unsupportedNode(node)
+	}
+}
+
+func number(n *parse.NumberNode, parameter reflect.Type) (reflect.Value, error) {
+	kind := reflect.Interface
+
+	if parameter != nil {
+		kind = parameter.Kind()
+	}
+
+	switch {
+	case (kind >= reflect.Int) && (kind <= reflect.Int64) && n.IsInt:
+		return reflect.ValueOf(n.Int64).Convert(parameter), nil
+	case (kind >= reflect.Uint) && (kind <= reflect.Uintptr) && n.IsUint:
+		return reflect.ValueOf(n.Uint64).Convert(parameter), nil
+	case ((kind == reflect.Float32) || (kind == reflect.Float64)) && n.IsFloat:
+		return reflect.ValueOf(n.Float64).Convert(parameter), nil
+	case (kind == reflect.Interface) && n.IsInt:
+		return reflect.ValueOf(int(n.Int64)), nil
+	case (kind == reflect.Interface) && n.IsFloat:
+		return reflect.ValueOf(n.Float64), nil
+	default:
+		return reflect.Value{}, fmt.Errorf("cannot use number %s as %s", n.Text, kind)
+	}
+}
+
+// parameterType returns type of parameter at provided index.
+func parameterType(functionType reflect.Type, index int) reflect.Type {
+	if functionType.IsVariadic() && (index >= functionType.NumIn()-1) {
+		return functionType.In(functionType.NumIn() - 1).Elem()
+	}
+
+	return functionType.In(index)
+}
+
+// convertArgument returns value assignable to parameter type. Invalid value is
+// converted to zero value of nilable types.
+func convertArgument(value reflect.Value, parameter reflect.Type) (reflect.Value, error) {
+	if !value.IsValid() {
+		switch parameter.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+			return reflect.Zero(parameter), nil
+		default:
+			return value, fmt.Errorf("cannot use nil as %s", parameter)
+		}
+	}
+
+	if (value.Kind() == reflect.Interface) && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	if !value.Type().AssignableTo(parameter) {
+		return value, fmt.Errorf("cannot use %s as %s", value.Type(), parameter)
+	}
+
+	return value, nil
+}
+
+func substitutionLen(value interface{}) (int, error) {
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Array, reflect.Chan, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len(), nil
+	default:
+		return 0, fmt.Errorf("len of type %T", value)
+	}
+}
+
+func unsupportedNode(node parse.Node) error {
+	return fmt.Errorf("%w: %s", ErrUnsupportedNode, node)
+}
@@ -0,0 +1,105 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"os"
+	"path"
+	"strings"
+)
+
+// SetEnvAllowlist enables access to environment variables matching provided
+// patterns like HOME or APP_* with {env.HOME} placeholders. Built-in env and
+// expand functions are then restricted to allowed variables, other variables
+// are treated as not set. No patterns restores unrestricted env and expand
+// functions and disables {env.NAME} placeholders.
+func (f *Formatter) SetEnvAllowlist(patterns ...string) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.envAllowlist = append([]string(nil), patterns...)
+
+	return f
+}
+
+// GetEnvAllowlist returns patterns of allowed environment variables.
+func (f *Formatter) GetEnvAllowlist() []string {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return append([]string(nil), f.envAllowlist...)
+}
+
+// envFunctions returns env and expand functions restricted to allowed
+// environment variables or nil if allowlist is not set.
+func (f *config) envFunctions() map[string]interface{} {
+	if len(f.envAllowlist) == 0 {
+		return nil
+	}
+
+	return map[string]interface{}{
+		"env":    f.env,
+		"expand": f.expand,
+	}
+}
+
+// env returns value of allowed environment variable or all allowed variables
+// if name is not provided.
+func (f *config) env(names ...string) interface{} {
+	switch len(names) {
+	case 0:
+		variables := make(map[string]string)
+
+		for _, variable := range os.Environ() {
+			if name, value, ok := strings.Cut(variable, "="); ok && f.isEnvAllowed(name) {
+				variables[name] = value
+			}
+		}
+
+		return variables
+	case 1:
+		return f.getenv(names[0])
+	default:
+		values := make([]string, 0, len(names))
+
+		for _, name := range names {
+			values = append(values, f.getenv(name))
+		}
+
+		return values
+	}
+}
+
+func (f *config) expand(text string) string {
+	return os.Expand(text, f.getenv)
+}
+
+func (f *config) getenv(name string) string {
+	if !f.isEnvAllowed(name) {
+		return ""
+	}
+
+	return os.Getenv(name)
+}
+
+func (f *config) isEnvAllowed(name string) bool {
+	for _, pattern := range f.envAllowlist {
+		if matched, err := path.Match(pattern, name); (err == nil) && matched {
+			return true
+		}
+	}
+
+	return false
+}
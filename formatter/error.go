@@ -14,8 +14,148 @@
 
 package formatter
 
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sort"
+)
+
 type fError string
 
 func (f fError) Error() string {
 	return string(f)
 }
+
+// UndefinedFunctionError is returned when message uses function or
+// placeholder that is not defined. Suggestions hold defined names that are
+// close to it.
+type UndefinedFunctionError struct {
+	Name        string
+	Location    string
+	Suggestions []string
+}
+
+// Error returns error message.
+func (e *UndefinedFunctionError) Error() string {
+	return fmt.Sprintf("template: %s: function %q not defined", e.Location, e.Name) + didYouMean(e.Suggestions)
+}
+
+// ExecError is returned when panic occurred during formatting, for example
+// nil pointer dereference inside an argument method. It holds recovered
+// value and stack trace of the panic.
+type ExecError struct {
+	Value interface{}
+	Stack []byte
+}
+
+// Error returns error message.
+func (e *ExecError) Error() string {
+	return fmt.Sprintf("formatter: panic during execution: %v", e.Value)
+}
+
+// Unwrap returns recovered value if it is an error.
+func (e *ExecError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+
+	return nil
+}
+
+// recoverExecError converts recovered panic to ExecError.
+func recoverExecError(err *error) {
+	if value := recover(); value != nil {
+		*err = &ExecError{Value: value, Stack: debug.Stack()}
+	}
+}
+
+// wrapError is returned by Errorf for message with single error argument.
+type wrapError struct {
+	message string
+	err     error
+}
+
+func (e *wrapError) Error() string {
+	return e.message
+}
+
+func (e *wrapError) Unwrap() error {
+	return e.err
+}
+
+// wrapErrors is returned by Errorf for message with many error arguments.
+type wrapErrors struct {
+	message string
+	errs    []error
+}
+
+func (e *wrapErrors) Error() string {
+	return e.message
+}
+
+func (e *wrapErrors) Unwrap() []error {
+	return e.errs
+}
+
+// Errorf formats message like Format and returns it as error that wraps all
+// error arguments.
+func Errorf(message string, arguments ...interface{}) error {
+	return New().Errorf(message, arguments...)
+}
+
+// Errorf formats message like Format and returns it as error. Error
+// arguments, also provided with Arg, Args or as Named values, are wrapped like
+// with the %w verb of fmt.Errorf, so they can be retrieved using errors.Unwrap,
+// errors.Is and errors.As. Formatting error is returned as %!(ERROR=message)
+// in error message.
+func (f *Formatter) Errorf(message string, arguments ...interface{}) error {
+	formatted, err := f.Format(message, arguments...)
+
+	if err != nil {
+		formatted = "%!(ERROR=" + err.Error() + ")"
+	}
+
+	var errs []error
+
+	for _, argument := range arguments {
+		switch value := unwrapArgument(argument).(type) {
+		case error:
+			errs = append(errs, value)
+		case Named:
+			errs = append(errs, mapErrors(value)...)
+		case Args:
+			errs = append(errs, mapErrors(value)...)
+		}
+	}
+
+	switch len(errs) {
+	case 0:
+		return errors.New(formatted)
+	case 1:
+		return &wrapError{message: formatted, err: errs[0]}
+	default:
+		return &wrapErrors{message: formatted, errs: errs}
+	}
+}
+
+// mapErrors returns error values sorted by names.
+func mapErrors(values map[string]interface{}) []error {
+	names := make([]string, 0, len(values))
+
+	for name := range values {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var errs []error
+
+	for _, name := range names {
+		if err, ok := values[name].(error); ok {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,190 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// These constants define options of the errorChain function.
+const (
+	errorChainList  = "list"
+	errorChainStack = "stack"
+)
+
+// These constants define separators used by the errorChain function.
+const (
+	errorChainSeparator = ": "
+	errorListSeparator  = "; "
+	errorChainIndent    = "  "
+)
+
+// errorChain renders error with its unwrap chain like "a: b: c". Optional
+// options placed before error are list for indented multi-line list and stack
+// for stack traces of errors that carry them.
+func errorChain(arguments ...interface{}) (result string, err error) {
+	defer recoverExecError(&err)
+
+	if len(arguments) == 0 {
+		return "", fError("expected optional options and error")
+	}
+
+	list, stack := false, false
+
+	for _, argument := range arguments[:len(arguments)-1] {
+		switch argument {
+		case errorChainList:
+			list = true
+		case errorChainStack:
+			stack = true
+		default:
+			return "", fmt.Errorf("unknown errorChain option %v", argument)
+		}
+	}
+
+	value := evaluate(arguments[len(arguments)-1])
+
+	if value == nil {
+		return "", nil
+	}
+
+	chained, ok := value.(error)
+
+	if !ok {
+		return "", fmt.Errorf("errorChain requires an error, got %T", value)
+	}
+
+	if list {
+		var lines []string
+
+		errorList(chained, 0, stack, &lines)
+
+		return strings.Join(lines, "\n"), nil
+	}
+
+	result = flatErrorChain(chained)
+
+	if stack {
+		for cause := chained; cause != nil; cause = errors.Unwrap(cause) {
+			if trace := errorStack(cause); trace != "" {
+				result += "\n" + trace
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// renderError renders error chain in single line. Panic from error methods is
+// returned as ExecError.
+func renderError(err error) (result string, e error) {
+	defer recoverExecError(&e)
+
+	return flatErrorChain(err), nil
+}
+
+// flatErrorChain renders error chain in single line. Unwrapped errors are
+// rendered only if message of error ends with their messages, errors wrapping
+// many errors render them separated with semicolons.
+func flatErrorChain(err error) string {
+	message, causes := errorCauses(err)
+
+	switch {
+	case len(causes) == 1:
+		if cause := flatErrorChain(causes[0]); message == "" {
+			return cause
+		} else if message != err.Error() {
+			return message + errorChainSeparator + cause
+		}
+	case len(causes) > 1:
+		rendered := make([]string, 0, len(causes))
+
+		for _, cause := range causes {
+			rendered = append(rendered, flatErrorChain(cause))
+		}
+
+		if message == "" {
+			return strings.Join(rendered, errorListSeparator)
+		}
+
+		return message + errorChainSeparator + "[" + strings.Join(rendered, errorListSeparator) + "]"
+	}
+
+	return message
+}
+
+// errorList appends lines of error chain indented according to depth.
+func errorList(err error, depth int, stack bool, lines *[]string) {
+	message, causes := errorCauses(err)
+	indent := strings.Repeat(errorChainIndent, depth)
+
+	if message != "" {
+		*lines = append(*lines, indent+message)
+		depth++
+	}
+
+	if trace := errorStack(err); stack && (trace != "") {
+		for _, line := range strings.Split(trace, "\n") {
+			*lines = append(*lines, indent+errorChainIndent+line)
+		}
+	}
+
+	for _, cause := range causes {
+		errorList(cause, depth, stack, lines)
+	}
+}
+
+// errorCauses returns own message of error without messages of unwrapped
+// errors and unwrapped errors. Own message is empty if error only joins
+// unwrapped errors.
+func errorCauses(err error) (message string, causes []error) {
+	switch unwrapper := err.(type) {
+	case interface{ Unwrap() []error }:
+		causes = unwrapper.Unwrap()
+	case interface{ Unwrap() error }:
+		if cause := unwrapper.Unwrap(); cause != nil {
+			causes = []error{cause}
+		}
+	}
+
+	message = err.Error()
+
+	switch len(causes) {
+	case 0:
+		return message, nil
+	case 1:
+		if cause := causes[0].Error(); message == cause {
+			return "", causes
+		} else if strings.HasSuffix(message, errorChainSeparator+cause) {
+			return strings.TrimSuffix(message, errorChainSeparator+cause), causes
+		}
+
+		return message, causes
+	}
+
+	joined := make([]string, 0, len(causes))
+
+	for _, cause := range causes {
+		joined = append(joined, cause.Error())
+	}
+
+	if message == strings.Join(joined, "\n") {
+		message = ""
+	}
+
+	return message, causes
+}
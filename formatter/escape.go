@@ -0,0 +1,57 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// shellSafe defines characters that don't require quoting in shell words.
+const shellSafe = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789@%+=:,./_-"
+
+// xmlEscape escapes value for XML text and attribute values.
+func xmlEscape(value interface{}) string {
+	var buffer bytes.Buffer
+
+	_ = xml.EscapeText(&buffer, []byte(fmt.Sprint(evaluate(value))))
+
+	return buffer.String()
+}
+
+// urlQuery escapes value for URL query parameter names and values.
+func urlQuery(value interface{}) string {
+	return url.QueryEscape(fmt.Sprint(evaluate(value)))
+}
+
+// urlPath escapes value for a single URL path segment.
+func urlPath(value interface{}) string {
+	return url.PathEscape(fmt.Sprint(evaluate(value)))
+}
+
+// shellQuote quotes value as a single POSIX shell word. Values with only safe
+// characters are returned as they are.
+func shellQuote(value interface{}) string {
+	text := fmt.Sprint(evaluate(value))
+
+	if (text != "") && (strings.Trim(text, shellSafe) == "") {
+		return text
+	}
+
+	return "'" + strings.ReplaceAll(text, "'", `'\''`) + "'"
+}
@@ -0,0 +1,215 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Explanation describes how placeholders of message are resolved from
+// arguments.
+type Explanation struct {
+	// Output is formatted message.
+	Output string
+
+	// Placeholders holds resolution of every placeholder use in order of
+	// appearance.
+	Placeholders []Resolution
+
+	// Unused holds positions of arguments that are not used by message.
+	Unused []int
+}
+
+// Resolution describes which argument satisfied single placeholder use.
+type Resolution struct {
+	Reference
+
+	// Argument is position of argument that satisfied placeholder. It is -1
+	// if placeholder is not resolved from arguments.
+	Argument int
+
+	// Key is name or map key under which argument provided value. It is
+	// empty for automatic, positional and object placeholders.
+	Key string
+
+	// Value is resolved value.
+	Value interface{}
+}
+
+// Explain formats message and returns explanation of placeholder resolution.
+func Explain(message string, arguments ...interface{}) (*Explanation, error) {
+	return New().Explain(message, arguments...)
+}
+
+// Explain formats message and returns explanation which argument, position or
+// key, satisfied every placeholder, resolved values and unused arguments.
+func (f *Formatter) Explain(message string, arguments ...interface{}) (*Explanation, error) {
+	c, arguments := f.snapshot().withOptions(arguments)
+
+	buffer := getBuffer()
+	defer putBuffer(buffer)
+
+	if err := c.formatWriter(context.Background(), buffer, message, arguments...); err != nil {
+		return nil, err
+	}
+
+	output := buffer.String()
+	arguments = lazyArguments(c.hookArguments(c.redactArguments(arguments)))
+	names := make(map[string]bool)
+
+	for _, argument := range arguments {
+		for _, name := range argumentNames(argument) {
+			names[name] = true
+		}
+	}
+
+	references, err := c.references(message, names)
+
+	if err != nil {
+		return nil, err
+	}
+	explanation := &Explanation{Output: output}
+	used := make(map[int]bool)
+	automatic := 0
+
+	for _, reference := range references {
+		resolution := Resolution{Reference: reference, Argument: -1}
+
+		switch {
+		case reference.Automatic:
+			if automatic < len(arguments) {
+				resolution.Argument = automatic
+				resolution.Value = evaluate(unwrapArgument(arguments[automatic]))
+			}
+
+			automatic++
+		case (reference.Position >= 0) && (reference.Position < len(arguments)):
+			resolution.Argument = reference.Position
+			resolution.Value = evaluate(unwrapArgument(arguments[reference.Position]))
+		case strings.HasPrefix(reference.Name, "."):
+			if position, object := objectArgument(arguments); position >= 0 {
+				resolution.Argument = position
+				resolution.Value, _ = c.field(reference.Name, object, reference.Name[1:])
+			}
+		default:
+			resolution.Argument, resolution.Value = c.namedArgument(reference.Name, arguments)
+
+			if resolution.Argument >= 0 {
+				resolution.Key = reference.Name
+			}
+		}
+
+		if resolution.Argument >= 0 {
+			used[resolution.Argument] = true
+		}
+
+		explanation.Placeholders = append(explanation.Placeholders, resolution)
+	}
+
+	for position, argument := range arguments {
+		if !isArgumentUsed(used, position, argument) {
+			explanation.Unused = append(explanation.Unused, position)
+		}
+	}
+
+	return explanation, nil
+}
+
+// String returns explanation with one line per placeholder use followed by
+// unused arguments.
+func (e *Explanation) String() string {
+	lines := make([]string, 0, len(e.Placeholders)+1)
+
+	for _, resolution := range e.Placeholders {
+		line := "{" + resolution.Name + "} at " + resolution.Location
+
+		switch {
+		case resolution.Argument < 0:
+			line += " not resolved"
+		case resolution.Key != "":
+			line += fmt.Sprintf(" from argument %d key %q: %#v", resolution.Argument, resolution.Key, resolution.Value)
+		default:
+			line += fmt.Sprintf(" from argument %d: %#v", resolution.Argument, resolution.Value)
+		}
+
+		lines = append(lines, line)
+	}
+
+	if len(e.Unused) > 0 {
+		positions := make([]string, 0, len(e.Unused))
+
+		for _, position := range e.Unused {
+			positions = append(positions, strconv.Itoa(position))
+		}
+
+		lines = append(lines, "unused arguments: "+strings.Join(positions, ", "))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// namedArgument returns position and value of argument that defines name
+// according to conflict policy or -1.
+func (f *config) namedArgument(name string, arguments []interface{}) (position int, value interface{}) {
+	position = -1
+
+	for index, argument := range arguments {
+		found := false
+
+		for _, defined := range argumentNames(argument) {
+			found = found || (defined == name)
+		}
+
+		if !found || ((position >= 0) && (f.conflictPolicy == FirstWins)) {
+			continue
+		}
+
+		position = index
+
+		switch v := argument.(type) {
+		case Argument:
+			value = evaluate(v.Value)
+		default:
+			value = evaluate(reflect.ValueOf(argument).MapIndex(reflect.ValueOf(name).Convert(reflect.TypeOf(argument).Key())).Interface())
+		}
+	}
+
+	return position, value
+}
+
+// objectArgument returns position and value of the last struct or struct
+// pointer argument used as data object or -1.
+func objectArgument(arguments []interface{}) (position int, object interface{}) {
+	position = -1
+
+	for index, argument := range arguments {
+		switch argument.(type) {
+		case Argument, Args, error, *lazyValue:
+			continue
+		}
+
+		valueOf := reflect.ValueOf(argument)
+
+		if (valueOf.Kind() == reflect.Struct) || ((valueOf.Kind() == reflect.Ptr) && isObjectPointer(valueOf)) {
+			position, object = index, argument
+		}
+	}
+
+	return position, object
+}
@@ -0,0 +1,117 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"reflect"
+	"text/template"
+	"text/template/parse"
+)
+
+const (
+	fallbackFunction = "fallback"
+	coalesceFunction = "coalesce"
+)
+
+func fallback(value, argument interface{}) interface{} {
+	if isMissing(argument) {
+		return value
+	}
+
+	return argument
+}
+
+// coalesce returns the first value that is not missing, nil or empty string.
+// It returns nil if all values are missing.
+func coalesce(values ...interface{}) interface{} {
+	for _, value := range values {
+		if !isMissing(value) {
+			return value
+		}
+	}
+
+	return nil
+}
+
+func isMissing(argument interface{}) bool {
+	if argument == nil {
+		return true
+	}
+
+	valueOf := reflect.ValueOf(argument)
+
+	switch valueOf.Kind() {
+	case reflect.String:
+		return valueOf.Len() == 0
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return valueOf.IsNil()
+	default:
+		return false
+	}
+}
+
+// missingPlaceholders returns placeholders returning nil for all undefined
+// placeholders used together with the fallback or coalesce function like
+// {name | fallback "unknown"}, {fallback "unknown" name} or
+// {coalesce nickname name "unknown"}.
+func missingPlaceholders(trees map[string]*parse.Tree, functions []template.FuncMap) template.FuncMap {
+	missing := make(template.FuncMap)
+
+	add := func(node parse.Node) {
+		if chain, ok := node.(*parse.ChainNode); ok {
+			node = chain.Node
+		}
+
+		if identifier, ok := node.(*parse.IdentifierNode); ok && !isDefined(identifier.Ident, functions) {
+			missing[identifier.Ident] = missingPlaceholder
+		}
+	}
+
+	walkTrees(trees, func(_ *parse.Tree, node parse.Node) {
+		pipe, ok := node.(*parse.PipeNode)
+
+		if !ok || (pipe == nil) {
+			return
+		}
+
+		for position, command := range pipe.Cmds {
+			if !isFallback(command) {
+				continue
+			}
+
+			for _, argument := range command.Args[1:] {
+				add(argument)
+			}
+
+			if (position == 1) && (len(pipe.Cmds[0].Args) == 1) {
+				add(pipe.Cmds[0].Args[0])
+			}
+		}
+	})
+
+	return missing
+}
+
+func isFallback(command *parse.CommandNode) bool {
+	if identifier, ok := command.Args[0].(*parse.IdentifierNode); ok {
+		return (identifier.Ident == fallbackFunction) || (identifier.Ident == coalesceFunction)
+	}
+
+	return false
+}
+
+func missingPlaceholder() interface{} {
+	return nil
+}
@@ -0,0 +1,450 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template/parse"
+)
+
+const (
+	fieldFunction      = "_field"
+	fieldOrNilFunction = "_fieldOrNil"
+)
+
+// ErrMethodCallsDisabled is returned when message calls method of argument
+// and method calls are disabled.
+const ErrMethodCallsDisabled = fError("method calls are disabled")
+
+var (
+	gErrorType    = reflect.TypeOf((*error)(nil)).Elem() // nolint: gochecknoglobals
+	gStructFields sync.Map                               // nolint: gochecknoglobals
+)
+
+// structField holds cached index and redaction options of exported struct
+// field.
+type structField struct {
+	index  []int
+	redact bool
+	hash   bool
+}
+
+type structFieldKey struct {
+	t    reflect.Type
+	name string
+	tag  string
+	fold bool
+}
+
+// transformFields replaces field chains like {user.Address.City} and
+// {.Address.City} with calls to the field function that navigates maps,
+// structs and methods and reports missing path segments.
+func transformFields(trees map[string]*parse.Tree) {
+	walkTrees(trees, func(tree *parse.Tree, node parse.Node) {
+		pipe, ok := node.(*parse.PipeNode)
+
+		if !ok || (pipe == nil) {
+			return
+		}
+
+		for position, command := range pipe.Cmds {
+			lenient := isFallback(command) || ((position == 0) && (len(pipe.Cmds) > 1) &&
+				isFallback(pipe.Cmds[1]) && (len(command.Args) == 1))
+
+			for index, argument := range command.Args {
+				if (index == 0) && ((position != 0) || (len(command.Args) != 1)) {
+					continue
+				}
+
+				if replaced := fieldCall(tree, argument, lenient); replaced != nil {
+					command.Args[index] = replaced
+				}
+			}
+		}
+	})
+}
+
+// fieldCall returns field function call for field chain node or nil for
+// other nodes.
+func fieldCall(tree *parse.Tree, node parse.Node, lenient bool) parse.Node {
+	var root parse.Node
+
+	var names []string
+
+	switch n := node.(type) {
+	case *parse.FieldNode:
+		root, names = &parse.DotNode{NodeType: parse.NodeDot, Pos: n.Pos}, n.Ident
+	case *parse.ChainNode:
+		root, names = n.Node, n.Field
+	default:
+		return nil
+	}
+
+	function := fieldFunction
+
+	if lenient {
+		function = fieldOrNilFunction
+	}
+
+	arguments := []parse.Node{
+		parse.NewIdentifier(function).SetTree(tree).SetPos(node.Position()),
+		newString(node.Position(), node.String()),
+		root,
+	}
+
+	for _, name := range names {
+		arguments = append(arguments, newString(node.Position(), name))
+	}
+
+	return &parse.PipeNode{
+		NodeType: parse.NodePipe,
+		Pos:      node.Position(),
+		Cmds: []*parse.CommandNode{{
+			NodeType: parse.NodeCommand,
+			Pos:      node.Position(),
+			Args:     arguments,
+		}},
+	}
+}
+
+func newString(position parse.Pos, text string) *parse.StringNode {
+	return &parse.StringNode{NodeType: parse.NodeString, Pos: position, Quoted: strconv.Quote(text), Text: text}
+}
+
+// field returns value under provided path of field, method or map key names.
+// Like text/template it returns nil for nil values without an error. Panic
+// from called methods is returned as ExecError.
+func (f *config) field(path string, object interface{}, names ...string) (result interface{}, err error) {
+	defer recoverExecError(&err)
+
+	value := reflect.ValueOf(object)
+
+	for position, name := range names {
+		var err error
+
+		if !value.IsValid() {
+			return nil, nil
+		}
+
+		if value, err = f.fieldValue(value, name); err != nil {
+			return nil, fmt.Errorf("cannot evaluate %q in %q: %w", strings.Join(names[:position+1], "."), path, err)
+		}
+	}
+
+	if !value.IsValid() {
+		return nil, nil
+	}
+
+	return value.Interface(), nil
+}
+
+func (f *config) fieldOrNil(path string, object interface{}, names ...string) interface{} {
+	value, err := f.field(path, object, names...)
+
+	if err != nil {
+		return nil
+	}
+
+	return value
+}
+
+func (f *config) fieldValue(value reflect.Value, name string) (reflect.Value, error) {
+	if method, ok := f.methodByName(value, name); ok {
+		return callMethod(method)
+	}
+
+	for (value.Kind() == reflect.Ptr) || (value.Kind() == reflect.Interface) {
+		if value.IsNil() {
+			return value, fError("nil pointer")
+		}
+
+		value = value.Elem()
+
+		if method, ok := f.methodByName(value, name); ok {
+			return callMethod(method)
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		if structField := f.lookupField(value.Type(), name); structField != nil {
+			if structField.redact {
+				return reflect.ValueOf(redactedField(value.FieldByIndex(structField.index), structField.hash)), nil
+			}
+
+			return value.FieldByIndex(structField.index), nil
+		}
+
+		if f.methodsDisabled() && hasMethod(value, name) {
+			return value, fmt.Errorf("%w: %s.%s", ErrMethodCallsDisabled, value.Type(), name)
+		}
+
+		return value, fmt.Errorf("field %q not found in type %s%s", name, value.Type(),
+			didYouMean(suggestions(name, memberNames(value))))
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return value, fmt.Errorf("map key type %s is not a string", value.Type().Key())
+		}
+
+		if element := value.MapIndex(reflect.ValueOf(name).Convert(value.Type().Key())); element.IsValid() {
+			return element, nil
+		}
+
+		if key, ok := f.foldedKey(value, name); ok {
+			return value.MapIndex(key), nil
+		}
+
+		return value, fmt.Errorf("key %q not found in map%s", name, didYouMean(suggestions(name, mapNames(value))))
+	default:
+		return value, fmt.Errorf("type %s has no fields", value.Type())
+	}
+}
+
+// lookupField returns exported struct field with provided name or nil if
+// there is no such field. Field with name set by name tag is used when there
+// is no field with provided Go name. With case-insensitive resolution field
+// name matching regardless of case is used as the last resort. Lookups are
+// cached per struct type, name, name tag and case sensitivity, so formatting
+// the same types again does not search fields by name.
+func (f *config) lookupField(t reflect.Type, name string) *structField {
+	key := structFieldKey{t: t, name: name, tag: f.nameTag, fold: f.caseInsensitive}
+
+	if cached, ok := gStructFields.Load(key); ok {
+		f.reportCache(FieldCache, true)
+		return cached.(*structField)
+	}
+
+	f.reportCache(FieldCache, false)
+
+	var result *structField
+
+	if field, ok := t.FieldByName(name); ok && (field.PkgPath == "") {
+		redact, hash := tagOptions(field)
+		result = &structField{index: field.Index, redact: redact, hash: hash}
+	} else if field, ok := f.taggedField(t, name); ok {
+		redact, hash := tagOptions(field)
+		result = &structField{index: field.Index, redact: redact, hash: hash}
+	} else if field, ok := f.foldedField(t, name); ok {
+		redact, hash := tagOptions(field)
+		result = &structField{index: field.Index, redact: redact, hash: hash}
+	}
+
+	gStructFields.Store(key, result)
+
+	return result
+}
+
+// taggedField returns exported struct field with name set by name tag.
+func (f *config) taggedField(t reflect.Type, name string) (reflect.StructField, bool) {
+	if f.nameTag == "" {
+		return reflect.StructField{}, false
+	}
+
+	for _, field := range reflect.VisibleFields(t) {
+		if field.IsExported() && !field.Anonymous && (tagFieldName(field, f.nameTag) == name) {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// taggedNames returns names set by name tag of exported fields of struct
+// value mapped to Go field names.
+func (f *config) taggedNames(value reflect.Value) map[string]string {
+	names := make(map[string]string)
+
+	for _, field := range reflect.VisibleFields(value.Type()) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+
+		if name := tagFieldName(field, f.nameTag); name != "" {
+			names[name] = field.Name
+		}
+	}
+
+	return names
+}
+
+// defineTagged defines placeholders named by name tag for fields of struct
+// value.
+func (f *config) defineTagged(value reflect.Value, position int, define func(name string, position int, value interface{})) {
+	if f.nameTag == "" {
+		return
+	}
+
+	for name, field := range f.taggedNames(value) {
+		if fieldValue, err := f.fieldValue(value, field); err == nil {
+			define(name, position, fieldValue.Interface())
+		}
+	}
+}
+
+// SetNameTag sets struct tag like json used to resolve struct fields by
+// names set in tags, so {user_id} matches field UserID tagged with
+// json:"user_id". Tag names of fields of object arguments are also defined as
+// named placeholders. Go field names take precedence. With format tag name is
+// the first tag element like format:"user_id,redact". Empty tag disables it.
+// Default is empty.
+func (f *Formatter) SetNameTag(tag string) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.nameTag = tag
+
+	return f
+}
+
+// GetNameTag returns struct tag used to resolve struct fields by names set in
+// tags.
+func (f *Formatter) GetNameTag() string {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.nameTag
+}
+
+// methodByName returns method with provided name. Method name matching
+// regardless of case is used with case-insensitive resolution. Methods are not
+// returned when method calls are disabled.
+func (f *config) methodByName(value reflect.Value, name string) (reflect.Value, bool) {
+	if f.methodsDisabled() {
+		return reflect.Value{}, false
+	}
+
+	if (value.Kind() != reflect.Interface) && (value.Kind() != reflect.Ptr) && value.CanAddr() {
+		if method := value.Addr().MethodByName(name); method.IsValid() {
+			return method, true
+		}
+	}
+
+	method := value.MethodByName(name)
+
+	if !method.IsValid() && f.caseInsensitive {
+		if folded, ok := foldedMethod(value, name); ok && (folded != name) {
+			return f.methodByName(value, folded)
+		}
+	}
+
+	return method, method.IsValid()
+}
+
+func callMethod(method reflect.Value) (reflect.Value, error) {
+	methodType := method.Type()
+
+	switch {
+	case methodType.NumIn() != 0:
+		return method, fError("method requires arguments")
+	case methodType.NumOut() == 1:
+		return method.Call(nil)[0], nil
+	case (methodType.NumOut() == 2) && (methodType.Out(1) == gErrorType):
+		results := method.Call(nil)
+
+		if err, ok := results[1].Interface().(error); ok && (err != nil) {
+			return results[0], err
+		}
+
+		return results[0], nil
+	default:
+		return method, fError("method must return a value and an optional error")
+	}
+}
+
+// SetMethodCalls enables or disables calling exported methods of arguments
+// like {user.DisplayName} or {user.Greet "Hi"}. Methods without arguments
+// are called like fields and methods with arguments are called with
+// template-style arguments. Disabled method calls are reported as
+// ErrMethodCallsDisabled. Default is true.
+func (f *Formatter) SetMethodCalls(enabled bool) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.noMethods = !enabled
+
+	return f
+}
+
+// GetMethodCalls returns true if calling methods of arguments is enabled.
+// Methods are never called in sandbox.
+func (f *Formatter) GetMethodCalls() bool {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return !f.methodsDisabled()
+}
+
+func (f *config) methodsDisabled() bool {
+	return f.noMethods || (f.sandbox != nil)
+}
+
+// checkMethodCalls returns ErrMethodCallsDisabled for method calls with
+// arguments when method calls are disabled. Variables with fields like
+// $user.Name are replaced with calls to the field function that doesn't call
+// methods.
+func (f *config) checkMethodCalls(trees map[string]*parse.Tree) error {
+	if !f.methodsDisabled() {
+		return nil
+	}
+
+	var err error
+
+	walkTrees(trees, func(tree *parse.Tree, node parse.Node) {
+		command, ok := node.(*parse.CommandNode)
+
+		if !ok || (err != nil) {
+			return
+		}
+
+		for index, argument := range command.Args {
+			switch n := argument.(type) {
+			case *parse.FieldNode, *parse.ChainNode:
+				if (index == 0) && (len(command.Args) > 1) {
+					err = fmt.Errorf("%w: %s", ErrMethodCallsDisabled, n)
+					return
+				}
+			case *parse.VariableNode:
+				if len(n.Ident) < 2 {
+					continue
+				}
+
+				if (index == 0) && (len(command.Args) > 1) {
+					err = fmt.Errorf("%w: %s", ErrMethodCallsDisabled, n)
+					return
+				}
+
+				variable := &parse.VariableNode{NodeType: parse.NodeVariable, Pos: n.Pos, Ident: n.Ident[:1]}
+				chain := &parse.ChainNode{NodeType: parse.NodeChain, Pos: n.Pos, Node: variable, Field: n.Ident[1:]}
+				command.Args[index] = fieldCall(tree, chain, false)
+			}
+		}
+	})
+
+	return err
+}
+
+// hasMethod returns true if value or pointer to it has method with provided
+// name.
+func hasMethod(value reflect.Value, name string) bool {
+	if value.MethodByName(name).IsValid() {
+		return true
+	}
+
+	return value.CanAddr() && value.Addr().MethodByName(name).IsValid()
+}
@@ -0,0 +1,76 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"encoding"
+	"fmt"
+	"strings"
+)
+
+// goStringVerb is the fmt verb that uses fmt.GoStringer.
+const goStringVerb = "%#v"
+
+// SetFmtVerb sets fmt verb like "%+v" or "%#v" used to render values
+// implementing fmt.Formatter or fmt.GoStringer. When it is set values
+// implementing encoding.TextMarshaler are rendered with MarshalText. Empty
+// verb restores default rendering.
+func (f *Formatter) SetFmtVerb(verb string) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if (verb != "") && !strings.HasPrefix(verb, "%") {
+		verb = "%" + verb
+	}
+
+	f.fmtVerb = verb
+
+	return f
+}
+
+// GetFmtVerb returns fmt verb used to render values implementing
+// fmt.Formatter or fmt.GoStringer.
+func (f *Formatter) GetFmtVerb() string {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.fmtVerb
+}
+
+// renderFmt returns value rendered with fmt.Formatter, fmt.GoStringer or
+// encoding.TextMarshaler. It returns false if fmt verb is not set or value
+// doesn't implement any of them.
+func (f *config) renderFmt(value interface{}) (string, bool, error) {
+	if f.fmtVerb == "" {
+		return "", false, nil
+	}
+
+	switch v := value.(type) {
+	case fmt.Formatter:
+		return fmt.Sprintf(f.fmtVerb, v), true, nil
+	case fmt.GoStringer:
+		if f.fmtVerb == goStringVerb {
+			return v.GoString(), true, nil
+		}
+	}
+
+	if marshaler, ok := value.(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+
+		return string(text), true, err
+	}
+
+	return "", false, nil
+}
@@ -0,0 +1,191 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// foldPrefix starts names of placeholder aliases used by case-insensitive
+// resolution.
+const foldPrefix = "_fold_"
+
+// SetCaseInsensitive enables or disables case-insensitive resolution of named
+// placeholders, struct fields, methods and map keys, so {UserName},
+// {username} and {USERNAME} resolve to the same argument. Exact matches take
+// precedence. When more names differ only in case, the first one in sorted
+// order is used. Default is false.
+func (f *Formatter) SetCaseInsensitive(enabled bool) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.caseInsensitive = enabled
+
+	return f
+}
+
+// GetCaseInsensitive returns true if case-insensitive resolution of
+// placeholders is enabled.
+func (f *Formatter) GetCaseInsensitive() bool {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.caseInsensitive
+}
+
+// foldPlaceholders adds aliases of placeholders that are used to resolve them
+// regardless of case.
+func (f *config) foldPlaceholders(placeholders template.FuncMap) {
+	if !f.caseInsensitive {
+		return
+	}
+
+	names := make([]string, 0, len(placeholders))
+
+	for name := range placeholders {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if alias := foldName(name); placeholders[alias] == nil {
+			placeholders[alias] = placeholders[name]
+		}
+	}
+}
+
+// foldedName returns alias of placeholder that matches name regardless of case.
+func (f *config) foldedName(name string, functions []template.FuncMap) (string, bool) {
+	if !f.caseInsensitive || isDefined(name, functions) {
+		return "", false
+	}
+
+	alias := foldName(name)
+
+	return alias, isDefined(alias, functions)
+}
+
+// foldFunctions returns function map that defines identifiers that aren't
+// defined as placeholders that match them regardless of case.
+func (f *config) foldFunctions(trees map[string]*parse.Tree, functions []template.FuncMap) template.FuncMap {
+	folded := make(template.FuncMap)
+
+	if !f.caseInsensitive {
+		return folded
+	}
+
+	walkTrees(trees, func(_ *parse.Tree, node parse.Node) {
+		identifier, ok := node.(*parse.IdentifierNode)
+
+		if !ok {
+			return
+		}
+
+		if alias, ok := f.foldedName(identifier.Ident, functions); ok {
+			folded[identifier.Ident] = lookupFunction(alias, functions)
+		}
+	})
+
+	return folded
+}
+
+// lookupFunction returns function with provided name defined in the last
+// function map.
+func lookupFunction(name string, functions []template.FuncMap) interface{} {
+	for index := len(functions) - 1; index >= 0; index-- {
+		if function, ok := functions[index][name]; ok {
+			return function
+		}
+	}
+
+	return nil
+}
+
+// foldedField returns exported struct field with Go name or name set by name
+// tag that matches name regardless of case.
+func (f *config) foldedField(t reflect.Type, name string) (reflect.StructField, bool) {
+	if !f.caseInsensitive {
+		return reflect.StructField{}, false
+	}
+
+	var matched []reflect.StructField
+
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+
+		if strings.EqualFold(field.Name, name) || ((f.nameTag != "") && strings.EqualFold(tagFieldName(field, f.nameTag), name)) {
+			matched = append(matched, field)
+		}
+	}
+
+	if len(matched) == 0 {
+		return reflect.StructField{}, false
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].Name < matched[j].Name
+	})
+
+	return matched[0], true
+}
+
+// foldedKey returns map key that matches name regardless of case.
+func (f *config) foldedKey(value reflect.Value, name string) (reflect.Value, bool) {
+	if !f.caseInsensitive {
+		return reflect.Value{}, false
+	}
+
+	keys := mapNames(value)
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if strings.EqualFold(key, name) {
+			return reflect.ValueOf(key).Convert(value.Type().Key()), true
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+// foldedMethod returns name of method of value or pointer to it that matches
+// name regardless of case.
+func foldedMethod(value reflect.Value, name string) (string, bool) {
+	types := []reflect.Type{value.Type()}
+
+	if (value.Kind() != reflect.Interface) && (value.Kind() != reflect.Ptr) && value.CanAddr() {
+		types = append(types, reflect.PtrTo(value.Type()))
+	}
+
+	for _, t := range types {
+		for index := 0; index < t.NumMethod(); index++ {
+			if method := t.Method(index); strings.EqualFold(method.Name, name) {
+				return method.Name, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+func foldName(name string) string {
+	return foldPrefix + strings.ToLower(name)
+}
@@ -0,0 +1,100 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"fmt"
+	"reflect"
+	"text/template/parse"
+)
+
+const formatValueFunction = "_formatValue"
+
+// Formattable is implemented by values that control their own formatting like
+// money or temperature. It takes precedence over default rendering. Spec is
+// empty when value is rendered directly like {price} and it is provided with
+// the format function like {price | format "short"}.
+type Formattable interface {
+	FormatValue(spec string) (string, error)
+}
+
+// transformFormattable appends call of the format value function to every
+// action that prints value.
+func transformFormattable(trees map[string]*parse.Tree) {
+	walkTrees(trees, func(tree *parse.Tree, node parse.Node) {
+		if action, ok := node.(*parse.ActionNode); ok && (len(action.Pipe.Decl) == 0) {
+			action.Pipe.Cmds = append(action.Pipe.Cmds, &parse.CommandNode{
+				NodeType: parse.NodeCommand,
+				Pos:      action.Pos,
+				Args:     []parse.Node{parse.NewIdentifier(formatValueFunction).SetTree(tree).SetPos(action.Pos)},
+			})
+		}
+	})
+}
+
+// formatValue returns value rendered with FormatValue if it implements
+// Formattable or value with redacted values. Nil and zero values are rendered
+// with nil text and zero renderers, booleans with boolean strings, values
+// implementing fmt interfaces with fmt verb, Stringer values as underlying
+// values if String method is disabled and errors with their unwrap chains in
+// single line.
+func (f *config) formatValue(value interface{}) (interface{}, error) {
+	value = f.redactType(value)
+
+	if text, ok := f.renderZero(value); ok {
+		return text, nil
+	}
+
+	if text, ok := f.renderBool(value); ok {
+		return text, nil
+	}
+
+	if formattable, ok := value.(Formattable); ok {
+		return formattable.FormatValue("")
+	}
+
+	if text, ok, err := f.renderFmt(value); ok {
+		return text, err
+	}
+
+	if underlying, ok := f.renderStringer(value); ok {
+		return underlying, nil
+	}
+
+	if err, ok := value.(error); ok && !isNil(reflect.ValueOf(value)) {
+		return renderError(err)
+	}
+
+	return f.redact(value), nil
+}
+
+// formatSpec is the format function registered in built-in functions. It is
+// replaced by formatter specific function during formatting.
+func formatSpec(spec string, value interface{}) (string, error) {
+	return (&config{}).formatSpec(spec, value)
+}
+
+// formatSpec returns value rendered with FormatValue using provided spec.
+func (f *config) formatSpec(spec string, value interface{}) (string, error) {
+	if value = f.redactType(value); value == RedactedText {
+		return RedactedText, nil
+	}
+
+	if formattable, ok := value.(Formattable); ok {
+		return formattable.FormatValue(spec)
+	}
+
+	return "", fmt.Errorf("type %T doesn't implement Formattable", value)
+}
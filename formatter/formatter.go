@@ -16,20 +16,34 @@ package formatter
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"reflect"
 	"strconv"
+	"strings"
+	"sync"
 	"text/template"
+	"time"
 )
 
 // These constants define default values used by formatter.
 const (
-	DefaultPlaceholder    = "p"
-	DefaultLeftDelimiter  = "{"
-	DefaultRightDelimiter = "}"
+	DefaultPlaceholder     = "p"
+	DefaultLeftDelimiter   = "{"
+	DefaultRightDelimiter  = "}"
+	DefaultUnusedPrefix    = " "
+	DefaultUnusedSeparator = " "
 )
 
+const maxPooledBufferSize = 64 << 10
+
+var gBuffers = sync.Pool{ // nolint: gochecknoglobals
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
 // Named defines named arguments.
 type Named map[string]interface{}
 
@@ -37,22 +51,85 @@ type Named map[string]interface{}
 type Functions map[string]interface{}
 
 // Formatter defines a formatter object that formats string using
-// “replacement fields” surrounded by curly braces {}.
+// “replacement fields” surrounded by curly braces {}. Formatter is safe for
+// concurrent use. Configuration changes don't affect formatting calls that
+// are already in progress.
 type Formatter struct {
-	placeholder    string
-	leftDelimiter  string
-	rightDelimiter string
-	functions      Functions
-}
-
-// New creates a new formatter object.
-func New() *Formatter {
-	return &Formatter{
-		placeholder:    DefaultPlaceholder,
-		leftDelimiter:  DefaultLeftDelimiter,
-		rightDelimiter: DefaultRightDelimiter,
-		functions:      Functions{},
+	mutex sync.RWMutex
+	config
+}
+
+// config defines formatter configuration. Functions map is never modified in
+// place, it is replaced with a modified copy instead. Thanks to that a copy of
+// configuration can be used without holding a lock.
+type config struct {
+	placeholder        string
+	placeholderStyle   PlaceholderStyle
+	leftDelimiter      string
+	rightDelimiter     string
+	addedDelimiters    []Delimiters
+	locale             string
+	colorMode          ColorMode
+	safeHTML           bool
+	safeMarkdown       bool
+	strict             bool
+	strictPlaceholders bool
+	unusedPrefix       string
+	unusedSeparator    string
+	conflictPolicy     ConflictPolicy
+	executionTimeout   time.Duration
+	maxOutputSize      int
+	maxDepth           int
+	pseudoLocalization bool
+	argumentHook       ArgumentHook
+	outputHook         OutputHook
+	metrics            MetricsSink
+	tracer             Tracer
+	engine             Engine
+	writeBufferSize    int
+	batchWorkers       int
+	redacted           map[reflect.Type]bool
+	missingHandler     MissingHandler
+	resolvers          []Resolver
+	envAllowlist       []string
+	templates          Templates
+	overrides          map[string]string
+	trimWhitespace     bool
+	tableOptions       TableOptions
+	widthAlgorithm     WidthAlgorithm
+	dumpOptions        DumpOptions
+	stackOptions       StackOptions
+	nilText            string
+	fmtVerb            string
+	noStringer         bool
+	trueText           string
+	falseText          string
+	zeroRenderers      map[reflect.Type]ZeroRenderer
+	functions          Functions
+	functionSets       map[string]Functions
+	functionPolicy     FunctionPolicy
+	functionConflicts  []string
+	sandbox            *Sandbox
+	noMethods          bool
+	nameTag            string
+	caseInsensitive    bool
+	compiled           *compiledMessage
+}
+
+// NewHTML creates a new formatter object with enabled HTML-safe mode.
+func NewHTML() *Formatter {
+	return New().SetSafeHTML(true)
+}
+
+// New creates a new formatter object configured with provided options.
+func New(options ...Option) *Formatter {
+	f := &Formatter{config: defaultConfig()}
+
+	for _, option := range options {
+		option(f)
 	}
+
+	return f
 }
 
 // Format formats string.
@@ -73,9 +150,14 @@ func FormatWriter(writer io.Writer, message string, arguments ...interface{}) er
 
 // Format formats string.
 func (f *Formatter) Format(message string, arguments ...interface{}) (string, error) {
-	var buffer bytes.Buffer
+	if (len(arguments) == 0) && f.isPlain(message) {
+		return message, nil
+	}
 
-	if err := f.FormatWriter(&buffer, message, arguments...); err != nil {
+	buffer := getBuffer()
+	defer putBuffer(buffer)
+
+	if err := f.FormatWriter(buffer, message, arguments...); err != nil {
 		return "", err
 	}
 
@@ -94,197 +176,658 @@ func (f *Formatter) MustFormat(message string, arguments ...interface{}) string
 	return formatted
 }
 
+// Clone returns a copy of formatter with a copy of its template functions.
+// Changes made to a clone don't affect the original formatter.
+func (f *Formatter) Clone() *Formatter {
+	c := f.snapshot()
+	c.functions = copyFunctions(c.functions)
+
+	return &Formatter{config: *c}
+}
+
 // Reset resets formatter to default state.
 func (f *Formatter) Reset() *Formatter {
-	*f = *New()
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.config = defaultConfig()
 
 	return f
 }
 
-// SetFunctions sets template functions used by formatter.
+// SetFunctions sets template functions used by formatter. Provided map is
+// copied. Collisions recorded for FunctionError policy are cleared.
 func (f *Formatter) SetFunctions(functions Functions) *Formatter {
-	f.functions = functions
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.functions = copyFunctions(functions)
+	f.functionConflicts = nil
+
 	return f
 }
 
 // GetFunction returns template function used by formatter.
 func (f *Formatter) GetFunction(name string) interface{} {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
 	return f.functions[name]
 }
 
-// GetFunctions returns template functions used by formatter.
+// GetFunctions returns a copy of template functions used by formatter.
 func (f *Formatter) GetFunctions() Functions {
-	return f.functions
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return copyFunctions(f.functions)
 }
 
 // AddFunction adds template function used by formatter.
 func (f *Formatter) AddFunction(name string, function interface{}) *Formatter {
-	f.functions[name] = function
-	return f
+	return f.AddFunctions(Functions{name: function})
 }
 
-// AddFunctions adds template functions used by formatter.
+// AddFunctions adds template functions used by formatter. Functions that
+// collide with built-in functions or functions added before are handled
+// according to function policy.
 func (f *Formatter) AddFunctions(functions Functions) *Formatter {
-	for name, function := range functions {
-		f.functions[name] = function
-	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.addFunctions(functions)
 
 	return f
 }
 
 // RemoveFunction removes template function used by formatter.
 func (f *Formatter) RemoveFunction(name string) *Formatter {
-	if _, ok := f.functions[name]; !ok {
-		return f
-	}
-
-	delete(f.functions, name)
-
-	return f
+	return f.RemoveFunctions([]string{name})
 }
 
-// RemoveFunctions removes template functions used by formatter.
+// RemoveFunctions removes template functions used by formatter. Collisions
+// recorded for removed names are forgotten.
 func (f *Formatter) RemoveFunctions(names []string) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.functions = copyFunctions(f.functions)
+
 	for _, name := range names {
-		f.RemoveFunction(name)
+		delete(f.functions, name)
 	}
 
+	f.removeFunctionConflicts(names)
+
 	return f
 }
 
-// ResetFunctions resets template functions used by formatter.
+// ResetFunctions resets template functions used by formatter to functions
+// registered with RegisterGlobal.
 func (f *Formatter) ResetFunctions() *Formatter {
-	f.functions = Functions{}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.functions = GlobalFunctions()
+	f.functionConflicts = nil
+
 	return f
 }
 
 // SetPlaceholder sets placeholder string prefix used for automatic and
 // positional placeholders to format string. Default is p.
 func (f *Formatter) SetPlaceholder(placeholder string) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
 	f.placeholder = placeholder
+
 	return f
 }
 
 // GetPlaceholder returns placeholder string prefix used for automatic and
 // positional placeholders to format string. Default is p.
 func (f *Formatter) GetPlaceholder() string {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
 	return f.placeholder
 }
 
 // ResetPlaceholder resets placeholder to default value.
 func (f *Formatter) ResetPlaceholder() *Formatter {
-	f.placeholder = DefaultPlaceholder
-	return f
+	return f.SetPlaceholder(DefaultPlaceholder)
 }
 
 // SetDelimiters sets delimiters used by formatter. Default is {}.
 func (f *Formatter) SetDelimiters(left, right string) *Formatter {
-	return f.SetLeftDelimiter(left).SetRightDelimiter(right)
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.leftDelimiter, f.rightDelimiter = left, right
+
+	return f
 }
 
 // SetLeftDelimiter sets left delimiter used by formatter. Default is {.
 func (f *Formatter) SetLeftDelimiter(delimiter string) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
 	f.leftDelimiter = delimiter
+
 	return f
 }
 
 // SetRightDelimiter sets right delimiter used by formatter. Default is }.
 func (f *Formatter) SetRightDelimiter(delimiter string) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
 	f.rightDelimiter = delimiter
+
 	return f
 }
 
 // GetDelimiters returns delimiters used by formatter. Default is {}.
 func (f *Formatter) GetDelimiters() (left, right string) {
-	return f.GetLeftDelimiter(), f.GetRightDelimiter()
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.leftDelimiter, f.rightDelimiter
 }
 
 // GetLeftDelimiter returns left delimiter used by formatter. Default is {.
 func (f *Formatter) GetLeftDelimiter() string {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
 	return f.leftDelimiter
 }
 
 // GetRightDelimiter returns right delimiter used by formatter. Default is }.
 func (f *Formatter) GetRightDelimiter() string {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
 	return f.rightDelimiter
 }
 
 // ResetDelimiters resets delimiters used by formatter to default values.
 func (f *Formatter) ResetDelimiters() *Formatter {
-	return f.ResetLeftDelimiter().ResetRightDelimiter()
+	return f.SetDelimiters(DefaultLeftDelimiter, DefaultRightDelimiter)
 }
 
 // ResetLeftDelimiter resets left delimiter used by formatter to default value.
 func (f *Formatter) ResetLeftDelimiter() *Formatter {
-	f.leftDelimiter = DefaultLeftDelimiter
-	return f
+	return f.SetLeftDelimiter(DefaultLeftDelimiter)
 }
 
 // ResetRightDelimiter resets right delimiter used by formatter to default value.
 func (f *Formatter) ResetRightDelimiter() *Formatter {
-	f.rightDelimiter = DefaultRightDelimiter
+	return f.SetRightDelimiter(DefaultRightDelimiter)
+}
+
+// SetLocale sets locale used by built-in functions like date. Default is en.
+func (f *Formatter) SetLocale(locale string) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.locale = locale
+
 	return f
 }
 
+// GetLocale returns locale used by built-in functions like date. Default is en.
+func (f *Formatter) GetLocale() string {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.locale
+}
+
+// ResetLocale resets locale used by built-in functions to default value.
+func (f *Formatter) ResetLocale() *Formatter {
+	return f.SetLocale(DefaultLocale)
+}
+
+// SetColorMode sets color mode used by built-in text and color functions.
+// Default is ColorAlways.
+func (f *Formatter) SetColorMode(mode ColorMode) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.colorMode = mode
+
+	return f
+}
+
+// GetColorMode returns color mode used by built-in text and color functions.
+// Default is ColorAlways.
+func (f *Formatter) GetColorMode() ColorMode {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.colorMode
+}
+
+// ResetColorMode resets color mode to default value.
+func (f *Formatter) ResetColorMode() *Formatter {
+	return f.SetColorMode(DefaultColorMode)
+}
+
+// SetSafeHTML enables or disables HTML-safe mode. In HTML-safe mode formatter
+// uses the html/template package and all arguments are contextually escaped.
+func (f *Formatter) SetSafeHTML(enabled bool) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.safeHTML = enabled
+
+	return f
+}
+
+// IsSafeHTML returns true if HTML-safe mode is enabled.
+func (f *Formatter) IsSafeHTML() bool {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.safeHTML
+}
+
+// SetStrict enables or disables strict mode. In strict mode unused arguments
+// are reported as an error instead of being appended to formatted string.
+func (f *Formatter) SetStrict(enabled bool) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.strict = enabled
+
+	return f
+}
+
+// IsStrict returns true if strict mode is enabled.
+func (f *Formatter) IsStrict() bool {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.strict
+}
+
+// SetUnusedPrefix sets text written before appended unused arguments like
+// " | extra: ". Default is a single space.
+func (f *Formatter) SetUnusedPrefix(prefix string) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.unusedPrefix = prefix
+
+	return f
+}
+
+// GetUnusedPrefix returns text written before appended unused arguments.
+func (f *Formatter) GetUnusedPrefix() string {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.unusedPrefix
+}
+
+// SetUnusedSeparator sets text written between appended unused arguments like
+// ", ". Default is a single space.
+func (f *Formatter) SetUnusedSeparator(separator string) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.unusedSeparator = separator
+
+	return f
+}
+
+// GetUnusedSeparator returns text written between appended unused arguments.
+func (f *Formatter) GetUnusedSeparator() string {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.unusedSeparator
+}
+
 // FormatWriter formats string to writer.
 func (f *Formatter) FormatWriter(writer io.Writer, message string, arguments ...interface{}) error {
-	var object interface{}
+	c, arguments := f.snapshot().withOptions(arguments)
+
+	return c.formatWriter(context.Background(), writer, message, arguments...)
+}
+
+// snapshot returns a copy of current configuration that can be used without
+// holding a lock.
+func (f *Formatter) snapshot() *config {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	c := f.config
+
+	return &c
+}
+
+// isPlain returns true if message doesn't contain replacement fields and
+// escaped delimiters so it can be written as it is without parsing. It is
+// never true in HTML-safe mode because the html/template package validates
+// the whole message, in pseudo-localization mode and when message exceeds
+// maximum output size.
+func (f *Formatter) isPlain(message string) bool {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.config.isPlain(message)
+}
+
+func (f *config) formatWriter(ctx context.Context, writer io.Writer, message string, arguments ...interface{}) error {
+	f = f.withColorWriter(writer)
+
+	return f.buffered(writer, func(writer io.Writer) error {
+		return f.measure(writer, func(writer io.Writer) error {
+			return f.formatMessage(ctx, writer, message, arguments...)
+		})
+	})
+}
+
+func (f *config) formatMessage(ctx context.Context, writer io.Writer, message string, arguments ...interface{}) error {
+	message = f.translateMessage(message)
+
+	if f.outputHook != nil {
+		return f.hookOutput(ctx, writer, message, arguments)
+	}
+
+	writer = f.limitWriter(writer)
+	arguments = lazyArguments(f.hookArguments(f.redactArguments(arguments)))
+
+	if f.isPlain(message) {
+		if err := write(writer, message); err != nil {
+			return err
+		}
+
+		return f.writeUnused(writer, map[int]bool{}, arguments)
+	}
+
+	if err := f.checkConflicts(arguments); err != nil {
+		return err
+	}
 
 	used := make(map[int]bool)
-	placeholders := make(template.FuncMap)
+	placeholders, object := f.placeholders(message, used, arguments)
+
+	if err := f.checkFunctionConflicts(placeholders); err != nil {
+		return err
+	}
+
+	key := TemplateKey(message)
 
+	if segments, ok := f.simpleSegments(message, placeholders); ok {
+		if err := f.executeSimple(ctx, key, writer, segments, placeholders); err != nil {
+			return err
+		}
+
+		return f.writeUnused(writer, used, arguments)
+	}
+
+	trees, functions, err := f.parseTraced(ctx, key, message, f.functionMaps(writer, placeholders))
+
+	if err != nil {
+		return err
+	}
+
+	if err := f.executeTraced(ctx, key, writer, trees, functions, object); err != nil {
+		return err
+	}
+
+	return f.writeUnused(writer, used, arguments)
+}
+
+func (f *config) isPlain(message string) bool {
+	switch {
+	case f.safeHTML, f.safeMarkdown, f.pseudoLocalization, (f.outputHook != nil), (f.metrics != nil), (len(f.overrides) > 0),
+		(f.leftDelimiter == ""), (f.rightDelimiter == ""):
+		return false
+	case (f.maxOutputSize > 0) && (len(message) > f.maxOutputSize), f.hasAddedDelimiters(message):
+		return false
+	}
+
+	return !strings.Contains(message, f.leftDelimiter) && !strings.Contains(message, f.rightDelimiter+f.rightDelimiter)
+}
+
+// writeUnused writes unused arguments after unused prefix separated by unused
+// separator. Maps are rendered with sorted keys, so output is deterministic.
+// In strict mode unused arguments are reported as an error.
+func (f *config) writeUnused(writer io.Writer, used map[int]bool, arguments []interface{}) error {
+	if len(used) >= len(arguments) {
+		return nil
+	}
+
+	if f.strict {
+		return unusedArgumentsError(used, arguments)
+	}
+
+	var values []string
+
+	for position, argument := range arguments {
+		if !isArgumentUsed(used, position, argument) {
+			value, err := f.formatValue(evaluate(argument))
+
+			if err != nil {
+				return err
+			}
+
+			values = append(values, f.escape(fmt.Sprint(value)))
+		}
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	return write(writer, f.unusedPrefix+strings.Join(values, f.unusedSeparator))
+}
+
+// placeholders returns placeholder functions and data object. Positional
+// placeholder functions are created only for placeholders referenced in
+// message.
+func (f *config) placeholders(message string, used map[int]bool, arguments []interface{}) (placeholders template.FuncMap, object interface{}) {
+	referenced := f.referencedPositions(message+f.overridesText(), len(arguments))
+
+	placeholders = make(template.FuncMap)
 	placeholders[f.placeholder] = argumentAutomatic(used, arguments)
 
+	defined := make(map[string]int)
+	define := func(name string, position int, value interface{}) {
+		if previous, ok := defined[name]; ok && (previous != position) && (f.conflictPolicy == FirstWins) {
+			return
+		}
+
+		defined[name] = position
+		placeholders[name] = argumentValue(used, position, value)
+	}
+
 	for position, argument := range arguments {
-		placeholder := f.placeholder + strconv.Itoa(position)
-		placeholders[placeholder] = argumentValue(used, position, argument)
+		if referenced[position] {
+			placeholders[f.placeholder+strconv.Itoa(position)] = argumentValue(used, position, unwrapArgument(argument))
+		}
+
+		if named, ok := argument.(Argument); ok {
+			define(named.Name, position, named.Value)
+			continue
+		}
+
+		if args, ok := argument.(Args); ok {
+			for name, value := range args {
+				define(name, position, value)
+			}
+
+			continue
+		}
 
 		if _, ok := argument.(error); ok {
 			continue
 		}
 
+		if _, ok := argument.(*lazyValue); ok {
+			continue
+		}
+
 		valueOf := reflect.ValueOf(argument)
 
 		switch valueOf.Kind() {
 		case reflect.Map:
 			if reflect.TypeOf(argument).Key().Kind() == reflect.String {
-				for _, key := range valueOf.MapKeys() {
-					placeholders[key.String()] = argumentValue(used, position, valueOf.MapIndex(key).Interface())
+				for iterator := valueOf.MapRange(); iterator.Next(); {
+					define(iterator.Key().String(), position, iterator.Value().Interface())
 				}
 			}
 		case reflect.Struct:
 			object = argument
+			f.defineTagged(valueOf, position, define)
 		case reflect.Ptr:
 			if isObjectPointer(valueOf) {
 				object = argument
+				f.defineTagged(valueOf.Elem(), position, define)
 			}
 		}
 	}
 
-	t := template.New("").Delims(f.leftDelimiter, f.rightDelimiter).
-		Funcs(gFunctions).Funcs(placeholders).Funcs(template.FuncMap(f.functions))
+	f.foldPlaceholders(placeholders)
 
-	if _, err := t.Parse(message); err != nil {
-		return err
+	return placeholders, object
+}
+
+// referencedPositions returns positions of arguments that may be referenced in
+// message by positional placeholders. It only looks for placeholder names
+// followed by a number so false positives like names in quoted strings are
+// possible but harmless.
+func (f *config) referencedPositions(message string, count int) []bool {
+	referenced := make([]bool, count)
+	placeholder := f.placeholder
+
+	if f.caseInsensitive {
+		message, placeholder = strings.ToLower(message), strings.ToLower(placeholder)
 	}
 
-	if err := t.Execute(writer, object); err != nil {
-		return err
+	if placeholder == "" {
+		for position := range referenced {
+			referenced[position] = true
+		}
+
+		return referenced
 	}
 
-	if len(used) >= len(arguments) {
-		return nil
+	for offset := 0; offset < len(message); {
+		index := strings.Index(message[offset:], placeholder)
+
+		if index < 0 {
+			break
+		}
+
+		start := offset + index
+		end := start + len(placeholder)
+		offset = end
+
+		if (start > 0) && isIdentifierByte(message[start-1]) {
+			continue
+		}
+
+		for (end < len(message)) && (message[end] >= '0') && (message[end] <= '9') {
+			end++
+		}
+
+		if (end == offset) || ((end < len(message)) && isIdentifierByte(message[end])) {
+			continue
+		}
+
+		if position, err := strconv.Atoi(message[offset:end]); (err == nil) && (position < count) {
+			referenced[position] = true
+		}
 	}
 
-	message = ""
+	return referenced
+}
 
-	for position, argument := range arguments {
-		if !isArgumentUsed(used, position, argument) {
-			message += " " + fmt.Sprint(argument)
+func (f *config) functionMaps(writer io.Writer, placeholders template.FuncMap) []template.FuncMap {
+	functions := f.builtinFunctionMaps(writer)
+
+	if f.sandbox != nil {
+		for index, funcs := range functions {
+			functions[index] = f.sandboxFunctions(funcs)
 		}
 	}
 
-	return write(writer, message)
+	return append(functions, placeholders, f.sandboxFunctions(f.customFunctions(placeholders)),
+		template.FuncMap{indexFunction: f.index})
+}
+
+// builtinFunctionMaps returns built-in functions. Colors are enabled for
+// writer depending on color mode.
+func (f *config) builtinFunctionMaps(writer io.Writer) []template.FuncMap {
+	colored := isColorEnabled(f.colorMode, writer)
+	functions := []template.FuncMap{gFunctions, f.builtinFunctions(colored), f.widthAlgorithm.functions(),
+		GetLocale(f.locale).functions()}
+
+	if !colored {
+		functions = append(functions, gNoColorFunctions)
+	}
+
+	if env := f.envFunctions(); env != nil {
+		functions = append(functions, env)
+	}
+
+	return functions
+}
+
+// builtinFunctions returns built-in functions that depend on formatter
+// configuration like redaction. They replace defaults from built-in
+// functions but they don't shadow placeholders. Colored enables colors in
+// functions like diff.
+func (f *config) builtinFunctions(colored bool) template.FuncMap {
+	return template.FuncMap{
+		"format":     f.formatSpec,
+		"table":      f.table,
+		"json":       f.json,
+		"jsonIndent": f.jsonIndent,
+		"yaml":       f.yaml,
+		"toml":       f.toml,
+		"logfmt":     f.logfmt,
+		"dump":       f.dump,
+		"stack":      f.stack,
+		"diff":       f.diff(colored),
+		"mdTable":    f.mdTable,
+	}
+}
+
+func defaultConfig() config {
+	return config{
+		placeholder:     DefaultPlaceholder,
+		leftDelimiter:   DefaultLeftDelimiter,
+		rightDelimiter:  DefaultRightDelimiter,
+		locale:          DefaultLocale,
+		colorMode:       DefaultColorMode,
+		unusedPrefix:    DefaultUnusedPrefix,
+		unusedSeparator: DefaultUnusedSeparator,
+		writeBufferSize: DefaultWriteBufferSize,
+		batchWorkers:    DefaultBatchWorkers,
+		functions:       GlobalFunctions(),
+	}
+}
+
+func copyFunctions(functions Functions) Functions {
+	copied := make(Functions, len(functions))
+
+	for name, function := range functions {
+		copied[name] = function
+	}
+
+	return copied
+}
+
+func isIdentifierByte(c byte) bool {
+	return (c == '_') || ((c >= '0') && (c <= '9')) || ((c >= 'a') && (c <= 'z')) || ((c >= 'A') && (c <= 'Z'))
 }
 
 func isObjectPointer(value reflect.Value) bool {
@@ -292,7 +835,12 @@ func isObjectPointer(value reflect.Value) bool {
 }
 
 func isArgumentUsed(used map[int]bool, position int, argument interface{}) bool {
-	if _, ok := argument.(error); ok {
+	if _, ok := argument.(Argument); ok {
+		return true
+	}
+
+	switch argument.(type) {
+	case error, *lazyValue:
 		return used[position]
 	}
 
@@ -315,9 +863,11 @@ func isArgumentUsed(used map[int]bool, position int, argument interface{}) bool
 }
 
 func argumentValue(used map[int]bool, position int, argument interface{}) func() interface{} {
+	argument = lazy(argument)
+
 	return func() interface{} {
 		used[position] = true
-		return argument
+		return evaluate(argument)
 	}
 }
 
@@ -330,7 +880,7 @@ func argumentAutomatic(used map[int]bool, arguments []interface{}) func() interf
 
 		if position < length {
 			used[position] = true
-			argument = arguments[position]
+			argument = evaluate(unwrapArgument(arguments[position]))
 			position++
 		}
 
@@ -338,10 +888,45 @@ func argumentAutomatic(used map[int]bool, arguments []interface{}) func() interf
 	}
 }
 
+// getBuffer returns empty buffer from the pool.
+func getBuffer() *bytes.Buffer {
+	buffer, _ := gBuffers.Get().(*bytes.Buffer)
+	buffer.Reset()
+
+	return buffer
+}
+
+// putBuffer returns buffer to the pool. Large buffers are dropped to not keep
+// memory allocated for a long time.
+func putBuffer(buffer *bytes.Buffer) {
+	if buffer.Cap() <= maxPooledBufferSize {
+		gBuffers.Put(buffer)
+	}
+}
+
+// write writes message without copying it if writer implements
+// io.StringWriter.
 func write(writer io.Writer, message string) error {
-	if _, err := writer.Write([]byte(message)); err != nil {
+	if _, err := io.WriteString(writer, message); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// unusedArgumentsError returns error listing positions of unused arguments.
+func unusedArgumentsError(used map[int]bool, arguments []interface{}) error {
+	var positions []string
+
+	for position, argument := range arguments {
+		if !isArgumentUsed(used, position, argument) {
+			positions = append(positions, strconv.Itoa(position))
+		}
+	}
+
+	if len(positions) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("unused arguments at positions: %s", strings.Join(positions, ", "))
+}
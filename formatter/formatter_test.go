@@ -16,15 +16,29 @@ package formatter_test
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net"
+	"os"
 	"os/user"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"gitlab.com/tymonx/go-formatter/formatter"
 	"gitlab.com/tymonx/go-formatter/mocks"
+	"gopkg.in/yaml.v3"
 )
 
 func ExampleMustFormat() {
@@ -319,6 +333,146 @@ func ExampleFormat_backgroundBrightColors() {
 	fmt.Println(formatted)
 }
 
+func ExampleFormat_date() {
+	date := time.Date(2020, time.March, 4, 15, 30, 0, 0, time.UTC)
+
+	formatted, err := formatter.New().SetLocale("pl").Format(`Date {p0 | date "Monday, 2 January 2006"} {p0 | date "Kitchen"}`, date)
+
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(formatted)
+	// Output: Date środa, 4 marca 2020 3:30PM
+}
+
+func ExampleNewHTML() {
+	formatted, err := formatter.NewHTML().Format("<p>Hello {p}!</p>", "<script>alert(1)</script>")
+
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(formatted)
+	// Output: <p>Hello &lt;script&gt;alert(1)&lt;/script&gt;!</p>
+}
+
+func ExampleFormat_escapeDelimiters() {
+	formatted, err := formatter.Format(`JSON {{"id": {p}, "name": "{p}"}}`, 3, "foo")
+
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(formatted)
+	// Output: JSON {"id": 3, "name": "foo"}
+}
+
+func ExampleFormat_fallback() {
+	formatted, err := formatter.Format(`Hello {name | fallback "stranger"} from {city | fallback "nowhere"}`, formatter.Named{
+		"name": "Bob",
+	})
+
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(formatted)
+	// Output: Hello Bob from nowhere
+}
+
+func ExampleFormatPartial() {
+	partial, err := formatter.FormatPartial("{greeting}, {name | upper}! Id: {id}", formatter.Named{
+		"greeting": "Hello",
+		"id":       7,
+	})
+
+	if err != nil {
+		panic(err)
+	}
+
+	formatted, err := formatter.Format(partial, formatter.Named{
+		"name": "bob",
+	})
+
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(partial)
+	fmt.Println(formatted)
+	// Output:
+	// Hello, {name | upper}! Id: 7
+	// Hello, BOB! Id: 7
+}
+
+func ExampleFormat_nestedPlaceholders() {
+	person := Person{
+		Name: "Bob",
+		Address: Address{
+			City: "Warsaw",
+			Tags: map[string]string{"zip": "00-001"},
+		},
+	}
+
+	formatted, err := formatter.Format("Nested placeholders {person.Name}:{person.Address.City}:{person.Address.Tags.zip}", formatter.Named{
+		"person": person,
+	})
+
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(formatted)
+	// Output: Nested placeholders Bob:Warsaw:00-001
+}
+
+func ExampleArg() {
+	user := struct {
+		Name string
+	}{
+		Name: "Bob",
+	}
+
+	order := struct {
+		ID int
+	}{
+		ID: 42,
+	}
+
+	formatted, err := formatter.Format("User {user.Name} placed order {order.ID} ({p0.Name}, {p1.ID})",
+		formatter.Arg("user", user), formatter.Arg("order", order))
+
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(formatted)
+	// Output: User Bob placed order 42 (Bob, 42)
+}
+
+func ExampleSprintf() {
+	fmt.Println(formatter.Sprintf("Printf verbs %s:%d %0.2f%% {%v}", "file", 3, 45.678, true))
+	// Output: Printf verbs file:3 45.68% {true}
+}
+
+func ExampleNew_options() {
+	f := formatter.New(
+		formatter.WithDelimiters("<", ">"),
+		formatter.WithPlaceholder("arg"),
+		formatter.WithFunction("upper", strings.ToUpper),
+	)
+
+	formatted, err := f.Format("Options <arg1> <arg0 | upper>", "foo", 3)
+
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(formatted)
+	// Output: Options 3 FOO
+}
+
 func TestFormatterNew(test *testing.T) {
 	assert.NotNil(test, formatter.New())
 }
@@ -900,3 +1054,3576 @@ func TestFormatterBell(test *testing.T) {
 	assert.NoError(test, err)
 	assert.Equal(test, "\a", formatted)
 }
+
+func TestFormatterDate(test *testing.T) {
+	date := time.Date(2020, time.January, 6, 7, 8, 9, 0, time.UTC)
+
+	formatted, err := formatter.Format(`{p0 | date "Mon Jan _2 2006 15:04:05 January Monday"}`, date)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Mon Jan  6 2020 07:08:09 January Monday", formatted)
+}
+
+func TestFormatterDateLayoutName(test *testing.T) {
+	date := time.Date(2020, time.January, 6, 7, 8, 9, 0, time.UTC)
+
+	formatted, err := formatter.Format(`{p0 | date "RFC3339"} {p0 | date "DateOnly"}`, date)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "2020-01-06T07:08:09Z 2020-01-06", formatted)
+}
+
+func TestFormatterDateLocale(test *testing.T) {
+	date := time.Date(2020, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	f := formatter.New().SetLocale("de-DE")
+
+	assert.Equal(test, "de-DE", f.GetLocale())
+
+	formatted, err := f.Format(`{p0 | date "Mon Monday Jan January"}`, date)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "So Sonntag Mär März", formatted)
+
+	formatted, err = f.Format(`{p0 | date "Month Janitor Mon, 2 Jan"}`, date)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Month Janitor So, 1 Mär", formatted)
+
+	formatted, err = f.ResetLocale().Format(`{p0 | date "Monday"}`, date)
+
+	assert.NoError(test, err)
+	assert.Equal(test, formatter.DefaultLocale, f.GetLocale())
+	assert.Equal(test, "Sunday", formatted)
+}
+
+func TestFormatterDateRegisterLocale(test *testing.T) {
+	locale := *formatter.GetLocale("en")
+	locale.Days[0] = "Dimanche"
+
+	formatter.RegisterLocale("xx_YY", &locale)
+
+	formatted, err := formatter.New().SetLocale("xx-yy").Format(`{p0 | date "Monday"}`, time.Date(2020, time.March, 1, 0, 0, 0, 0, time.UTC))
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Dimanche", formatted)
+	assert.Equal(test, formatter.GetLocale("en"), formatter.GetLocale("unknown"))
+}
+
+func TestFormatterTimezone(test *testing.T) {
+	date := time.Date(2020, time.January, 6, 7, 8, 9, 0, time.UTC)
+
+	formatted, err := formatter.Format(`{p0 | tz "Asia/Tokyo" | date "15:04 MST"}`, date)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "16:08 JST", formatted)
+}
+
+func TestFormatterTimezoneError(test *testing.T) {
+	formatted, err := formatter.Format(`{p0 | tz "Invalid/Zone"}`, time.Now())
+
+	assert.Error(test, err)
+	assert.Empty(test, formatted)
+}
+
+func TestFormatterAgo(test *testing.T) {
+	defer func() {
+		formatter.Now = time.Now
+	}()
+
+	now := time.Date(2020, time.January, 6, 7, 8, 9, 0, time.UTC)
+
+	formatter.Now = func() time.Time {
+		return now
+	}
+
+	formatted, err := formatter.Format("{p0 | ago}, {p1 | ago}, {p2 | until}, {p3 | ago}",
+		now.Add(-3*time.Minute), now.Add(-time.Hour), now.Add(49*time.Hour), now)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "3 minutes ago, 1 hour ago, in 2 days, just now", formatted)
+}
+
+func TestFormatterAgoLocale(test *testing.T) {
+	defer func() {
+		formatter.Now = time.Now
+	}()
+
+	now := time.Date(2020, time.January, 6, 7, 8, 9, 0, time.UTC)
+
+	formatter.Now = func() time.Time {
+		return now
+	}
+
+	formatted, err := formatter.New().SetLocale("pl").Format("{p0 | ago}, {p1 | ago}, {p2 | until}",
+		now.Add(-22*time.Minute), now.Add(-25*time.Second), now.Add(400*24*time.Hour))
+
+	assert.NoError(test, err)
+	assert.Equal(test, "22 minuty temu, 25 sekund temu, za 1 rok", formatted)
+}
+
+func TestFormatterHumanizeDuration(test *testing.T) {
+	formatted, err := formatter.Format("{p0 | humanizeDuration} {p1 | humanizeDuration} {p2 | humanizeDuration} {p3 | humanizeDuration}",
+		2*time.Hour+15*time.Minute, -(26*time.Hour + 3*time.Second), 150*time.Millisecond, time.Duration(0))
+
+	assert.NoError(test, err)
+	assert.Equal(test, "2h 15m -1d 2h 3s 150ms 0s", formatted)
+
+	formatted, err = formatter.Format("{p0 | humanizeDuration} {p1 | humanizeDuration} {p2 | humanizeDuration}",
+		time.Duration(math.MinInt64), time.Duration(math.MaxInt64), -150*time.Millisecond)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "-106751d 23h 47m 16s 106751d 23h 47m 16s -150ms", formatted)
+}
+
+func TestFormatterBackgroundShortcuts(test *testing.T) {
+	formatted, err := formatter.Format("{bgBlue}{bgGray}{red | background}")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "\x1b[44m\x1b[100m\x1b[41m", formatted)
+}
+
+func TestFormatterColorModeNever(test *testing.T) {
+	f := formatter.New().SetColorMode(formatter.ColorNever)
+
+	assert.Equal(test, formatter.ColorNever, f.GetColorMode())
+
+	formatted, err := f.Format(`{red | bright | background}red{rgb 1 2 3}{color "0x123456"}{blink}{blink | off}{normal} {p}{bell}`, 3)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "red 3\a", formatted)
+	assert.Equal(test, formatter.DefaultColorMode, f.ResetColorMode().GetColorMode())
+}
+
+func TestFormatterColorModeAuto(test *testing.T) {
+	formatted, err := formatter.New().SetColorMode(formatter.ColorAuto).Format("{red}red{normal}")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "red", formatted)
+}
+
+func TestFormatterColorModeAutoBuffered(test *testing.T) {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		test.Skip("NO_COLOR is set")
+	}
+
+	// Character device like terminal, output is captured by output hook.
+	device, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+
+	if err != nil {
+		test.Skip(err)
+	}
+
+	defer device.Close()
+
+	var captured []string
+
+	f := formatter.New().SetColorMode(formatter.ColorAuto).SetOutputHook(func(output string) string {
+		captured = append(captured, output)
+		return output
+	})
+
+	assert.NoError(test, f.FormatWriter(device, "{red}red{normal}"))
+	assert.NoError(test, f.SetMetrics(&recordingMetrics{}).FormatWriter(device, "{red}red{normal}"))
+
+	compiled, err := f.Compile("{red}red{normal}")
+
+	assert.NoError(test, err)
+
+	_, err = compiled.Bind().WriteTo(device)
+
+	assert.NoError(test, err)
+	assert.Equal(test, []string{"\x1b[31mred\x1b[0m", "\x1b[31mred\x1b[0m", "\x1b[31mred\x1b[0m"}, captured)
+	assert.Greater(test, f.GetWriteBufferSize(), 0)
+}
+
+func TestFormatterSafeHTML(test *testing.T) {
+	f := formatter.New().SetSafeHTML(true)
+
+	assert.True(test, f.IsSafeHTML())
+
+	formatted, err := f.Format(`<a href="/user?name={name}" title="{name}">{p0 | upper}</a>`, "<b>", formatter.Named{
+		"name": `a&b "c"`,
+	}, "<i>")
+
+	assert.NoError(test, err)
+	assert.Equal(test, `<a href="/user?name=a%26b%20%22c%22" title="a&amp;b &#34;c&#34;">&lt;B&gt;</a> &lt;i&gt;`, formatted)
+	assert.False(test, f.SetSafeHTML(false).IsSafeHTML())
+}
+
+func TestFormatterSafeHTMLError(test *testing.T) {
+	formatted, err := formatter.NewHTML().Format("{invalid}")
+
+	assert.Error(test, err)
+	assert.Empty(test, formatted)
+}
+
+func TestFormatterEscapeDelimiters(test *testing.T) {
+	formatted, err := formatter.Format(`{{p}} {{{p}}} {"}" | printf "%s}}"} {/* } */}{'}'} }`, 4)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "{p} {4} }}} 125 }", formatted)
+}
+
+func TestFormatterEscapeCustomDelimiters(test *testing.T) {
+	formatted, err := formatter.New().SetDelimiters("<%", "%>").Format("<%<%p%>%> <%p%>", 5)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "<%p%> 5", formatted)
+}
+
+func TestFormatterEscapeUnclosed(test *testing.T) {
+	formatted, err := formatter.Format(`{"}`)
+
+	assert.Error(test, err)
+	assert.Empty(test, formatted)
+}
+
+func TestFormatterEmpty(test *testing.T) {
+	formatted, err := formatter.Format("")
+
+	assert.NoError(test, err)
+	assert.Empty(test, formatted)
+
+	formatted, err = formatter.NewHTML().Format("")
+
+	assert.NoError(test, err)
+	assert.Empty(test, formatted)
+}
+
+func TestFormatterFallback(test *testing.T) {
+	var pointer *int
+
+	formatted, err := formatter.Format(`{a | fallback "A"} {fallback "B" b} {c | fallback "C"} {p1 | fallback "D"} {p2 | fallback 3} {d | fallback "E"}`,
+		formatter.Named{"a": nil, "c": "", "d": 0}, pointer, 5)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "A B C D 5 0", formatted)
+}
+
+func TestFormatterFallbackUndefined(test *testing.T) {
+	formatted, err := formatter.Format(`{a | upper | fallback "A"}`)
+
+	assert.Error(test, err)
+	assert.Empty(test, formatted)
+	assert.Contains(test, err.Error(), `function "a" not defined`)
+}
+
+func TestFormatterPartial(test *testing.T) {
+	formatted, err := formatter.New().FormatPartial(`{{ {p0} {p1} {.Name} {a "x"} {b | fallback "y"} {"{}" | upper} }}`, "{x}")
+
+	assert.NoError(test, err)
+	assert.Equal(test, `{{ {{x}} {p1} {.Name} {a "x"} {b | fallback "y"} {{}} }}`, formatted)
+}
+
+func TestFormatterPartialObject(test *testing.T) {
+	object := struct {
+		Name  string
+		Items []string
+	}{
+		Name:  "foo",
+		Items: []string{"a", "b"},
+	}
+
+	formatted, err := formatter.FormatPartial(`{.Name}{range .Items} {.}{end}{if p0} {p0.Name}{end} {$x := 3}{$x} {x}`, object)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "foo a b foo 3 {x}", formatted)
+}
+
+func TestFormatterPartialAutomatic(test *testing.T) {
+	formatted, err := formatter.FormatPartial("{p} {p}", "a")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "a {p}", formatted)
+
+	formatted, err = formatter.FormatPartial(formatted, "b")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "a b", formatted)
+
+	formatted, err = formatter.FormatPartial(`{p | upper} {x} {p} {p | printf "%v-%v" p}`, "a", "b")
+
+	assert.NoError(test, err)
+	assert.Equal(test, `A {x} b {p | printf "%v-%v" p}`, formatted)
+
+	formatted, err = formatter.New().SetPlaceholderStyle(formatter.PythonStyle).FormatPartial("{} {}", "a")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "a {p}", formatted)
+}
+
+func TestFormatterPartialError(test *testing.T) {
+	formatted, err := formatter.FormatPartial(`{if x}`)
+
+	assert.Error(test, err)
+	assert.Empty(test, formatted)
+}
+
+func TestFormatterPartialBlocks(test *testing.T) {
+	formatted, err := formatter.FormatPartial("{if premium}Thanks {name}{end} {name}|{if p0}{name}{end}",
+		true, formatter.Named{"name": "Bob"})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "{if premium}Thanks {name}{end} Bob|Bob", formatted)
+
+	formatted, err = formatter.FormatPartial("{if p0}a{else if trial}b{else}c{end}|{if p0}{missing}{end}", true)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "{if p0}a{else if trial}b{else}c{end}|{missing}", formatted)
+
+	formatted, err = formatter.FormatPartial("{each items}{.Name}, {end}|{each p0}{.}{end}|{range .Items}{.}{end}", []int{1, 2})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "{each items}{.Name}, {end}|12|{range .Items}{.}{end}", formatted)
+
+	formatted, err = formatter.FormatPartial("{with account}{.Name} {{x}}{if x}{end}{else}none{end}|{with p0}{.}{end}", "v")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "{with account}{.Name} {{x}}{if x}{end}{else}none{end}|v", formatted)
+
+	formatted, err = formatter.Format(formatted, formatter.Named{"account": Person{Name: "Bob"}})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Bob {x}|v", formatted)
+}
+
+func TestFormatterNestedPlaceholders(test *testing.T) {
+	person := &Person{
+		Name:    "Bob",
+		Address: Address{City: "Warsaw"},
+		Manager: &Person{Name: "Alice"},
+	}
+
+	formatted, err := formatter.Format(`{p.Manager.Name} {p0.DisplayName} {p0.Manager.DisplayName | upper} {.Address.City} {.Greet "Hi"} {printf "%s" person.Name}`,
+		person, formatter.Named{"person": person})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Alice Mr. Bob MR. ALICE Warsaw Hi Bob Bob", formatted)
+}
+
+func TestFormatterNestedPlaceholdersMissing(test *testing.T) {
+	person := Person{
+		Address: Address{Tags: map[string]string{}},
+	}
+
+	for message, expected := range map[string]string{
+		"{person.Address.Town}":     `cannot evaluate "Address.Town" in "person.Address.Town": field "Town" not found in type formatter_test.Address`,
+		"{person.Address.Tags.zip}": `cannot evaluate "Address.Tags.zip" in "person.Address.Tags.zip": key "zip" not found in map`,
+		"{person.Manager.Name}":     `cannot evaluate "Manager.Name" in "person.Manager.Name": nil pointer`,
+		"{person.Name.Length}":      `cannot evaluate "Name.Length" in "person.Name.Length": type string has no fields`,
+		"{person.Validate}":         `cannot evaluate "Validate" in "person.Validate": invalid person`,
+		"{person.Greet}":            `cannot evaluate "Greet" in "person.Greet": method requires arguments`,
+	} {
+		formatted, err := formatter.Format(message, formatter.Named{"person": &person})
+
+		assert.Error(test, err, message)
+		assert.Contains(test, err.Error(), expected)
+		assert.Empty(test, formatted)
+	}
+}
+
+func TestFormatterNestedPlaceholdersFallback(test *testing.T) {
+	formatted, err := formatter.Format(`{person.Manager.Name | fallback "none"} {fallback "no city" other.Address.City}`, formatter.Named{
+		"person": Person{},
+	})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "none no city", formatted)
+}
+
+func TestFormatterMultipleObjects(test *testing.T) {
+	formatted, err := formatter.Format("{p0.Name} {p1.Name} {.Name} {p.Name}", Person{Name: "A"}, &Person{Name: "B"})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "A B B A", formatted)
+}
+
+func TestFormatterArg(test *testing.T) {
+	formatted, err := formatter.Format("{p} {name} {p1}", formatter.Arg("name", "foo"), formatter.Arg("object", Person{}), 3)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "foo foo { { <nil> map[]} <nil>} 3", formatted)
+}
+
+func TestFormatterArgUnused(test *testing.T) {
+	formatted, err := formatter.Format("{.Name}", formatter.Arg("object", Person{Name: "A"}), 3)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "<no value> 3", formatted)
+}
+
+func TestFormatterSprintf(test *testing.T) {
+	for format, arguments := range map[string][]interface{}{
+		"%[2]d %[1]s %d":       {"a", 2},
+		"%*d|%-*.*f|%x":        {5, 42, 8, 2, 3.14159, 255},
+		"%[3]*.[2]*[1]f":       {12.0, 2, 6},
+		"%+q %#v %T %v %c %U":  {"é", []int{1}, 4.5, nil, 'x', 0x1F600},
+		"{} %d {{}}":           {7},
+		"%d %s":                {1},
+		"%!":                   {},
+		"trailing %":           {},
+		"%5.2":                 {},
+		"%[x]d %[0]d %[1d":     {},
+		"%é %d":                {1, 2},
+		"%s %v":                {Error("error"), &Person{Name: "A"}},
+		"no verbs":             {},
+		"%08.3f|% d|%-5d|%05s": {3.14159, 7, 3, "ab"},
+	} {
+		assert.Equal(test, fmt.Sprintf(format, arguments...), formatter.Sprintf(format, arguments...), format)
+	}
+}
+
+func TestFormatterSprintfUnused(test *testing.T) {
+	assert.Equal(test, "1 2 3", formatter.Sprintf("%d", 1, 2, 3))
+	assert.Equal(test, "%!d(BADINDEX) 3", formatter.Sprintf("%[x]d", 3))
+}
+
+func TestFormatterSprintfCustomDelimiters(test *testing.T) {
+	assert.Equal(test, "<3><4>", formatter.New().SetDelimiters("<", ">").Sprintf("<%d><%d>", 3, 4))
+}
+
+func TestFormatterSprintfError(test *testing.T) {
+	formatted := formatter.New().AddFunction("printf", func(string, ...interface{}) (string, error) {
+		return "", Error("error")
+	}).Sprintf("%d", 3)
+
+	assert.True(test, strings.HasPrefix(formatted, "%!(ERROR="), formatted)
+}
+
+func TestFormatterTranslatePrintf(test *testing.T) {
+	assert.Equal(test, `{{}} {printf "%s" p0}:{printf "%*d" p2 p3} 100%`, formatter.TranslatePrintf("{} %s:%[3]*d 100%%"))
+}
+
+func TestFormatterNewOptions(test *testing.T) {
+	f := formatter.New(
+		formatter.WithDelimiters("<", ">"),
+		formatter.WithPlaceholder("arg"),
+		formatter.WithFunctions(formatter.Functions{"twice": func(v int) int { return 2 * v }}),
+		formatter.WithLocale("pl"),
+		formatter.WithColorMode(formatter.ColorNever),
+		formatter.WithSafeHTML(),
+		formatter.WithStrict(),
+	)
+
+	assert.Equal(test, "<", f.GetLeftDelimiter())
+	assert.Equal(test, ">", f.GetRightDelimiter())
+	assert.Equal(test, "arg", f.GetPlaceholder())
+	assert.Equal(test, "pl", f.GetLocale())
+	assert.Equal(test, formatter.ColorNever, f.GetColorMode())
+	assert.True(test, f.IsSafeHTML())
+	assert.True(test, f.IsStrict())
+
+	formatted, err := f.Format("<arg0 | twice>", 3)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "6", formatted)
+}
+
+func TestFormatterStrict(test *testing.T) {
+	f := formatter.New(formatter.WithStrict())
+
+	formatted, err := f.Format("{p1}", 1, 2, 3)
+
+	assert.EqualError(test, err, "unused arguments at positions: 0, 2")
+	assert.Equal(test, "", formatted)
+
+	formatted, err = f.Format("{p0} {p1}", 1, formatter.Arg("name", 2))
+
+	assert.NoError(test, err)
+	assert.Equal(test, "1 2", formatted)
+
+	formatted, err = f.SetStrict(false).Format("{p1}", 1, 2, 3)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "2 1 3", formatted)
+}
+
+func TestFormatterConcurrent(test *testing.T) {
+	f := formatter.New()
+
+	var group sync.WaitGroup
+
+	for index := 0; index < 8; index++ {
+		group.Add(1)
+
+		go func(index int) {
+			defer group.Done()
+
+			for loop := 0; loop < 100; loop++ {
+				f.AddFunction("value"+strconv.Itoa(index), func() int { return index })
+				f.SetLocale("en").SetStrict(false)
+
+				formatted, err := f.Format("{p}", index)
+
+				assert.NoError(test, err)
+				assert.Equal(test, strconv.Itoa(index), formatted)
+				assert.NotEmpty(test, f.GetFunctions())
+			}
+		}(index)
+	}
+
+	group.Wait()
+}
+
+func TestFormatterClone(test *testing.T) {
+	base := formatter.New(formatter.WithDelimiters("<", ">"), formatter.WithFunction("name", func() string { return "base" }))
+	clone := base.Clone().AddFunction("extra", func() string { return "extra" }).SetStrict(true)
+
+	formatted, err := clone.Format("<name> <extra>")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "base extra", formatted)
+
+	assert.Nil(test, base.GetFunction("extra"))
+	assert.False(test, base.IsStrict())
+	assert.Equal(test, "<", clone.GetLeftDelimiter())
+
+	_, err = base.Format("<extra>")
+
+	assert.Error(test, err)
+}
+
+func TestFormatterPlainMessage(test *testing.T) {
+	f := formatter.New()
+
+	allocations := testing.AllocsPerRun(100, func() {
+		formatted, err := f.Format("Plain message } without replacement fields")
+
+		if (err != nil) || (formatted != "Plain message } without replacement fields") {
+			test.Fail()
+		}
+	})
+
+	assert.Zero(test, allocations)
+
+	formatted, err := f.Format("Plain message", 3, "foo")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Plain message 3 foo", formatted)
+
+	formatted, err = f.Format("Escaped }}")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Escaped }", formatted)
+
+	_, err = f.SetStrict(true).Format("Plain message", 3)
+
+	assert.EqualError(test, err, "unused arguments at positions: 0")
+}
+
+func TestFormatterBufferReuse(test *testing.T) {
+	f := formatter.New()
+
+	for index := 0; index < 10; index++ {
+		formatted, err := f.Format("{p} {p}", index, strings.Repeat("x", index))
+
+		assert.NoError(test, err)
+		assert.Equal(test, strconv.Itoa(index)+" "+strings.Repeat("x", index), formatted)
+	}
+}
+
+func TestFormatterReferencedPlaceholders(test *testing.T) {
+	arguments := make([]interface{}, 20)
+
+	for position := range arguments {
+		arguments[position] = position
+	}
+
+	formatted, err := formatter.Format("{p12}:{p1|printf \"%03d\"}", arguments[:13]...)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "12:001 0 2 3 4 5 6 7 8 9 10 11", formatted)
+
+	formatted, err = formatter.Format("{p2}{p19}", arguments...)
+
+	assert.NoError(test, err)
+	assert.True(test, strings.HasPrefix(formatted, "219 0 1 3"), formatted)
+
+	_, err = formatter.Format("{xp1}", arguments...)
+
+	assert.Error(test, err)
+
+	_, err = formatter.Format("{p1x}", arguments...)
+
+	assert.Error(test, err)
+}
+
+func TestFormatterAppendFormat(test *testing.T) {
+	buffer := make([]byte, 0, 64)
+	buffer = append(buffer, "log: "...)
+
+	buffer, err := formatter.New().AppendFormat(buffer, "{p} {p}", "foo", 3)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "log: foo 3", string(buffer))
+
+	buffer, err = formatter.AppendFormat(buffer, " plain")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "log: foo 3 plain", string(buffer))
+
+	buffer, err = formatter.AppendFormat(buffer, " {p | undefined}", 3)
+
+	assert.Error(test, err)
+	assert.Equal(test, "log: foo 3 plain", string(buffer))
+}
+
+func TestFormatterFormatContext(test *testing.T) {
+	formatted, err := formatter.FormatContext(context.Background(), "Context {p}", 3)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Context 3", formatted)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = formatter.FormatContext(ctx, "Context {p}", 3)
+
+	assert.True(test, errors.Is(err, context.Canceled), err)
+}
+
+func TestFormatterExecutionTimeout(test *testing.T) {
+	f := formatter.New(formatter.WithExecutionTimeout(10*time.Millisecond)).AddFunction("forever", func() chan int {
+		values := make(chan int)
+
+		go func() {
+			for {
+				values <- 0
+			}
+		}()
+
+		return values
+	})
+
+	assert.Equal(test, 10*time.Millisecond, f.GetExecutionTimeout())
+
+	var buffer bytes.Buffer
+
+	err := f.FormatWriter(&buffer, "{range forever}{.}{end}")
+
+	assert.True(test, errors.Is(err, context.DeadlineExceeded), err)
+	assert.Empty(test, buffer.String())
+
+	formatted, err := f.Format("Timeout {p}", 3)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Timeout 3", formatted)
+
+	assert.Zero(test, f.ResetExecutionTimeout().GetExecutionTimeout())
+}
+
+func TestFormatterExecutionTimeoutStopsLoop(test *testing.T) {
+	var iterations int64
+
+	f := formatter.New(formatter.WithExecutionTimeout(10 * time.Millisecond)).AddFunctions(formatter.Functions{
+		"count": func() string {
+			atomic.AddInt64(&iterations, 1)
+			return ""
+		},
+		"forever": func() chan int {
+			values := make(chan int)
+
+			go func() {
+				for {
+					values <- 0
+				}
+			}()
+
+			return values
+		},
+	})
+
+	_, err := f.Format("{range forever}{$x := count}{end}")
+
+	assert.True(test, errors.Is(err, context.DeadlineExceeded), err)
+
+	stopped := atomic.LoadInt64(&iterations)
+
+	time.Sleep(20 * time.Millisecond)
+
+	assert.Less(test, atomic.LoadInt64(&iterations)-stopped, int64(1000))
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var buffer bytes.Buffer
+
+	err = formatter.New().AddFunction("cancel", func() string {
+		cancel()
+		time.Sleep(time.Millisecond)
+
+		return "late"
+	}).FormatWriterContext(ctx, &buffer, "{range p0}{cancel}{end}", []int{1, 2, 3})
+
+	assert.True(test, errors.Is(err, context.Canceled), err)
+	assert.Empty(test, buffer.String())
+}
+
+func TestFormatterMaxOutputSize(test *testing.T) {
+	f := formatter.New().SetMaxOutputSize(8)
+
+	assert.Equal(test, 8, f.GetMaxOutputSize())
+
+	formatted, err := f.Format("{p}", "12345678")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "12345678", formatted)
+
+	_, err = f.Format("{range p}{.}{end}", []string{"12345", "6789"})
+
+	assert.True(test, errors.Is(err, formatter.ErrOutputTooLarge), err)
+
+	_, err = f.Format("Too long plain message")
+
+	assert.True(test, errors.Is(err, formatter.ErrOutputTooLarge), err)
+
+	_, err = f.Format("{p}", "1234", "56789")
+
+	assert.True(test, errors.Is(err, formatter.ErrOutputTooLarge), err)
+
+	_, err = f.FormatPartial("{p} {unknown}", "1234")
+
+	assert.True(test, errors.Is(err, formatter.ErrOutputTooLarge), err)
+}
+
+func TestFormatterMaxDepth(test *testing.T) {
+	f := formatter.New().SetMaxDepth(2)
+
+	assert.Equal(test, 2, f.GetMaxDepth())
+
+	formatted, err := f.Format("{if true}{with p}{.}{end}{end}", 3)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "3", formatted)
+
+	_, err = f.Format("{if true}{with p}{if .}{.}{end}{end}{end}", 3)
+
+	assert.True(test, errors.Is(err, formatter.ErrTooDeep), err)
+
+	_, err = f.Format("{if true}{with p}{(print (print .))}{end}{end}", 3)
+
+	assert.True(test, errors.Is(err, formatter.ErrTooDeep), err)
+
+	_, err = f.Format(`{define "loop"}{template "loop" .}{end}{template "loop" p}`, 3)
+
+	assert.True(test, errors.Is(err, formatter.ErrTooDeep), err)
+
+	formatted, err = f.SetMaxDepth(0).Format(`{if true}{with p}{if .}{.}{end}{end}{end}`, 3)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "3", formatted)
+}
+
+func TestFormatterExecError(test *testing.T) {
+	var execError *formatter.ExecError
+
+	_, err := formatter.Format("{.ManagerName}", &Person{Name: "Bob"})
+
+	assert.True(test, errors.As(err, &execError), err)
+	assert.NotEmpty(test, execError.Stack)
+	assert.Contains(test, execError.Error(), "nil pointer dereference")
+	assert.Error(test, execError.Unwrap())
+
+	formatted, err := formatter.Format(`{.ManagerName | fallback "none"}`, &Person{Name: "Bob"})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "none", formatted)
+
+	err = formatter.FormatWriter(&WriterPanic{}, "{p}", 3)
+
+	assert.True(test, errors.As(err, &execError), err)
+	assert.Equal(test, "writer panic", execError.Value)
+	assert.NoError(test, execError.Unwrap())
+}
+
+func TestFormatterUndefinedFunctionError(test *testing.T) {
+	var undefined *formatter.UndefinedFunctionError
+
+	_, err := formatter.Format("Undefined {count}")
+
+	assert.True(test, errors.As(err, &undefined), err)
+	assert.Equal(test, "count", undefined.Name)
+	assert.Equal(test, `template: :1:11: function "count" not defined`, err.Error())
+}
+
+func TestFormatterDidYouMean(test *testing.T) {
+	var undefined *formatter.UndefinedFunctionError
+
+	_, err := formatter.Format("Hello {usrname}", formatter.Named{"username": "bob", "user": "x", "id": 1})
+
+	assert.True(test, errors.As(err, &undefined), err)
+	assert.Equal(test, []string{"username"}, undefined.Suggestions)
+	assert.Equal(test, `template: :1:7: function "usrname" not defined, did you mean "username"?`, err.Error())
+
+	_, err = formatter.Format("{p0 | uper}", "a")
+
+	assert.Contains(test, err.Error(), `did you mean "upper"`)
+
+	_, err = formatter.Format("{.Nmae}", Person{Name: "bob"})
+
+	assert.Contains(test, err.Error(), `field "Nmae" not found in type formatter_test.Person, did you mean "Name"?`)
+
+	_, err = formatter.Format("{user.Emial}", formatter.Named{"user": map[string]string{"Email": "x", "Name": "y"}})
+
+	assert.Contains(test, err.Error(), `key "Emial" not found in map, did you mean "Email"?`)
+}
+
+func TestFormatterPlaceholders(test *testing.T) {
+	placeholders, err := formatter.Placeholders(`{count} {p0 | printf "%d"} {person.Name} {.Email} {name | fallback "x"} {if p}{red}{end} {{skip}}`)
+
+	assert.NoError(test, err)
+	assert.Equal(test, []string{".Email", "count", "name", "p", "p0", "person"}, placeholders)
+
+	placeholders, err = formatter.New().SetDelimiters("<", ">").AddFunction("custom", strings.ToUpper).Placeholders("<custom> <arg>")
+
+	assert.NoError(test, err)
+	assert.Equal(test, []string{"arg"}, placeholders)
+
+	_, err = formatter.Placeholders("{if}")
+
+	assert.Error(test, err)
+}
+
+func TestFormatterPseudoLocalization(test *testing.T) {
+	f := formatter.New(formatter.WithPseudoLocalization())
+
+	assert.True(test, f.IsPseudoLocalization())
+
+	formatted, err := f.Format("Hello {name}, you have {p1 | printf \"%d\"} files", formatter.Named{"name": "Bob"}, 3)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "[Ĥéļļö Bob, ýöü ĥáṽé 3 ƒíļéš~~~~~~]", formatted)
+
+	formatted, err = f.Format("Plain")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "[Þļáíñ~~]", formatted)
+
+	formatted, err = f.SetPseudoLocalization(false).Format("Plain")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Plain", formatted)
+
+	assert.Equal(test, "[Ţéšţ 123~~~]", formatter.PseudoLocalize("Test 123"))
+}
+
+func TestFormatterReferences(test *testing.T) {
+	references, err := formatter.References("{p1} {name | fallback \"x\"}\n{.Email} {p} {p01}")
+
+	assert.NoError(test, err)
+	assert.Equal(test, []formatter.Reference{
+		{Name: "p1", Position: 1, Location: ":1:1"},
+		{Name: "name", Position: -1, Fallback: true, Location: ":1:6"},
+		{Name: ".Email", Position: -1, Location: ":2:1"},
+		{Name: "p", Position: -1, Automatic: true, Location: ":2:10"},
+		{Name: "p01", Position: -1, Location: ":2:14"},
+	}, references)
+
+	_, err = formatter.References("{if}")
+
+	assert.Error(test, err)
+}
+
+func ExampleScan() {
+	var level string
+
+	var code int
+
+	var elapsed time.Duration
+
+	err := formatter.Scan("[{p}] request failed with {p} after {p}", "[error] request failed with 503 after 1.5s",
+		&level, &code, &elapsed)
+
+	fmt.Println(level, code, elapsed, err)
+	// Output: error 503 1.5s <nil>
+}
+
+func TestFormatterScan(test *testing.T) {
+	var name string
+
+	var count uint8
+
+	var ratio float64
+
+	var enabled bool
+
+	var raw interface{}
+
+	assert.NoError(test, formatter.Scan("{p1} has {count} items, {p0} {{ratio}} {ratio}: {p2}",
+		"Bob has 12 items, true {ratio} 0.5: raw",
+		&enabled, &name, &raw, formatter.Arg("count", &count), formatter.Named{"ratio": &ratio}))
+
+	assert.Equal(test, "Bob", name)
+	assert.Equal(test, uint8(12), count)
+	assert.Equal(test, 0.5, ratio)
+	assert.True(test, enabled)
+	assert.Equal(test, "raw", raw)
+
+	named := formatter.Named{}
+
+	assert.NoError(test, formatter.Scan("{first} {last} {first}", "John Smith John", named))
+	assert.Equal(test, formatter.Named{"first": "John", "last": "Smith"}, named)
+
+	person := &Person{}
+
+	assert.NoError(test, formatter.Scan("Name: {.Name}", "Name: Alice", person))
+	assert.Equal(test, "Alice", person.Name)
+
+	var at time.Time
+
+	assert.NoError(test, formatter.New().SetDelimiters("<", ">").Scan("at <p>", "at 2020-01-02T03:04:05Z", &at))
+	assert.Equal(test, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), at)
+}
+
+func TestFormatterScanError(test *testing.T) {
+	var value int
+
+	err := formatter.Scan("value {p}", "other 1", &value)
+
+	assert.True(test, errors.Is(err, formatter.ErrNoMatch), err)
+
+	err = formatter.Scan("{p0} {p0}", "1 2", &value)
+
+	assert.True(test, errors.Is(err, formatter.ErrNoMatch), err)
+
+	err = formatter.Scan("value {p}", "value x", &value)
+
+	assert.Error(test, err)
+	assert.Contains(test, err.Error(), `cannot scan "x" for placeholder p0`)
+
+	assert.Error(test, formatter.Scan("{p1}", "x", &value))
+	assert.Error(test, formatter.Scan("{p}", "x", value))
+	assert.Error(test, formatter.Scan("{p | upper}", "x", &value))
+	assert.Error(test, formatter.Scan("{name}", "x", &value))
+	assert.Error(test, formatter.Scan("{.Missing}", "x", &Person{}))
+	assert.Error(test, formatter.Scan("{p}", "x", new(error)))
+}
+
+func ExampleErrorf() {
+	err := formatter.Errorf("cannot open {file}: {err}", formatter.Named{"file": "config.yaml", "err": os.ErrNotExist})
+
+	fmt.Println(err)
+	fmt.Println(errors.Is(err, os.ErrNotExist))
+	// Output:
+	// cannot open config.yaml: file does not exist
+	// true
+}
+
+func TestFormatterErrorf(test *testing.T) {
+	first, second := Error("first"), errors.New("second")
+
+	err := formatter.Errorf("failed: {p}", first)
+
+	assert.Equal(test, "failed: first", err.Error())
+	assert.Equal(test, first, errors.Unwrap(err))
+
+	var target Error
+
+	assert.True(test, errors.As(err, &target))
+	assert.Equal(test, first, target)
+
+	err = formatter.Errorf("{p0} and {cause}", first, formatter.Arg("cause", second))
+
+	assert.Equal(test, "first and second", err.Error())
+	assert.True(test, errors.Is(err, first))
+	assert.True(test, errors.Is(err, second))
+	assert.Nil(test, errors.Unwrap(err))
+
+	err = formatter.Errorf("no errors {p}", 1)
+
+	assert.Equal(test, "no errors 1", err.Error())
+	assert.Nil(test, errors.Unwrap(err))
+	assert.False(test, err == formatter.Errorf("no errors {p}", 1))
+
+	err = formatter.Errorf("{if}", first)
+
+	assert.Contains(test, err.Error(), "%!(ERROR=")
+	assert.True(test, errors.Is(err, first))
+}
+
+func ExamplePrintln() {
+	_, _ = formatter.Println("Hello {p}!", "World")
+	_, _ = formatter.Print("No newline {p}", 1)
+	// Output:
+	// Hello World!
+	// No newline 1
+}
+
+func TestFormatterPrint(test *testing.T) {
+	var buffer bytes.Buffer
+
+	count, err := formatter.Fprint(&buffer, "Value {p}", 1)
+
+	assert.NoError(test, err)
+	assert.Equal(test, 7, count)
+	assert.Equal(test, "Value 1", buffer.String())
+
+	buffer.Reset()
+	count, err = formatter.New().SetDelimiters("<", ">").Fprintln(&buffer, "Value <p>", 2)
+
+	assert.NoError(test, err)
+	assert.Equal(test, 8, count)
+	assert.Equal(test, "Value 2\n", buffer.String())
+
+	buffer.Reset()
+	count, err = formatter.Fprintln(&buffer, "{if}")
+
+	assert.Error(test, err)
+	assert.Zero(test, count)
+	assert.Empty(test, buffer.String())
+}
+
+func TestFormatterArgs(test *testing.T) {
+	user := &Person{Name: "Bob"}
+	order := struct{ ID int }{ID: 42}
+
+	formatted, err := formatter.Format("{user.Name} placed order {order.ID} {p1}",
+		formatter.Args{"user": user, "order": order}, "after")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Bob placed order 42 after", formatted)
+
+	formatted, err = formatter.Format("{.Name} {user.DisplayName}", formatter.Args{"user": user}, Person{Name: "Alice"})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Alice Mr. Bob", formatted)
+
+	formatted, err = formatter.New().SetStrict(true).Format("{user.Name}", formatter.Args{"user": user, "unused": 1})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Bob", formatted)
+
+	first := Error("first")
+	err = formatter.Errorf("failed: {cause}", formatter.Args{"cause": first})
+
+	assert.Equal(test, "failed: first", err.Error())
+	assert.True(test, errors.Is(err, first))
+}
+
+func ExampleLazy() {
+	dump := formatter.Lazy(func() interface{} {
+		fmt.Println("evaluated")
+		return "state"
+	})
+
+	fmt.Println(formatter.MustFormat("Value {p0}", 1, formatter.Arg("dump", dump)))
+	fmt.Println(formatter.MustFormat("Dump {dump} {dump}", formatter.Arg("dump", dump)))
+	// Output:
+	// Value 1
+	// evaluated
+	// Dump state state
+}
+
+func TestFormatterLazy(test *testing.T) {
+	calls := 0
+
+	function := func() interface{} {
+		calls++
+		return calls
+	}
+
+	formatted, err := formatter.Format("{p0} {p0} {p}", function, "unused")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "1 1 1 unused", formatted)
+	assert.Equal(test, 1, calls)
+
+	formatted, err = formatter.Format("{p1}", formatter.Lazy(function), "used")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "used 2", formatted)
+	assert.Equal(test, 2, calls)
+
+	formatted, err = formatter.Format("{name}", formatter.Named{"name": formatter.Lazy(function), "other": function})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "3", formatted)
+	assert.Equal(test, 3, calls)
+
+	_, err = formatter.New().SetStrict(true).Format("Strict", formatter.Lazy(function))
+
+	assert.Error(test, err)
+	assert.Equal(test, 3, calls)
+
+	formatted, err = formatter.FormatPartial("{p0} {missing}", formatter.Lazy(function))
+
+	assert.NoError(test, err)
+	assert.Equal(test, "4 {missing}", formatted)
+}
+
+func TestFormatterFormattable(test *testing.T) {
+	formatted, err := formatter.Format("{p0} {p0 | format \"short\"} {price} {p}", Money(12345), formatter.Arg("price", Money(5)))
+
+	assert.NoError(test, err)
+	assert.Equal(test, "$123.45 $123 $0.05 $123.45", formatted)
+
+	formatted, err = formatter.New().SetSafeHTML(true).Format("<b>{p}</b>", Money(100))
+
+	assert.NoError(test, err)
+	assert.Equal(test, "<b>$1.00</b>", formatted)
+
+	formatted, err = formatter.Format("Total", Money(100))
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Total $1.00", formatted)
+
+	_, err = formatter.Format("{p | format \"long\"}", Money(1))
+
+	assert.Error(test, err)
+	assert.Contains(test, err.Error(), "unknown spec")
+
+	_, err = formatter.Format("{p | format \"short\"}", 1)
+
+	assert.Error(test, err)
+	assert.Contains(test, err.Error(), "doesn't implement Formattable")
+}
+
+func TestFormatterHooks(test *testing.T) {
+	var positions []int
+
+	f := formatter.New(formatter.WithArgumentHook(func(position int, value interface{}) interface{} {
+		positions = append(positions, position)
+
+		if text, ok := value.(string); ok && strings.HasPrefix(text, "secret:") {
+			return "***"
+		}
+
+		return value
+	}))
+
+	formatted, err := f.Format("{p0} {token} {p2}", "public", formatter.Arg("token", "secret:abc"),
+		formatter.Lazy(func() interface{} { return "secret:lazy" }))
+
+	assert.NoError(test, err)
+	assert.Equal(test, "public *** ***", formatted)
+	assert.Equal(test, []int{0, 1, 2}, positions)
+	assert.NotNil(test, f.GetArgumentHook())
+
+	formatted, err = f.FormatPartial("{p0} {missing}", "secret:x")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "*** {missing}", formatted)
+
+	f = formatter.New().SetMaxOutputSize(10).SetOutputHook(func(output string) string {
+		return strings.TrimSpace(output) + "!"
+	})
+
+	formatted, err = f.Format("  Plain  ")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Plain!", formatted)
+
+	var buffer bytes.Buffer
+
+	assert.NoError(test, f.FormatWriter(&buffer, " Hi {p} ", "Bob"))
+	assert.Equal(test, "Hi Bob!", buffer.String())
+
+	buffer.Reset()
+	assert.True(test, errors.Is(f.FormatWriter(&buffer, "{p}", "too long output"), formatter.ErrOutputTooLarge))
+	assert.Empty(test, buffer.String())
+
+	_, err = f.Format("{if}")
+
+	assert.Error(test, err)
+	assert.Nil(test, f.SetOutputHook(nil).GetOutputHook())
+}
+
+func TestFormatterRedact(test *testing.T) {
+	credentials := Credentials{User: "bob", Password: "pass", Token: "token"}
+
+	formatted, err := formatter.Format("{p} {p0.User} {p0.Password} {p0.Token}", credentials)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "{bob *** sha256:3c469e9d} bob *** sha256:3c469e9d", formatted)
+
+	formatted, err = formatter.Format("{.Password} {p}", &credentials)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "*** &{bob *** sha256:3c469e9d}", formatted)
+
+	formatted, err = formatter.Format("Login {p1}", credentials, []Credentials{credentials})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Login [{bob *** sha256:3c469e9d}]", formatted)
+
+	f := formatter.New().Redact(reflect.TypeOf(Secret("")))
+
+	assert.True(test, f.IsRedacted(reflect.TypeOf(Secret(""))))
+	assert.False(test, formatter.New().IsRedacted(reflect.TypeOf(Secret(""))))
+
+	formatted, err = f.Format("{p} {p0 | printf \"%s\"} {name} {lazy} {p3}", Secret("value"), formatter.Arg("name", Secret("other")),
+		formatter.Args{"lazy": formatter.Lazy(func() interface{} { return Secret("lazy") })}, Secret("unused"))
+
+	assert.NoError(test, err)
+	assert.Equal(test, "*** *** *** *** ***", formatted)
+
+	formatted, err = formatter.Format("{p}", Secret("visible"))
+
+	assert.NoError(test, err)
+	assert.Equal(test, "visible", formatted)
+}
+
+func TestFormatterMissingHandler(test *testing.T) {
+	var names []string
+
+	f := formatter.New(formatter.WithMissingHandler(func(name string) (interface{}, bool) {
+		names = append(names, name)
+
+		switch name {
+		case "version":
+			return "1.2.3", true
+		case "build":
+			return formatter.Lazy(func() interface{} { return 42 }), true
+		default:
+			return nil, false
+		}
+	}))
+
+	assert.NotNil(test, f.GetMissingHandler())
+
+	formatted, err := f.Format("{app} {version} {version} {build} {other | fallback \"none\"}", formatter.Arg("app", "tool"))
+
+	assert.NoError(test, err)
+	assert.Equal(test, "tool 1.2.3 1.2.3 42 none", formatted)
+	assert.Equal(test, []string{"version", "build", "other"}, names)
+
+	_, err = f.Format("{unknown}")
+
+	var undefined *formatter.UndefinedFunctionError
+
+	assert.True(test, errors.As(err, &undefined), err)
+	assert.Equal(test, "unknown", undefined.Name)
+
+	formatted, err = f.FormatPartial("{version} {unknown}")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "1.2.3 {unknown}", formatted)
+
+	formatted, err = f.Format("{p1}", "a")
+
+	assert.Error(test, err)
+	assert.Empty(test, formatted)
+	assert.NotContains(test, names, "p1")
+}
+
+func TestFormatterResolver(test *testing.T) {
+	config := formatter.ResolverFunc(func(name string) (interface{}, bool) {
+		value, ok := map[string]interface{}{"host": "localhost", "port": 8080}[name]
+		return value, ok
+	})
+
+	override := formatter.ResolverFunc(func(name string) (interface{}, bool) {
+		if name == "host" {
+			return "example.com", true
+		}
+
+		return nil, false
+	})
+
+	f := formatter.New(formatter.WithResolver(config)).SetMissingHandler(func(name string) (interface{}, bool) {
+		return "handler", true
+	})
+
+	formatted, err := f.Format("{host}:{port} {other}")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "localhost:8080 handler", formatted)
+
+	formatted, err = f.Clone().ResetResolvers().AddResolver(override, config).Format("{host}:{port}", formatter.Named{"port": 80})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "example.com:80", formatted)
+	assert.Len(test, f.GetResolvers(), 1)
+
+	formatted, err = formatter.New().AddResolver(config).Format("{host} {missing | fallback \"none\"}")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "localhost none", formatted)
+}
+
+func TestFormatterEnvAllowlist(test *testing.T) {
+	test.Setenv("FORMATTER_APP_NAME", "tool")
+	test.Setenv("FORMATTER_SECRET", "secret")
+
+	f := formatter.New(formatter.WithEnvAllowlist("FORMATTER_APP_*"))
+
+	assert.Equal(test, []string{"FORMATTER_APP_*"}, f.GetEnvAllowlist())
+
+	formatted, err := f.Format(`{env.FORMATTER_APP_NAME} {env "FORMATTER_APP_NAME"} [{env "FORMATTER_SECRET"}] {expand "$FORMATTER_APP_NAME:$FORMATTER_SECRET"}`)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "tool tool [] tool:", formatted)
+
+	_, err = f.Format("{env.FORMATTER_SECRET}")
+
+	assert.Error(test, err)
+
+	formatted, err = f.Format(`{env.FORMATTER_SECRET | fallback "hidden"}`)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "hidden", formatted)
+
+	formatted, err = f.SetEnvAllowlist().Format(`{env "FORMATTER_SECRET"}`)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "secret", formatted)
+}
+
+func TestFormatterTemplates(test *testing.T) {
+	f := formatter.New().AddTemplate("signature", "--\n{company}{template \"footer\"}").AddTemplates(formatter.Templates{
+		"footer": " ({year})",
+		"broken": "{if}",
+	})
+
+	formatted, err := f.Format("Hello {name}\n{template \"signature\"}", formatter.Named{
+		"name":    "Bob",
+		"company": "ACME",
+		"year":    2020,
+	})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Hello Bob\n--\nACME (2020)", formatted)
+
+	formatted, err = f.Format(`{define "footer"}!{end}{template "signature"}`, formatter.Arg("company", "X"))
+
+	assert.NoError(test, err)
+	assert.Equal(test, "--\nX!", formatted)
+
+	_, err = f.Format(`{template "broken"}`)
+
+	assert.Error(test, err)
+
+	_, err = f.Format(`{template "unknown"}`)
+
+	assert.Error(test, err)
+
+	text, ok := f.GetTemplate("footer")
+
+	assert.True(test, ok)
+	assert.Equal(test, " ({year})", text)
+	assert.Len(test, f.GetTemplates(), 3)
+
+	_, ok = f.RemoveTemplate("footer").GetTemplate("footer")
+
+	assert.False(test, ok)
+}
+
+func TestFormatterFormatWithLayout(test *testing.T) {
+	f := formatter.New().AddTemplates(formatter.Templates{
+		"layout":  "{block \"header\" .}Hello {name}{end}\n{template \"body\"}\n{block \"footer\" .}Bye{end}",
+		"welcome": "Welcome {p0}!{define \"footer\"}Regards, {company}{end}",
+		"plain":   "Plain",
+	})
+
+	formatted, err := f.FormatWithLayout("layout", "welcome", "aboard", formatter.Named{"name": "Bob", "company": "ACME"})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Hello Bob\nWelcome aboard!\nRegards, ACME", formatted)
+
+	formatted, err = f.FormatWithLayout("layout", "plain", formatter.Named{"name": "Alice"})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Hello Alice\nPlain\nBye", formatted)
+
+	formatted, err = f.FormatWithLayout("plain", "welcome", "unused", formatter.Named{"company": "ACME"})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Plain unused", formatted)
+
+	_, err = f.FormatWithLayout("missing", "plain")
+
+	assert.Error(test, err)
+
+	_, err = f.FormatWithLayout("layout", "missing")
+
+	assert.Error(test, err)
+}
+
+func TestFormatterTrimMarkers(test *testing.T) {
+	formatted, err := formatter.New().SetDelimiters("<", ">").Format("a  <- p -> \n b", 1)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "a1b", formatted)
+}
+
+func TestFormatterTrimWhitespace(test *testing.T) {
+	f := formatter.New(formatter.WithTrimWhitespace())
+
+	assert.True(test, f.IsTrimWhitespace())
+
+	message := "Items:\n  {range items}\n  - {.}\n  {else}\n  none\n  {end}\nDone"
+
+	formatted, err := f.Format(message, formatter.Named{"items": []string{"a", "b"}})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Items:\n  - a\n  - b\nDone", formatted)
+
+	formatted, err = f.Format(message, formatter.Named{"items": []string{}})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Items:\n  none\nDone", formatted)
+
+	formatted, err = f.Format("{if ok}\n  yes {ok}\n{end}\n", formatter.Named{"ok": true})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "  yes true\n", formatted)
+
+	formatted, err = f.SetTrimWhitespace(false).Format("{if ok}\nyes\n{end}\n", formatter.Named{"ok": true})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "\nyes\n\n", formatted)
+}
+
+func TestFormatterComments(test *testing.T) {
+	message := "{# translator note: count is plural #}You have {count} messages{#\n second #}"
+
+	formatted, err := formatter.Format(message, formatter.Named{"count": 3})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "You have 3 messages", formatted)
+
+	assert.Equal(test, []formatter.Comment{
+		{Text: "translator note: count is plural", Location: ":1:0"},
+		{Text: "second", Location: ":1:63"},
+	}, formatter.Comments(message))
+
+	placeholders, err := formatter.Placeholders(message)
+
+	assert.NoError(test, err)
+	assert.Equal(test, []string{"count"}, placeholders)
+
+	formatted, err = formatter.New().SetDelimiters("<", ">").Format("a<# {p} #> <<#>> b", 1)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "a <#> b 1", formatted)
+
+	formatted, err = formatter.FormatPartial("{# note #}{name} {p}", 1)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "{name} 1", formatted)
+
+	_, err = formatter.Format("{# unclosed")
+
+	assert.Error(test, err)
+
+	assert.Empty(test, formatter.Comments("{{# not a comment #}}"))
+}
+
+func TestFormatterTable(test *testing.T) {
+	type row struct {
+		Name string
+		Age  int
+	}
+
+	rows := []row{{Name: "Bob", Age: 42}, {Name: "Alice", Age: 7}}
+
+	formatted, err := formatter.Format("Users:\n{table .Rows \"Name\" \"Age\"}", struct{ Rows []row }{Rows: rows})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Users:\n"+
+		"+-------+-----+\n"+
+		"| Name  | Age |\n"+
+		"+-------+-----+\n"+
+		"| Bob   | 42  |\n"+
+		"| Alice | 7   |\n"+
+		"+-------+-----+", formatted)
+
+	table, err := formatter.Table(nil, rows, formatter.TableOptions{
+		Style: formatter.UnicodeTable,
+		Align: []formatter.Alignment{formatter.AlignCenter, formatter.AlignRight},
+	})
+
+	assert.NoError(test, err)
+	assert.Equal(test, ""+
+		"┌───────┬─────┐\n"+
+		"│ Name  │ Age │\n"+
+		"├───────┼─────┤\n"+
+		"│  Bob  │  42 │\n"+
+		"│ Alice │   7 │\n"+
+		"└───────┴─────┘", table)
+
+	table, err = formatter.Table([]string{"Key", "Value"}, [][]interface{}{{"name", "Bob"}, {"description", "long text"}},
+		formatter.TableOptions{Style: formatter.MarkdownTable, MaxWidth: 6, Align: []formatter.Alignment{formatter.AlignLeft, formatter.AlignRight}})
+
+	assert.NoError(test, err)
+	assert.Equal(test, ""+
+		"| Key    |  Value |\n"+
+		"| :----- | -----: |\n"+
+		"| name   |    Bob |\n"+
+		"| descr… | long … |", table)
+
+	table, err = formatter.Table(nil, []map[string]interface{}{{"b": 2, "a": "x\ny"}, {"c": nil}}, formatter.TableOptions{})
+
+	assert.NoError(test, err)
+	assert.Equal(test, ""+
+		"+-----+---+---+\n"+
+		"| a   | b | c |\n"+
+		"+-----+---+---+\n"+
+		"| x y | 2 |   |\n"+
+		"|     |   |   |\n"+
+		"+-----+---+---+", table)
+
+	_, err = formatter.Table(nil, 3, formatter.TableOptions{})
+
+	assert.Error(test, err)
+
+	_, err = formatter.Table([]string{"Missing"}, rows, formatter.TableOptions{})
+
+	assert.Error(test, err)
+
+	f := formatter.New(formatter.WithTableOptions(formatter.TableOptions{Style: formatter.MarkdownTable}))
+
+	assert.Equal(test, formatter.MarkdownTable, f.GetTableOptions().Style)
+
+	formatted, err = f.Format("{table p}", rows)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "| Name  | Age |\n| :---- | :-- |\n| Bob   | 42  |\n| Alice | 7   |", formatted)
+}
+
+func TestFormatterLists(test *testing.T) {
+	items := []string{"a", "b", "c"}
+
+	formatted, err := formatter.Format("{p0 | joinAnd}, {p0 | joinOr}, {p1 | joinAnd}, {p2 | joinAnd}", items, []int{1, 2}, "x")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "a, b, and c, a, b, or c, 1 and 2, x", formatted)
+
+	formatted, err = formatter.New().SetLocale("de").Format("{p | joinAnd} / {p0 | joinOr}", items)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "a, b und c / a, b oder c", formatted)
+
+	formatted, err = formatter.Format("{p | join \"; \"}\n{p0 | bullets \"-\"}\n{p0 | numbered}", items)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "a; b; c\n- a\n- b\n- c\n1. a\n2. b\n3. c", formatted)
+
+	locale := *formatter.GetLocale("en")
+	locale.And, locale.SerialComma = "", false
+
+	formatter.RegisterLocale("xx-lists", &locale)
+
+	formatted, err = formatter.New().SetLocale("xx-lists").Format("{p | joinAnd}{joinAnd nil}", items)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "a, b and c", formatted)
+}
+
+func TestFormatterWrap(test *testing.T) {
+	text := "The quick brown fox jumps over the lazy dog\n\n  indented extraordinarily long words"
+
+	formatted, err := formatter.Format("{p0 | wrap 16}", text)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "The quick brown\nfox jumps over\nthe lazy dog\n\n  indented\n  extraordinarily\n  long words", formatted)
+
+	formatted, err = formatter.Format("{p0 | wrap 8}", "日本語 日本語 abc")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "日本語\n日本語\nabc", formatted)
+
+	formatted, err = formatter.Format("{p0 | wrap 0}", text)
+
+	assert.NoError(test, err)
+	assert.Equal(test, text, formatted)
+}
+
+func TestFormatterIndent(test *testing.T) {
+	formatted, err := formatter.Format("Description: {p0 | wrap 20 | hangingIndent 13}\n{p1 | indent 2}",
+		"one two three four five six seven", "a\n\nb")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Description: one two three four\n             five six seven\n  a\n\n  b", formatted)
+}
+
+func TestFormatterWidth(test *testing.T) {
+	formatted, err := formatter.Format("{p0 | width} {p1 | width} {p2 | width} {p3 | width} {p4 | width} {p5 | width}",
+		"abc", "日本語", "e\u0301", "👨\u200D👩\u200D👧", "🇵🇱", "❤\uFE0F")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "3 6 1 2 2 2", formatted)
+
+	formatted, err = formatter.Format("[{p0 | pad 6}] [{p0 | padLeft 6}] [{p0 | center 7}]", "日本")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "[日本  ] [  日本] [ 日本  ]", formatted)
+
+	f := formatter.New(formatter.WithWidthAlgorithm(formatter.RuneWidth))
+
+	assert.Equal(test, formatter.RuneWidth, f.GetWidthAlgorithm())
+
+	formatted, err = f.Format("{p0 | width} [{p0 | pad 4}]", "日本")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "2 [日本  ]", formatted)
+
+	formatted, err = f.SetWidthAlgorithm(formatter.ByteWidth).Format("{p0 | width}", "日本")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "6", formatted)
+}
+
+func TestFormatterTableWideCharacters(test *testing.T) {
+	table, err := formatter.Table([]string{"Name", "City"}, [][]string{{"山田", "東京"}, {"Bob", "Warsaw"}},
+		formatter.TableOptions{MaxWidth: 5})
+
+	assert.NoError(test, err)
+	assert.Equal(test, ""+
+		"+------+-------+\n"+
+		"| Name | City  |\n"+
+		"+------+-------+\n"+
+		"| 山田 | 東京  |\n"+
+		"| Bob  | Wa... |\n"+
+		"+------+-------+", table)
+}
+
+func TestFormatterBytes(test *testing.T) {
+	formatted, err := formatter.Format("{p0 | bytesIEC} {p0 | bytesSI} {p1 | bytesIEC} {p2 | bytesSI 2} {p3 | bytesIEC 0} {p4 | bytesIEC}",
+		1500000000, uint8(200), 1234567.0, -2048, 1048575)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "1.4 GiB 1.5 GB 200 B 1.23 MB -2 KiB 1.0 MiB", formatted)
+
+	_, err = formatter.Format("{p0 | bytesIEC}", "text")
+
+	assert.Error(test, err)
+
+	_, err = formatter.Format("{bytesSI 1 2 3}")
+
+	assert.Error(test, err)
+
+	assert.Equal(test, "1.5 KiB", formatter.ByteSize(1536).String())
+}
+
+func TestFormatterParseBytes(test *testing.T) {
+	for text, expected := range map[string]uint64{
+		"512":     512,
+		"512 B":   512,
+		"1.5 GB":  1500000000,
+		"1.5GiB":  1610612736,
+		"2 kb":    2000,
+		"2 KiB":   2048,
+		" 3 mi ":  3145728,
+		"0.5 EiB": 1 << 59,
+	} {
+		size, err := formatter.ParseBytes(text)
+
+		assert.NoError(test, err, text)
+		assert.Equal(test, expected, size, text)
+	}
+
+	for _, text := range []string{"", "GB", "1.5 XB", "1e3 B", "100 EB"} {
+		_, err := formatter.ParseBytes(text)
+
+		assert.Error(test, err, text)
+	}
+
+	var size formatter.ByteSize
+
+	assert.NoError(test, formatter.Scan("Size: {p}", "Size: 1.4 GiB", &size))
+	assert.Equal(test, formatter.ByteSize(1503238553), size)
+
+	assert.Error(test, formatter.Scan("Size: {p}", "Size: large", &size))
+}
+
+func TestFormatterOrdinal(test *testing.T) {
+	formatted, err := formatter.Format("{p0 | ordinal} {p1 | ordinal} {p2 | ordinal} {p3 | ordinal} {p4 | ordinal} {p5 | ordinal} {p6 | ordinal}",
+		1, 2, 3, 4, 11, 112, uint(101))
+
+	assert.NoError(test, err)
+	assert.Equal(test, "1st 2nd 3rd 4th 11th 112th 101st", formatted)
+
+	formatted, err = formatter.New().SetLocale("de").Format("{p0 | ordinal}", 3)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "3.", formatted)
+
+	_, err = formatter.Format("{p0 | ordinal}", 1.5)
+
+	assert.Error(test, err)
+}
+
+func TestFormatterSpell(test *testing.T) {
+	for number, expected := range map[int64]string{
+		0:             "zero",
+		7:             "seven",
+		42:            "forty-two",
+		-15:           "minus fifteen",
+		100:           "one hundred",
+		1001:          "one thousand one",
+		2_340_050:     "two million three hundred forty thousand fifty",
+		math.MinInt64: "minus nine quintillion two hundred twenty-three quadrillion three hundred seventy-two trillion thirty-six billion eight hundred fifty-four million seven hundred seventy-five thousand eight hundred eight",
+	} {
+		formatted, err := formatter.Format("{p0 | spell}", number)
+
+		assert.NoError(test, err)
+		assert.Equal(test, expected, formatted)
+	}
+
+	formatted, err := formatter.New().SetLocale("pl").Format("{p0 | spell} {p1 | spell}", 42, 3.0)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "42 3", formatted)
+
+	_, err = formatter.Format("{p0 | spell}", "42")
+
+	assert.Error(test, err)
+
+	_, err = formatter.Format("{p0 | spell}", uint64(math.MaxUint64))
+
+	assert.Error(test, err)
+}
+
+func TestFormatterNumericBases(test *testing.T) {
+	formatted, err := formatter.Format("{p0 | hex} {p0 | hex 4} {p0 | hexPrefix} {p0 | hexPrefix 8} {p0 | oct} {p0 | bin} {p1 | bin 8} {p2 | hex}",
+		255, uint8(5), -26)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "ff 00ff 0xff 0x000000ff 377 11111111 00000101 -1a", formatted)
+
+	formatted, err = formatter.Format("{p0 | bits} / {p1 | bits 12} / {p2 | bits} / {p3 | bits 6}", uint8(10), 0xABC, int16(-2), uint64(1<<63))
+
+	assert.NoError(test, err)
+	assert.Equal(test, "0000 1010 / 1010 1011 1100 / 1111 1111 1111 1110 / 00 0000", formatted)
+
+	_, err = formatter.Format("{p0 | hex}", 1.5)
+
+	assert.Error(test, err)
+
+	_, err = formatter.Format("{p0 | bits \"x\"}", 1)
+
+	assert.Error(test, err)
+
+	_, err = formatter.Format("{hex}")
+
+	assert.Error(test, err)
+}
+
+func TestFormatterPlural(test *testing.T) {
+	message := "{count} {count | plural \"file\" \"files\"}, {plural p0 \"item\" \"items\"}"
+
+	formatted, err := formatter.Format(message, 1, formatter.Named{"count": 1})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "1 file, item", formatted)
+
+	formatted, err = formatter.Format(message, 3, formatter.Named{"count": 0})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "0 files, items", formatted)
+
+	f := formatter.New().SetLocale("pl")
+
+	for count, expected := range map[int]string{1: "1 plik", 3: "3 pliki", 5: "5 plików", 22: "22 pliki", 12: "12 plików"} {
+		formatted, err = f.Format("{p0} {p0 | plural \"plik\" \"pliki\" \"plików\"}", count)
+
+		assert.NoError(test, err)
+		assert.Equal(test, expected, formatted)
+	}
+
+	formatted, err = f.Format("{p0 | plural \"plik\" \"pliki\"}", 5)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "pliki", formatted)
+
+	_, err = formatter.Format("{p0 | plural}", 1)
+
+	assert.Error(test, err)
+
+	_, err = formatter.Format("{p0 | plural \"a\" \"b\"}", "many")
+
+	assert.Error(test, err)
+
+	_, err = formatter.Format("{p0 | plural 1 \"b\"}", 2)
+
+	assert.Error(test, err)
+}
+
+func TestFormatterCaseConversion(test *testing.T) {
+	for text, expected := range map[string]string{
+		"user name":         "userName userName UserName user_name user-name USER_NAME User name",
+		"HTTPServer2Error":  "httpServer2Error httpServer2Error HttpServer2Error http_server2_error http-server2-error HTTP_SERVER2_ERROR Http server2 error",
+		"  --already_snake": "alreadySnake alreadySnake AlreadySnake already_snake already-snake ALREADY_SNAKE Already snake",
+		"":                  "      ",
+	} {
+		formatted, err := formatter.Format("{p0 | camelCase} {camelCase p0} {p0 | pascalCase} {p0 | snakeCase} "+
+			"{p0 | kebabCase} {p0 | screamingSnakeCase} {p0 | sentenceCase}", text)
+
+		assert.NoError(test, err)
+		assert.Equal(test, expected, formatted)
+	}
+}
+
+func TestFormatterJSON(test *testing.T) {
+	payload := map[string]interface{}{"id": 3, "tags": []string{"a", "<b>"}}
+
+	formatted, err := formatter.Format("Payload {p0 | json}", payload)
+
+	assert.NoError(test, err)
+	assert.Equal(test, `Payload {"id":3,"tags":["a","<b>"]}`, formatted)
+
+	formatted, err = formatter.Format("{p0 | jsonIndent}\n{p1 | jsonIndent \"\\t\"}", payload, []int{1})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "{\n  \"id\": 3,\n  \"tags\": [\n    \"a\",\n    \"<b>\"\n  ]\n}\n[\n\t1\n]", formatted)
+
+	_, err = formatter.Format("{p0 | json}", func() {})
+
+	assert.Error(test, err)
+
+	_, err = formatter.Format("{p0 | jsonIndent 2}", 1)
+
+	assert.Error(test, err)
+}
+
+func TestFormatterJSONRedact(test *testing.T) {
+	credentials := &Credentials{User: "bob", Password: "secret", Token: "token"}
+
+	formatted, err := formatter.New().Redact(reflect.TypeOf(Secret(""))).Format("{p0 | json} {p1 | json}",
+		[]*Credentials{credentials}, map[string]interface{}{"key": Secret("value")})
+
+	assert.NoError(test, err)
+	assert.Equal(test, `[{"User":"bob","Password":"***","Token":"sha256:3c469e9d"}] {"key":"***"}`, formatted)
+	assert.Equal(test, "secret", credentials.Password)
+}
+
+func TestFormatterYAML(test *testing.T) {
+	type Server struct {
+		Host  string   `yaml:"host"`
+		Ports []int    `yaml:"ports"`
+		Tags  []string `yaml:"tags,omitempty"`
+	}
+
+	formatted, err := formatter.Format("server:\n{p0 | yaml | indent 2}", Server{Host: "localhost", Ports: []int{80, 443}})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "server:\n  host: localhost\n  ports:\n  - 80\n  - 443", formatted)
+
+	formatted, err = formatter.Format("{p0 | yaml}", &Credentials{User: "bob", Password: "secret"})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "user: bob\npassword: '***'\ntoken: sha256:e3b0c442", formatted)
+
+	_, err = formatter.Format("{p0 | yaml}", func() {})
+
+	assert.Error(test, err)
+}
+
+func TestFormatterTOML(test *testing.T) {
+	formatted, err := formatter.Format("{p0 | toml}", map[string]interface{}{
+		"name":   "app",
+		"server": map[string]interface{}{"port": 8080},
+	})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "name = \"app\"\n\n[server]\n  port = 8080", formatted)
+
+	formatted, err = formatter.Format("{p0 | toml}", Credentials{User: "bob", Password: "secret"})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "User = \"bob\"\nPassword = \"***\"\nToken = \"sha256:e3b0c442\"", formatted)
+
+	_, err = formatter.Format("{p0 | toml}", []int{1})
+
+	assert.Error(test, err)
+}
+
+func TestFormatterLogfmt(test *testing.T) {
+	fields := formatter.Named{
+		"user":    "bob",
+		"message": "access denied",
+		"code":    403,
+		"empty":   "",
+		"nil":     nil,
+		"request": map[string]interface{}{"path": "/a b", "id": 7},
+	}
+
+	formatted, err := formatter.Format("Access denied for {user} {p0 | logfmt}", fields)
+
+	assert.NoError(test, err)
+	assert.Equal(test, `Access denied for bob code=403 empty="" message="access denied" nil=null `+
+		`request.id=7 request.path="/a b" user=bob`, formatted)
+
+	formatted, err = formatter.Format("{. | logfmt}", &Credentials{User: "bob", Password: "secret"})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Password=*** Token=sha256:e3b0c442 User=bob", formatted)
+
+	formatted, err = formatter.Format("{fields | logfmt}", formatter.Named{
+		"fields": map[string]interface{}{"at": time.Duration(0), "a=b": `"q"`},
+	})
+
+	assert.NoError(test, err)
+	assert.Equal(test, `a_b="\"q\"" at=0s`, formatted)
+
+	_, err = formatter.Format("{p0 | logfmt}", []int{1})
+
+	assert.Error(test, err)
+
+	_, err = formatter.Format("{logfmt nil}")
+
+	assert.Error(test, err)
+}
+
+func TestFormatterDump(test *testing.T) {
+	type Node struct {
+		Name     string
+		Tags     []string
+		Meta     map[string]interface{}
+		Next     *Node
+		Elapsed  time.Duration
+		Password string `format:"redact"`
+		hidden   int
+	}
+
+	node := &Node{Name: "a", Tags: []string{"x"}, Meta: map[string]interface{}{"b": 2, "a": nil}, Elapsed: time.Second, hidden: 3}
+	node.Next = node
+
+	formatted, err := formatter.Format("{p0 | dump}", node)
+
+	assert.NoError(test, err)
+	assert.Equal(test, `&formatter_test.Node{
+  Name: "a",
+  Tags: []string{
+    "x",
+  },
+  Meta: map[string]interface {}{
+    "a": nil,
+    "b": 2,
+  },
+  Next: <cycle *formatter_test.Node>,
+  Elapsed: 1s,
+  Password: "***",
+  hidden: 3,
+}`, formatted)
+
+	f := formatter.New(formatter.WithDumpOptions(formatter.DumpOptions{MaxDepth: 1, MaxElements: 2, Indent: "\t"}))
+
+	assert.Equal(test, 2, f.GetDumpOptions().MaxElements)
+
+	formatted, err = f.Format("{p0 | dump}", [][]int{{1}, {2}, {3}, {4}})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "[][]int{\n\t[]int{...},\n\t[]int{...},\n\t... 2 more\n}", formatted)
+
+	formatted, err = formatter.Format("{p0 | dump} {p1 | dump} {p2 | dump} {dump nil}", []int{}, (*Node)(nil), map[string]int(nil))
+
+	assert.NoError(test, err)
+	assert.Equal(test, "[]int{} (*formatter_test.Node)(nil) map[string]int(nil) nil", formatted)
+}
+
+func TestFormatterDiff(test *testing.T) {
+	formatted, err := formatter.Format("{diff p0 p1}", "a\nb\nc\nd\ne\nf\ng\nh\ni\nj", "a\nb\nc\nd\ne\nF\ng\nh\ni\nj\nk")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "@@ -3,8 +3,9 @@\n c\n d\n e\n-f\n+F\n g\n h\n i\n j\n+k", formatted)
+
+	formatted, err = formatter.Format("{diff p0 p1 \"inline\"}", "the quick fox", "the slow brown fox")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "the [-quick-]{+slow brown+} fox", formatted)
+
+	formatted, err = formatter.Format("{diff p0 p1 \"inline\" \"color\"}", "a b", "a c")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "a \033[31m[-b-]\033[0m\033[32m{+c+}\033[0m", formatted)
+
+	formatted, err = formatter.New().SetColorMode(formatter.ColorNever).Format("{diff p0 p1 \"color\"}", []int{1, 2}, []int{1, 3})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "@@ -1,4 +1,4 @@\n []int{\n   1,\n-  2,\n+  3,\n }", formatted)
+
+	formatted, err = formatter.Format("{diff p0 p0}", "same")
+
+	assert.NoError(test, err)
+	assert.Empty(test, formatted)
+}
+
+func TestFormatterMarkdown(test *testing.T) {
+	formatted, err := formatter.Format("{mdBold p0} {mdCode p1} {mdLink p2 p3} {p4 | mdEscape}",
+		"a*b", "x`y", "docs [v1]", "https://example.com/a b(1)", "_init_")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "**a\\*b** ``x`y`` [docs \\[v1\\]](https://example.com/a%20b%281%29) \\_init\\_", formatted)
+
+	f := formatter.New(formatter.WithSafeMarkdown())
+
+	assert.True(test, f.IsSafeMarkdown())
+
+	formatted, err = f.Format("User {p0} posted {mdBold p1}", "*bob*", "#1", "[extra]")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "User \\*bob\\* posted **\\#1** \\[extra\\]", formatted)
+
+	formatted, err = f.Format("{mdTable p0}", []formatter.Named{{"Name": "a|b"}})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "| Name |\n| :--- |\n| a\\|b |", formatted)
+}
+
+func TestFormatterEscaping(test *testing.T) {
+	formatted, err := formatter.Format("<a title=\"{p0 | xmlEscape}\"/> ?q={p1 | urlQuery} /{p2 | urlPath} {p3 | shellQuote} {p4 | shellQuote} {p5 | shellQuote}",
+		`"a" & <b>`, "a&b c", "a/b c", "it's here", "safe/path-1.txt", "")
+
+	assert.NoError(test, err)
+	assert.Equal(test, `<a title="&#34;a&#34; &amp; &lt;b&gt;"/> ?q=a%26b+c /a%2Fb%20c 'it'\''s here' safe/path-1.txt ''`, formatted)
+}
+
+func TestFormatterNilText(test *testing.T) {
+	var person *Person
+
+	f := formatter.New(formatter.WithNilText("n/a"), formatter.WithZeroRenderer(reflect.TypeOf(""), func(interface{}) string {
+		return "-"
+	}))
+
+	assert.Equal(test, "n/a", f.GetNilText())
+	assert.NotNil(test, f.GetZeroRenderer(reflect.TypeOf("")))
+
+	formatted, err := f.Format("{p0} {p1} {p2} {p3} {p4}", nil, person, error(nil), "", "text", 0)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "n/a n/a n/a - text 0", formatted)
+
+	formatted, err = f.SetZeroRenderer(reflect.TypeOf(""), nil).Format("[{p0}]", "")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "[]", formatted)
+	assert.Nil(test, f.GetZeroRenderer(reflect.TypeOf("")))
+}
+
+func TestFormatterBool(test *testing.T) {
+	f := formatter.New(formatter.WithBoolStrings("yes", "no"))
+
+	trueText, falseText := f.GetBoolStrings()
+
+	assert.Equal(test, "yes", trueText)
+	assert.Equal(test, "no", falseText)
+
+	formatted, err := f.Format("{p0} {p1}", true, false)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "yes no", formatted)
+
+	formatted, err = formatter.New().SetLocale("pl").Format("{p0 | yesno} {p1 | onoff} {p2 | yesno}", true, false, "")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "tak wył. nie", formatted)
+
+	formatted, err = formatter.Format("{p0} {p1 | yesno} {p2 | onoff}", true, 1, []int{})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "true yes off", formatted)
+}
+
+type stackError struct {
+	message string
+}
+
+func (e *stackError) Error() string {
+	return e.message
+}
+
+func (e *stackError) StackTrace() []string {
+	return []string{"main.run", "main.main"}
+}
+
+func TestFormatterErrorChain(test *testing.T) {
+	root := &stackError{message: "connection refused"}
+	err := fmt.Errorf("load config: %w", fmt.Errorf("read file: %w", root))
+
+	formatted, e := formatter.Format("{p0} | {p0 | errorChain}", err)
+
+	assert.NoError(test, e)
+	assert.Equal(test, "load config: read file: connection refused | load config: read file: connection refused", formatted)
+
+	formatted, e = formatter.Format(`{p0 | errorChain "list"}`, err)
+
+	assert.NoError(test, e)
+	assert.Equal(test, "load config\n  read file\n    connection refused", formatted)
+
+	formatted, e = formatter.Format(`{p0 | errorChain "list" "stack"}`, err)
+
+	assert.NoError(test, e)
+	assert.Equal(test, "load config\n  read file\n    connection refused\n      [main.run main.main]", formatted)
+
+	formatted, e = formatter.Format("{p0}", fmt.Errorf("sync: %w", errors.Join(errors.New("a"), errors.New("b"))))
+
+	assert.NoError(test, e)
+	assert.Equal(test, "sync: a; b", formatted)
+
+	_, e = formatter.Format(`{p0 | errorChain "tree"}`, err)
+
+	assert.Error(test, e)
+}
+
+func TestFormatterStack(test *testing.T) {
+	formatted, err := formatter.Format("{stack 1}")
+
+	assert.NoError(test, err)
+	assert.True(test, strings.HasPrefix(formatted, "gitlab.com/tymonx/go-formatter/formatter_test.TestFormatterStack\n\t"))
+	assert.True(test, strings.HasSuffix(formatted, " more"))
+	assert.Equal(test, 2, strings.Count(formatted, "\n"))
+
+	formatted, err = formatter.Format("{p0 | stack}", formatter.CaptureStack(0))
+
+	assert.NoError(test, err)
+	assert.True(test, strings.HasPrefix(formatted, "gitlab.com/tymonx/go-formatter/formatter_test.TestFormatterStack\n\t"))
+
+	f := formatter.New(formatter.WithStackOptions(formatter.StackOptions{Filter: func(frame runtime.Frame) bool {
+		return strings.HasPrefix(frame.Function, "testing.")
+	}}))
+
+	assert.NotNil(test, f.GetStackOptions().Filter)
+
+	formatted, err = f.Format("{stack}")
+
+	assert.NoError(test, err)
+	assert.True(test, strings.HasPrefix(formatted, "testing.tRunner\n\t"))
+
+	formatted, err = formatter.Format("{p0 | stack} {p1 | stack}", &stackError{message: "failed"}, nil)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "[main.run main.main] ", formatted)
+
+	_, err = formatter.Format("{p0 | stack}", "text")
+
+	assert.Error(test, err)
+}
+
+type verbose struct{}
+
+func (verbose) Format(state fmt.State, verb rune) {
+	if state.Flag('+') {
+		_, _ = state.Write([]byte("details"))
+	} else {
+		_, _ = state.Write([]byte("summary"))
+	}
+}
+
+type goPoint struct {
+	X, Y int
+}
+
+func (p goPoint) GoString() string {
+	return fmt.Sprintf("Point(%d, %d)", p.X, p.Y)
+}
+
+func TestFormatterFmtVerb(test *testing.T) {
+	date := time.Date(2020, time.March, 4, 15, 30, 0, 0, time.UTC)
+
+	formatted, err := formatter.Format("{p0} {p1} {p2}", verbose{}, goPoint{1, 2}, date)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "summary {1 2} 2020-03-04 15:30:00 +0000 UTC", formatted)
+
+	f := formatter.New(formatter.WithFmtVerb("+v"))
+
+	assert.Equal(test, "%+v", f.GetFmtVerb())
+
+	formatted, err = f.Format("{p0} {p1} {p2}", verbose{}, goPoint{1, 2}, date)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "details {1 2} 2020-03-04T15:30:00Z", formatted)
+
+	formatted, err = f.SetFmtVerb("%#v").Format("{p0}", goPoint{1, 2})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Point(1, 2)", formatted)
+}
+
+type level int
+
+func (l level) String() string {
+	return [...]string{"debug", "info"}[l]
+}
+
+func TestFormatterUseStringer(test *testing.T) {
+	formatted, err := formatter.Format("{p0} {p0 | raw} {p1 | raw}", level(1), Money(12345))
+
+	assert.NoError(test, err)
+	assert.Equal(test, "info 1 12345", formatted)
+
+	f := formatter.New(formatter.WithUseStringer(false))
+
+	assert.False(test, f.IsUseStringer())
+
+	formatted, err = f.Format("{p0} {p1}", level(1), time.Second)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "1 1000000000", formatted)
+	assert.True(test, f.SetUseStringer(true).IsUseStringer())
+}
+
+func TestFormatterPythonStyle(test *testing.T) {
+	f := formatter.New(formatter.WithPlaceholderStyle(formatter.PythonStyle))
+
+	assert.Equal(test, formatter.PythonStyle, f.GetPlaceholderStyle())
+
+	formatted, err := f.Format("{1} {0.Name | upper} {- 1 -} {printf \"%03d\" 7}", Person{Name: "bob"}, "x")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "x BOBx007", formatted)
+
+	formatted, err = f.SetPlaceholder("arg").Format("{} and {}", "a", "b")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "a and b", formatted)
+
+	formatted, err = formatter.Format("{0}", "a")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "0 a", formatted)
+}
+
+func TestFormatterStrictPlaceholders(test *testing.T) {
+	f := formatter.New(formatter.WithStrictPlaceholders())
+
+	assert.True(test, f.IsStrictPlaceholders())
+
+	_, err := f.Format("{p} {p0}", 1)
+
+	assert.True(test, errors.Is(err, formatter.ErrMixedPlaceholders))
+	assert.EqualError(test, err, `automatic and positional placeholders are mixed: "p" at :1:1 and "p0" at :1:5`)
+
+	formatted, err := f.Format("{p} {p}", 1, 2)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "1 2", formatted)
+
+	formatted, err = f.SetStrictPlaceholders(false).Format("{p} {p0}", 1)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "1 1", formatted)
+}
+
+func TestFormatterConflictPolicy(test *testing.T) {
+	first := formatter.Named{"user": "bob", "id": 1}
+	second := formatter.Named{"user": "alice"}
+
+	assert.Equal(test, []string{"id", "user"}, formatter.Conflicts(first, second, formatter.Arg("id", 2), formatter.Arg("id", 3)))
+	assert.Empty(test, formatter.Conflicts(first, "text", 3))
+
+	formatted, err := formatter.Format("{user}", first, second)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "alice", formatted)
+
+	f := formatter.New(formatter.WithConflictPolicy(formatter.FirstWins))
+
+	assert.Equal(test, formatter.FirstWins, f.GetConflictPolicy())
+
+	formatted, err = f.Format("{user}", first, second)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "bob", formatted)
+
+	_, err = f.SetConflictPolicy(formatter.ConflictError).Format("{user}", first, second)
+
+	assert.True(test, errors.Is(err, formatter.ErrNameConflict))
+	assert.EqualError(test, err, "placeholder names defined by many arguments: user")
+}
+
+func TestFormatterUnusedSeparator(test *testing.T) {
+	f := formatter.New(formatter.WithUnusedPrefix(" | extra: "), formatter.WithUnusedSeparator(", "))
+
+	assert.Equal(test, " | extra: ", f.GetUnusedPrefix())
+	assert.Equal(test, ", ", f.GetUnusedSeparator())
+
+	formatted, err := f.Format("Request {p0}", "/users", 404, map[int]string{3: "c", 1: "a", 2: "b"})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Request /users | extra: 404, map[1:a 2:b 3:c]", formatted)
+
+	formatted, err = f.Format("No placeholders", 1)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "No placeholders | extra: 1", formatted)
+
+	formatted, err = f.Format("All used {p}", 1)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "All used 1", formatted)
+}
+
+func TestFormatterExplain(test *testing.T) {
+	explanation, err := formatter.Explain("{p} {p1} {user} {.Name} {missing | fallback \"-\"}",
+		"first", "second", formatter.Named{"user": "bob"}, Person{Name: "alice"}, 42)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "first second bob alice - 42", explanation.Output)
+	assert.Len(test, explanation.Placeholders, 5)
+	assert.Equal(test, 0, explanation.Placeholders[0].Argument)
+	assert.Equal(test, 1, explanation.Placeholders[1].Argument)
+	assert.Equal(test, 2, explanation.Placeholders[2].Argument)
+	assert.Equal(test, "user", explanation.Placeholders[2].Key)
+	assert.Equal(test, "bob", explanation.Placeholders[2].Value)
+	assert.Equal(test, 3, explanation.Placeholders[3].Argument)
+	assert.Equal(test, "alice", explanation.Placeholders[3].Value)
+	assert.Equal(test, -1, explanation.Placeholders[4].Argument)
+	assert.Equal(test, []int{4}, explanation.Unused)
+	assert.Equal(test, `{p} at :1:1 from argument 0: "first"
+{p1} at :1:5 from argument 1: "second"
+{user} at :1:10 from argument 2 key "user": "bob"
+{.Name} at :1:17 from argument 3: "alice"
+{missing} at :1:25 not resolved
+unused arguments: 4`, explanation.String())
+
+	_, err = formatter.Explain("{if}")
+
+	assert.Error(test, err)
+}
+
+func TestFormatterRequired(test *testing.T) {
+	requirements, err := formatter.Required(`{p} {p} {p3} {account.Name} {.Email} {city | fallback "-"} {if p1}{name}{end}`)
+
+	assert.NoError(test, err)
+	assert.Equal(test, []int{0, 1, 3}, requirements.Positions)
+	assert.Equal(test, []string{"account", "name"}, requirements.Names)
+	assert.Equal(test, []string{"city"}, requirements.Optional)
+	assert.Equal(test, []string{".Email"}, requirements.Fields)
+
+	requirements, err = formatter.Required("Plain")
+
+	assert.NoError(test, err)
+	assert.Empty(test, requirements.Positions)
+	assert.Empty(test, requirements.Names)
+
+	_, err = formatter.Required("{if}")
+
+	assert.Error(test, err)
+}
+
+type metricsAccount struct {
+	Name string
+	Key  string `format:"redact"`
+}
+
+type recordingMetrics struct {
+	formats     int
+	sizes       []int
+	parseErrors int
+	hits        map[string]int
+	misses      map[string]int
+}
+
+func (m *recordingMetrics) FormatDuration(duration time.Duration) {
+	if duration >= 0 {
+		m.formats++
+	}
+}
+
+func (m *recordingMetrics) OutputSize(size int) {
+	m.sizes = append(m.sizes, size)
+}
+
+func (m *recordingMetrics) ParseError(err error) {
+	m.parseErrors++
+}
+
+func (m *recordingMetrics) CacheHit(cache string) {
+	m.hits[cache]++
+}
+
+func (m *recordingMetrics) CacheMiss(cache string) {
+	m.misses[cache]++
+}
+
+func TestFormatterMetrics(test *testing.T) {
+	metrics := &recordingMetrics{hits: map[string]int{}, misses: map[string]int{}}
+	f := formatter.New(formatter.WithMetrics(metrics))
+
+	assert.Equal(test, metrics, f.GetMetrics())
+
+	formatted, err := f.Format("Plain")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Plain", formatted)
+
+	formatted, err = f.Format("{p}", metricsAccount{Name: "bob", Key: "secret"})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "{bob "+formatter.RedactedText+"}", formatted)
+
+	_, err = f.Format("{p}", metricsAccount{Name: "bob"})
+
+	assert.NoError(test, err)
+
+	_, err = f.Format("{if}")
+
+	assert.Error(test, err)
+
+	_, err = f.FormatPartial("{p} {missing}", 1)
+
+	assert.NoError(test, err)
+
+	assert.Equal(test, 5, metrics.formats)
+	assert.Equal(test, []int{5, 9, 9, 0, 11}, metrics.sizes)
+	assert.Equal(test, 1, metrics.parseErrors)
+	assert.Equal(test, 1, metrics.misses[formatter.RedactionCache])
+	assert.True(test, metrics.hits[formatter.RedactionCache] > 0)
+
+	assert.Nil(test, f.SetMetrics(nil).GetMetrics())
+}
+
+type recordingSpan struct {
+	name       string
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+func (s *recordingSpan) SetAttribute(key string, value interface{}) {
+	s.attributes[key] = value
+}
+
+func (s *recordingSpan) End(err error) {
+	s.err, s.ended = err, true
+}
+
+type recordingTracer struct {
+	spans []*recordingSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, formatter.Span) {
+	span := &recordingSpan{name: name, attributes: map[string]interface{}{}}
+	t.spans = append(t.spans, span)
+
+	return ctx, span
+}
+
+func TestFormatterTracer(test *testing.T) {
+	tracer := &recordingTracer{}
+	f := formatter.New(formatter.WithTracer(tracer))
+
+	assert.Equal(test, tracer, f.GetTracer())
+
+	formatted, err := f.Format("Hello {p | upper}!", "Bob")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Hello BOB!", formatted)
+	assert.Len(test, tracer.spans, 2)
+
+	key := formatter.TemplateKey("Hello {p | upper}!")
+
+	assert.Equal(test, formatter.ParseSpan, tracer.spans[0].name)
+	assert.Equal(test, key, tracer.spans[0].attributes[formatter.TemplateKeyAttribute])
+	assert.True(test, tracer.spans[0].ended)
+	assert.NoError(test, tracer.spans[0].err)
+
+	assert.Equal(test, formatter.ExecuteSpan, tracer.spans[1].name)
+	assert.Equal(test, key, tracer.spans[1].attributes[formatter.TemplateKeyAttribute])
+	assert.Equal(test, 10, tracer.spans[1].attributes[formatter.OutputSizeAttribute])
+	assert.True(test, tracer.spans[1].ended)
+
+	tracer.spans = nil
+
+	_, err = f.Format("{if}")
+
+	assert.Error(test, err)
+	assert.Len(test, tracer.spans, 1)
+	assert.Equal(test, err, tracer.spans[0].err)
+
+	tracer.spans = nil
+
+	_, err = f.Format("{p0 | date}", "text")
+
+	assert.Error(test, err)
+	assert.Len(test, tracer.spans, 2)
+	assert.Error(test, tracer.spans[1].err)
+
+	tracer.spans = nil
+
+	_, err = f.Format("Hello {p}!", "Bob")
+
+	assert.NoError(test, err)
+	assert.Len(test, tracer.spans, 1)
+	assert.Equal(test, formatter.ExecuteSpan, tracer.spans[0].name)
+
+	tracer.spans = nil
+
+	formatted, err = f.Format("Plain")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Plain", formatted)
+	assert.Empty(test, tracer.spans)
+
+	assert.NotEqual(test, formatter.TemplateKey("a"), formatter.TemplateKey("b"))
+	assert.Nil(test, f.SetTracer(nil).GetTracer())
+}
+
+func TestFormatterEngine(test *testing.T) {
+	f := formatter.New(formatter.WithEngine(formatter.SubstitutionEngine{}))
+
+	assert.Equal(test, formatter.SubstitutionEngine{}, f.GetEngine())
+
+	formatted, err := f.Format(`{p} has {p1 | printf "%03d"} {p2 | upper} {.Name} {missing | fallback "-"}{# note #}`,
+		"Bob", 7, "items", struct{ Name string }{Name: "x"})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Bob has 007 ITEMS x -", formatted)
+
+	_, err = f.Format("{if p}yes{end}", true)
+
+	assert.True(test, errors.Is(err, formatter.ErrUnsupportedNode))
+
+	_, err = f.Format("{p | date}", "text")
+
+	assert.Error(test, err)
+
+	formatted, err = formatter.New().SetSafeHTML(true).Format("<b>{p}</b>", "<i>")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "<b>&lt;i&gt;</b>", formatted)
+
+	formatted, err = formatter.New(formatter.WithEngine(formatter.TextEngine{})).SetSafeHTML(true).Format("<b>{p}</b>", "<i>")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "<b><i></b>", formatted)
+
+	formatted, err = formatter.New(formatter.WithEngine(formatter.HTMLEngine{})).Format("<b>{p}</b>", "<i>")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "<b>&lt;i&gt;</b>", formatted)
+
+	assert.Nil(test, f.SetEngine(nil).GetEngine())
+}
+
+func TestFormatterSimpleMessages(test *testing.T) {
+	number := 3
+	var empty *int
+
+	arguments := []interface{}{"Bob", &number, empty, nil, errors.New("failed"), formatter.Named{"name": "Alice"}, true}
+
+	for _, message := range []string{
+		"{p} {p} {p} {p} {p} {p}",
+		"{ p0 } {p1} {p2} {p3} {p4} {p6} {name}",
+		"{{p0}} {# comment #} {p0}}} {name}",
+		"{p0}",
+		"{p0 | upper}",
+		"{.Name}",
+	} {
+		expected, expectedErr := formatter.New(formatter.WithEngine(formatter.TextEngine{})).Format(message, arguments...)
+		formatted, err := formatter.Format(message, arguments...)
+
+		assert.Equal(test, expectedErr, err, message)
+		assert.Equal(test, expected, formatted, message)
+	}
+
+	formatted, err := formatter.Format("{p} {p1}", "a", "b", "c")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "a b c", formatted)
+
+	_, err = formatter.New(formatter.WithStrict()).Format("{p0}", "a", "b")
+
+	assert.Error(test, err)
+}
+
+type cachedAccount struct {
+	Name  string
+	Token string `format:"redact"`
+}
+
+func TestFormatterFieldCache(test *testing.T) {
+	metrics := &recordingMetrics{hits: map[string]int{}, misses: map[string]int{}}
+	f := formatter.New(formatter.WithMetrics(metrics))
+
+	for _, name := range []string{"Bob", "Alice"} {
+		formatted, err := f.Format("{.Name} {.Token} {account.Name}", cachedAccount{Name: name, Token: "secret"},
+			formatter.Named{"account": &cachedAccount{Name: name}})
+
+		assert.NoError(test, err)
+		assert.Equal(test, name+" "+formatter.RedactedText+" "+name, formatted)
+	}
+
+	assert.Equal(test, 2, metrics.misses[formatter.FieldCache])
+	assert.Equal(test, 4, metrics.hits[formatter.FieldCache])
+
+	_, err := f.Format("{.Missing}", cachedAccount{})
+
+	assert.Error(test, err)
+
+	_, err = f.Format("{.Missing}", cachedAccount{})
+
+	assert.Error(test, err)
+	assert.Equal(test, 3, metrics.misses[formatter.FieldCache])
+}
+
+type recordingWriter struct {
+	writes       int
+	stringWrites int
+	output       strings.Builder
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.writes++
+	return w.output.Write(data)
+}
+
+func (w *recordingWriter) WriteString(text string) (int, error) {
+	w.stringWrites++
+	return w.output.WriteString(text)
+}
+
+func TestFormatterWriteBuffer(test *testing.T) {
+	f := formatter.New()
+
+	assert.Equal(test, formatter.DefaultWriteBufferSize, f.GetWriteBufferSize())
+
+	writer := &recordingWriter{}
+
+	assert.NoError(test, f.FormatWriter(writer, "{p | upper} and {p | lower}!", "A", "B"))
+	assert.Equal(test, "A and b!", writer.output.String())
+	assert.Equal(test, 1, writer.writes+writer.stringWrites)
+
+	writer = &recordingWriter{}
+
+	assert.NoError(test, formatter.New(formatter.WithWriteBufferSize(0)).FormatWriter(writer, "Plain"))
+	assert.Equal(test, "Plain", writer.output.String())
+	assert.Equal(test, 0, writer.writes)
+	assert.Equal(test, 1, writer.stringWrites)
+
+	writer = &recordingWriter{}
+
+	assert.NoError(test, formatter.New(formatter.WithWriteBufferSize(0)).FormatWriter(writer, "{p | upper} and {p | lower}!", "A", "B"))
+	assert.Equal(test, "A and b!", writer.output.String())
+	assert.True(test, writer.writes+writer.stringWrites > 1)
+
+	writer = &recordingWriter{}
+
+	assert.NoError(test, formatter.New(formatter.WithWriteBufferSize(4)).FormatWriter(writer, "{p} and {p}!", "AAAA", "BBBB"))
+	assert.Equal(test, "AAAA and BBBB!", writer.output.String())
+	assert.Equal(test, 4, f.SetWriteBufferSize(4).GetWriteBufferSize())
+}
+
+func TestFormatterFormatAll(test *testing.T) {
+	messages := []formatter.Message{
+		{Text: "Hello {p}!", Arguments: []interface{}{"Bob"}},
+		{Text: "{p | upper}", Arguments: []interface{}{"x"}},
+		{Text: "{if}"},
+		{Text: "Plain"},
+	}
+
+	formatted, err := formatter.FormatAll(messages)
+
+	var messageError *formatter.MessageError
+
+	assert.Equal(test, []string{"Hello Bob!", "X", "", "Plain"}, formatted)
+	assert.True(test, errors.As(err, &messageError))
+	assert.Equal(test, 2, messageError.Index)
+	assert.Error(test, messageError.Unwrap())
+	assert.Contains(test, err.Error(), "message 2: ")
+
+	messages = nil
+
+	for index := 0; index < 100; index++ {
+		messages = append(messages, formatter.Message{Text: "{p} {name}", Arguments: []interface{}{index, formatter.Named{"name": "x"}}})
+	}
+
+	f := formatter.New(formatter.WithBatchWorkers(0))
+
+	assert.Equal(test, 0, f.GetBatchWorkers())
+
+	formatted, err = f.FormatAll(messages)
+
+	assert.NoError(test, err)
+	assert.Len(test, formatted, 100)
+
+	for index, text := range formatted {
+		assert.Equal(test, strconv.Itoa(index)+" x", text)
+	}
+
+	formatted, err = f.SetBatchWorkers(4).FormatAll(nil)
+
+	assert.NoError(test, err)
+	assert.Empty(test, formatted)
+	assert.Equal(test, formatter.DefaultBatchWorkers, formatter.New().GetBatchWorkers())
+}
+
+func TestFormatterFormatEach(test *testing.T) {
+	formatted, err := formatter.FormatEach("Hello {item}!", []string{"Bob", "Alice"})
+
+	assert.NoError(test, err)
+	assert.Equal(test, []string{"Hello Bob!", "Hello Alice!"}, formatted)
+
+	formatted, err = formatter.FormatEach("{.Name} from {item.Address.City}", [2]interface{}{
+		&Person{Name: "Bob", Address: Address{City: "Warsaw"}},
+		"text",
+	})
+
+	var messageError *formatter.MessageError
+
+	assert.Equal(test, []string{"Bob from Warsaw", ""}, formatted)
+	assert.True(test, errors.As(err, &messageError))
+	assert.Equal(test, 1, messageError.Index)
+
+	formatted, err = formatter.New().SetOutputHook(strings.ToUpper).FormatEach("{item | printf \"%03d\"}", []int{1, 2})
+
+	assert.NoError(test, err)
+	assert.Equal(test, []string{"001", "002"}, formatted)
+
+	formatted, err = formatter.FormatEach("{item}", []interface{}{})
+
+	assert.NoError(test, err)
+	assert.Empty(test, formatted)
+
+	_, err = formatter.FormatEach("{item}", "text")
+
+	assert.Error(test, err)
+
+	_, err = formatter.FormatEach("{if}", []int{1})
+
+	assert.Error(test, err)
+}
+
+func TestFormatterFormatReader(test *testing.T) {
+	data, err := io.ReadAll(formatter.FormatReader("Hello {p}! {p | upper}", "Bob", "ab"))
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Hello Bob! AB", string(data))
+
+	data, err = io.ReadAll(formatter.New().FormatReader("{p0} {p1 | date}", "Bob", "text"))
+
+	assert.Error(test, err)
+	assert.Equal(test, "Bob ", string(data))
+
+	reader := formatter.New().SetWriteBufferSize(8).FormatReader(`{range p}{.}{end}`, strings.Split(strings.Repeat("x", 1000), ""))
+	buffer := make([]byte, 16)
+
+	n, err := io.ReadFull(reader, buffer)
+
+	assert.NoError(test, err)
+	assert.Equal(test, 16, n)
+	assert.Equal(test, strings.Repeat("x", 16), string(buffer))
+	assert.NoError(test, reader.(io.Closer).Close())
+}
+
+func TestFormatterCompile(test *testing.T) {
+	f := formatter.New().SetPlaceholder("arg")
+
+	compiled, err := f.Compile("Hello {arg}!")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Hello {arg}!", compiled.Message())
+
+	f.SetPlaceholder("p")
+
+	formatted, err := compiled.Format("Bob")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Hello Bob!", formatted)
+
+	var buffer bytes.Buffer
+
+	bound := compiled.Bind("Alice")
+
+	n, err := io.Copy(&buffer, bound.(io.Reader))
+
+	assert.NoError(test, err)
+	assert.Equal(test, int64(12), n)
+
+	data, err := io.ReadAll(&struct{ io.Reader }{bound.(io.Reader)})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Hello Alice!", string(data))
+
+	buffer.Reset()
+
+	for index := 0; index < 2; index++ {
+		n, err = bound.WriteTo(&buffer)
+
+		assert.NoError(test, err)
+		assert.Equal(test, int64(12), n)
+	}
+
+	assert.Equal(test, "Hello Alice!Hello Alice!", buffer.String())
+
+	compiled, err = formatter.Compile("{p | date}")
+
+	assert.NoError(test, err)
+
+	_, err = compiled.Bind("text").WriteTo(&buffer)
+
+	assert.Error(test, err)
+
+	_, err = formatter.Compile("{if}")
+
+	assert.Error(test, err)
+}
+
+func TestFormatterCompileParsed(test *testing.T) {
+	compiled, err := formatter.New().AddTemplate("name", "{.Name | upper}").
+		Compile(`{template "name" .}: {range $i, $v := .Items}{if $i}, {end}{$v}{end}`)
+
+	assert.NoError(test, err)
+
+	var group sync.WaitGroup
+
+	for index := 0; index < 10; index++ {
+		items := []string{"a", strconv.Itoa(index)}
+
+		group.Add(1)
+
+		go func() {
+			defer group.Done()
+
+			formatted, err := compiled.Format(struct {
+				Name  string
+				Items []string
+			}{Name: "bob", Items: items})
+
+			assert.NoError(test, err)
+			assert.Equal(test, "BOB: a, "+items[1], formatted)
+		}()
+	}
+
+	group.Wait()
+
+	compiled, err = formatter.Compile("<p0> {p0}")
+
+	assert.NoError(test, err)
+
+	formatted, err := compiled.Format("a", formatter.WithDelimiters("<", ">"))
+
+	assert.NoError(test, err)
+	assert.Equal(test, "a {p0}", formatted)
+
+	formatted, err = compiled.Format("b")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "<p0> b", formatted)
+
+	compiled, err = formatter.New(formatter.WithExecutionTimeout(time.Second)).Compile("{range p0}{.}{end}")
+
+	assert.NoError(test, err)
+
+	for index := 0; index < 2; index++ {
+		formatted, err = compiled.Format([]int{1, 2})
+
+		assert.NoError(test, err)
+		assert.Equal(test, "12", formatted)
+	}
+}
+
+// benchmarkMessage returns message with many actions, so parsing is a
+// significant part of formatting.
+func benchmarkMessage() string {
+	return strings.Repeat(`{p0 | upper} has {range $i, $v := p1}{if $i}, {end}{$v | printf "%03d"}{end}. `, 20)
+}
+
+func BenchmarkFormat(benchmark *testing.B) {
+	f, message := formatter.New(), benchmarkMessage()
+
+	for index := 0; index < benchmark.N; index++ {
+		if _, err := f.Format(message, "bob", []int{1, 2, 3}); err != nil {
+			benchmark.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCompiledFormat(benchmark *testing.B) {
+	compiled, err := formatter.New().Compile(benchmarkMessage())
+
+	if err != nil {
+		benchmark.Fatal(err)
+	}
+
+	for index := 0; index < benchmark.N; index++ {
+		if _, err := compiled.Format("bob", []int{1, 2, 3}); err != nil {
+			benchmark.Fatal(err)
+		}
+	}
+}
+
+func TestFormatterCallOptions(test *testing.T) {
+	f := formatter.New()
+
+	formatted, err := f.Format("{pos} {pos1}", "a", formatter.WithPlaceholder("pos"), "b")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "a b", formatted)
+	assert.Equal(test, formatter.DefaultPlaceholder, f.GetPlaceholder())
+
+	_, err = f.Format("{p}", "a", "b", formatter.WithStrict())
+
+	assert.Error(test, err)
+
+	formatted, err = f.Format("{p}", "a", "b")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "a b", formatted)
+
+	formatted, err = f.Format("{p0 | yesno}", true, formatter.WithLocale("pl"))
+
+	assert.NoError(test, err)
+	assert.Equal(test, "tak", formatted)
+
+	assert.Equal(test, "1 2", f.Sprintf("%d %d", 1, formatter.WithStrict(), 2))
+
+	formatted, err = f.FormatPartial("<p> {x}", "a", formatter.WithDelimiters("<", ">"))
+
+	assert.NoError(test, err)
+	assert.Equal(test, "a {x}", formatted)
+
+	formatted, err = f.FormatContext(context.Background(), "{p}", formatter.WithUnusedPrefix("|"), 1, 2)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "1|2", formatted)
+}
+
+func TestFormatterSettings(test *testing.T) {
+	f := formatter.New().SetDelimiters("<", ">").SetStrict(true).SetColorMode(formatter.ColorNever).
+		SetExecutionTimeout(1500*time.Millisecond).AddTemplate("hello", "Hello <p>!")
+
+	data, err := json.Marshal(f)
+
+	assert.NoError(test, err)
+	assert.Contains(test, string(data), `"strict":true`)
+	assert.Contains(test, string(data), `"colorMode":"never"`)
+	assert.Contains(test, string(data), `"executionTimeout":"1.5s"`)
+
+	decoded := formatter.New()
+
+	assert.NoError(test, json.Unmarshal(data, decoded))
+	assert.Equal(test, f.GetSettings(), decoded.GetSettings())
+
+	decoded = formatter.New().SetLocale("pl")
+
+	assert.NoError(test, json.Unmarshal([]byte(`{"placeholder": "arg", "conflictPolicy": "first", "placeholderStyle": "python"}`), decoded))
+
+	settings := decoded.GetSettings()
+
+	assert.Equal(test, "arg", settings.Placeholder)
+	assert.Equal(test, formatter.FirstWins, decoded.GetConflictPolicy())
+	assert.Equal(test, formatter.PythonStyle, decoded.GetPlaceholderStyle())
+	assert.Equal(test, "pl", decoded.GetLocale())
+	assert.Equal(test, formatter.DefaultLeftDelimiter, settings.LeftDelimiter)
+
+	formatted, err := decoded.Format("{} {arg0}", 1)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "1 1", formatted)
+
+	data, err = yaml.Marshal(f)
+
+	assert.NoError(test, err)
+	assert.Contains(test, string(data), "rightDelimiter: '>'")
+
+	decoded = formatter.New()
+
+	assert.NoError(test, yaml.Unmarshal(data, decoded))
+	assert.Equal(test, f.GetSettings(), decoded.GetSettings())
+
+	assert.NoError(test, yaml.Unmarshal([]byte("widthAlgorithm: rune\nstrict: true\n"), decoded))
+	assert.Equal(test, formatter.RuneWidth, decoded.GetWidthAlgorithm())
+
+	assert.Error(test, json.Unmarshal([]byte(`{"colorMode": "sometimes"}`), formatter.New()))
+	assert.Error(test, json.Unmarshal([]byte(`{"executionTimeout": "soon"}`), formatter.New()))
+	assert.Error(test, yaml.Unmarshal([]byte("conflictPolicy: random"), formatter.New()))
+
+	_, err = json.Marshal(formatter.New().SetColorMode(formatter.ColorMode(7)))
+
+	assert.Error(test, err)
+}
+
+func TestFormatterSettingsFunctionSets(test *testing.T) {
+	newFormatter := func() *formatter.Formatter {
+		return formatter.New().
+			AddFunctionSet("str", formatter.Functions{"upper": strings.ToUpper}).
+			AddFunctionSet("text", formatter.Functions{"lower": strings.ToLower})
+	}
+
+	f := newFormatter().RemoveFunctionSet("text")
+
+	data, err := json.Marshal(f)
+
+	assert.NoError(test, err)
+	assert.Contains(test, string(data), `"functionSets":["str"]`)
+
+	decoded := newFormatter()
+
+	assert.NoError(test, json.Unmarshal(data, decoded))
+	assert.Equal(test, f.GetSettings(), decoded.GetSettings())
+	assert.Equal(test, []string{"str"}, decoded.GetFunctionSets())
+
+	formatted, err := decoded.Format("{p0 | str.upper}", "a")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "A", formatted)
+
+	decoded = newFormatter()
+
+	assert.NoError(test, yaml.Unmarshal([]byte("strict: true\n"), decoded))
+	assert.Equal(test, []string{"str", "text"}, decoded.GetFunctionSets())
+
+	assert.NoError(test, yaml.Unmarshal([]byte("functionSets: [text]\n"), decoded))
+	assert.Equal(test, []string{"text"}, decoded.GetFunctionSets())
+
+	data, err = yaml.Marshal(decoded)
+
+	assert.NoError(test, err)
+	assert.Contains(test, string(data), "functionSets:\n  - text")
+
+	decoded = newFormatter()
+
+	err = json.Unmarshal([]byte(`{"functionSets": ["str", "math"], "strict": true}`), decoded)
+
+	assert.True(test, errors.Is(err, formatter.ErrUnknownFunctionSet))
+	assert.Contains(test, err.Error(), `"math"`)
+	assert.Equal(test, []string{"str", "text"}, decoded.GetFunctionSets())
+	assert.False(test, decoded.IsStrict())
+}
+
+func TestFormatterAddedDelimiters(test *testing.T) {
+	f := formatter.New().AddDelimiters("<%", "%>")
+
+	formatted, err := f.Format(`[<% range $i, $v := .Items %><% if $i %>, <% end %>{$v | json}<% end %>]`,
+		struct{ Items []string }{Items: []string{"a", "b"}})
+
+	assert.NoError(test, err)
+	assert.Equal(test, `["a", "b"]`, formatted)
+
+	formatted, err = f.Format(`<%<% {p} <% "%>" %>`, "value")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "<% value %>", formatted)
+
+	assert.Equal(test, []formatter.Delimiters{{Left: "<%", Right: "%>"}}, f.GetAddedDelimiters())
+	assert.Empty(test, f.ResetAddedDelimiters().GetAddedDelimiters())
+
+	formatted, err = formatter.New(formatter.WithAddedDelimiters("[[", "]]")).Format("[[ .Name ]] {.Name}",
+		struct{ Name string }{Name: "Bob"})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Bob Bob", formatted)
+}
+
+func TestFormatterRawBlock(test *testing.T) {
+	formatted, err := formatter.Format("Use {raw}{name} or {{p}} {# note #}{end} for {p}", "values")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Use {name} or {{p}} {# note #} for values", formatted)
+
+	formatted, err = formatter.New().SetPlaceholderStyle(formatter.PythonStyle).Format("{ raw }{}{end} is {}", 3)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "{} is 3", formatted)
+
+	formatted, err = formatter.New().SetDelimiters("<", ">").Format("<raw><p> {p}<end> <p>", 1)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "<p> {p} 1", formatted)
+
+	formatted, err = formatter.Format("{raw}", formatter.Arg("raw", "value"))
+
+	assert.NoError(test, err)
+	assert.Equal(test, "value", formatted)
+}
+
+func TestFormatterDiagnose(test *testing.T) {
+	assert.Empty(test, formatter.Diagnose(`Hello {name}, {{"id": {p}}} {raw}{"a": 1} }{end} {# note #}`))
+
+	diagnostics := formatter.Diagnose("Object {\"id\": {p}} and } and {} {if {.A}}{end}\n{p")
+
+	assert.Equal(test, []formatter.Diagnostic{{
+		Text:       `{"id": {p}`,
+		Location:   ":1:7",
+		Message:    "action looks like JSON object",
+		Suggestion: `escape braces as "{{" and "}}" or use other delimiters`,
+	}, {
+		Text:       "}",
+		Location:   ":1:17",
+		Message:    "unbalanced right delimiter",
+		Suggestion: `escape it as "}}"`,
+	}, {
+		Text:       "{}",
+		Location:   ":1:29",
+		Message:    "empty action",
+		Suggestion: `use "{p}" placeholder or escape braces as "{{" and "}}" or use other delimiters`,
+	}, {
+		Text:       "{if {.A}",
+		Location:   ":1:32",
+		Message:    "left delimiter inside action",
+		Suggestion: `escape braces as "{{" and "}}" or use other delimiters`,
+	}, {
+		Text:       "}",
+		Location:   ":1:40",
+		Message:    "unbalanced right delimiter",
+		Suggestion: `escape it as "}}"`,
+	}, {
+		Text:       "{p",
+		Location:   ":2:0",
+		Message:    "unclosed action",
+		Suggestion: `escape left delimiter as "{{" or use other delimiters`,
+	}}, diagnostics)
+
+	assert.Empty(test, formatter.New().SetPlaceholderStyle(formatter.PythonStyle).Diagnose("{} {0}"))
+
+	assert.NoError(test, formatter.Validate("Hello {name}"))
+
+	err := formatter.Validate("Hello {name} }")
+
+	var validationError *formatter.ValidationError
+
+	assert.True(test, errors.As(err, &validationError))
+	assert.NoError(test, validationError.Err)
+	assert.Len(test, validationError.Diagnostics, 1)
+	assert.Equal(test, `:1:13: unbalanced right delimiter, escape it as "}}"`, err.Error())
+
+	err = formatter.Validate(`JSON {"id": 3}`)
+
+	assert.True(test, errors.As(err, &validationError))
+	assert.Error(test, validationError.Err)
+	assert.Contains(test, err.Error(), "; :1:5: action looks like JSON object")
+
+	err = formatter.Validate("{if}")
+
+	assert.True(test, errors.As(err, &validationError))
+	assert.Error(test, validationError.Err)
+	assert.Empty(test, validationError.Diagnostics)
+}
+
+func TestFormatterFunctionSet(test *testing.T) {
+	f := formatter.New().
+		AddFunctionSet("str", formatter.Functions{"upper": strings.ToUpper, "repeat": strings.Repeat}).
+		AddFunctionSet("num", formatter.Functions{"double": func(value int) int { return 2 * value }})
+
+	formatted, err := f.Format("{p0 | str.upper} {str.repeat p1 2} {num.double 4}", "abc", "x")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "ABC xx 8", formatted)
+
+	assert.Equal(test, []string{"num", "str"}, f.GetFunctionSets())
+	assert.Len(test, f.GetFunctionSet("str"), 2)
+	assert.Nil(test, f.GetFunctionSet("missing"))
+
+	_, err = f.Format("{p | str.uper}", "abc")
+
+	assert.Error(test, err)
+	assert.Contains(test, err.Error(), `function "uper" not found in function set "str", did you mean "upper"?`)
+
+	_, err = f.Format("{str.upper}", formatter.Arg("str", "value"))
+
+	assert.True(test, errors.Is(err, formatter.ErrNamespaceConflict))
+
+	_, err = f.Clone().AddFunction("num", func() int { return 0 }).Format("{num.double 1}")
+
+	assert.True(test, errors.Is(err, formatter.ErrNamespaceConflict))
+
+	placeholders, err := f.Placeholders("{name | str.upper}")
+
+	assert.NoError(test, err)
+	assert.Equal(test, []string{"name"}, placeholders)
+
+	formatted, err = f.FormatPartial("{p | str.upper} {missing}", "abc")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "ABC {missing}", formatted)
+
+	_, err = f.RemoveFunctionSet("str").Format("{p | str.upper}", "abc")
+
+	assert.Error(test, err)
+	assert.Equal(test, []string{"num"}, f.GetFunctionSets())
+}
+
+func TestFormatterFunctionPolicy(test *testing.T) {
+	upper := func() string { return "custom" }
+
+	formatted, err := formatter.New().AddFunction("name", upper).Format("{name}", formatter.Arg("name", "Bob"))
+
+	assert.NoError(test, err)
+	assert.Equal(test, "custom", formatted)
+
+	f := formatter.New(formatter.WithFunctionPolicy(formatter.FunctionIgnore)).
+		AddFunction("name", upper).
+		AddFunction("json", upper).
+		AddFunction("extra", upper).
+		AddFunction("extra", func() string { return "ignored" })
+
+	formatted, err = f.Format(`{name} {"a" | json} {extra}`, formatter.Arg("name", "Bob"))
+
+	assert.NoError(test, err)
+	assert.Equal(test, `Bob "a" custom`, formatted)
+	assert.Equal(test, formatter.FunctionIgnore, f.GetFunctionPolicy())
+
+	f = formatter.New().SetFunctionPolicy(formatter.FunctionError).AddFunction("name", upper)
+
+	formatted, err = f.Format("{name}")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "custom", formatted)
+
+	_, err = f.Format("{name}", formatter.Arg("name", "Bob"))
+
+	assert.True(test, errors.Is(err, formatter.ErrFunctionConflict))
+	assert.Contains(test, err.Error(), ": name")
+
+	_, err = f.Clone().AddFunction("json", upper).Format("{name}")
+
+	assert.True(test, errors.Is(err, formatter.ErrFunctionConflict))
+	assert.Contains(test, err.Error(), ": json")
+
+	_, err = f.Clone().AddFunction("json", upper).ResetFunctions().Format("text {p}", 1)
+
+	assert.NoError(test, err)
+
+	formatted, err = f.Clone().AddFunction("json", upper).RemoveFunction("json").Format(`{"a" | json}`)
+
+	assert.NoError(test, err)
+	assert.Equal(test, `"a"`, formatted)
+
+	_, err = f.Clone().AddFunction("json", upper).AddFunction("xml", upper).
+		RemoveFunctions([]string{"xml"}).Format("{name}")
+
+	assert.True(test, errors.Is(err, formatter.ErrFunctionConflict))
+	assert.Contains(test, err.Error(), ": json")
+
+	err = formatter.New().AddFunctionStrict("json", upper)
+
+	assert.True(test, errors.Is(err, formatter.ErrFunctionConflict))
+
+	f = formatter.New()
+
+	assert.NoError(test, f.AddFunctionStrict("greet", upper))
+	assert.True(test, errors.Is(f.AddFunctionStrict("greet", upper), formatter.ErrFunctionConflict))
+
+	formatted, err = f.Format("{greet}")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "custom", formatted)
+}
+
+func TestFormatterRegisterGlobal(test *testing.T) {
+	before := formatter.New()
+
+	formatter.RegisterGlobal("globalGreeting", func(name string) string { return "Hello " + name })
+	defer formatter.UnregisterGlobal("globalGreeting")
+
+	assert.Contains(test, formatter.GlobalFunctions(), "globalGreeting")
+
+	formatted, err := formatter.Format("{p | globalGreeting}", "Bob")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Hello Bob", formatted)
+
+	_, err = before.Format("{p | globalGreeting}", "Bob")
+
+	assert.Error(test, err)
+
+	f := formatter.New().RemoveFunction("globalGreeting")
+
+	_, err = f.Format("{p | globalGreeting}", "Bob")
+
+	assert.Error(test, err)
+
+	formatted, err = f.ResetFunctions().Format("{p | globalGreeting}", "Bob")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Hello Bob", formatted)
+
+	formatter.UnregisterGlobal("globalGreeting")
+
+	assert.NotContains(test, formatter.GlobalFunctions(), "globalGreeting")
+
+	_, err = formatter.Format("{p | globalGreeting}", "Bob")
+
+	assert.Error(test, err)
+}
+
+func TestFormatterSandbox(test *testing.T) {
+	f := formatter.New().SetSandbox(formatter.DefaultSandbox())
+	person := &Person{Name: "Bob", Address: Address{City: "Paris"}}
+
+	formatted, err := f.Format("{p0.Name | upper} {p0.Address.City} {len p0.Name}", person)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "BOB Paris 3", formatted)
+
+	formatted, err = f.Format("{range $i, $v := p}{$v}{end}", []int{1, 2, 3})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "123", formatted)
+
+	for _, message := range []string{`{env "HOME"}`, "{hostname}", `{call p}`, "{p.DisplayName}",
+		`{p.Greet "Hi"}`, `{with p}{.Greet "Hi"}{end}`, `{$v := p}{$v.DisplayName}`} {
+		_, err = f.Format(message, person)
+
+		assert.Error(test, err, message)
+	}
+
+	_, err = f.Format(`{call p}`, func() string { return "called" })
+
+	assert.True(test, errors.Is(err, formatter.ErrSandboxViolation))
+
+	_, err = f.Format("{p.DisplayName}", person)
+
+	assert.True(test, errors.Is(err, formatter.ErrMethodCallsDisabled))
+
+	sandbox := &formatter.Sandbox{Functions: []string{"upper", "str.lower"}, MaxIterations: 3}
+	f = formatter.New().AddFunctionSet("str", formatter.Functions{"lower": strings.ToLower, "title": strings.Title}).
+		SetSandbox(sandbox)
+
+	formatted, err = f.Format("{p0 | upper} {p0 | str.lower} {hostname}", "Bob", formatter.Arg("hostname", "host"))
+
+	assert.NoError(test, err)
+	assert.Equal(test, "BOB bob host", formatted)
+
+	_, err = f.Format("{p | str.title}", "bob")
+
+	assert.True(test, errors.Is(err, formatter.ErrSandboxViolation))
+
+	_, err = f.Format("{p | lower}", "Bob")
+
+	assert.True(test, errors.Is(err, formatter.ErrSandboxViolation))
+
+	_, err = f.Format("{range p0}{.}{end}{range p0}{.}{end}", []int{1, 2})
+
+	assert.True(test, errors.Is(err, formatter.ErrTooManyIterations))
+
+	assert.Equal(test, sandbox, f.GetSandbox())
+	assert.Nil(test, f.SetSandbox(nil).GetSandbox())
+
+	f = formatter.New().SetSandbox(&formatter.Sandbox{Functions: []string{"printf"}, MaxOutputSize: 4, MaxDepth: 1})
+
+	assert.Equal(test, 4, f.GetMaxOutputSize())
+	assert.Equal(test, 1, f.GetMaxDepth())
+
+	_, err = f.Format("{p}", "too long")
+
+	assert.True(test, errors.Is(err, formatter.ErrOutputTooLarge))
+
+	assert.NotContains(test, formatter.DefaultSandbox().Functions, "env")
+	assert.Contains(test, formatter.DefaultSandbox().Functions, "printf")
+}
+
+func TestFormatterMethodCalls(test *testing.T) {
+	person := &Person{Name: "Bob"}
+	message := `{user.DisplayName}, {user.Greet "Hi"}, {$u := user}{$u.DisplayName}`
+
+	formatted, err := formatter.Format(message, formatter.Arg("user", person))
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Mr. Bob, Hi Bob, Mr. Bob", formatted)
+
+	f := formatter.New(formatter.WithMethodCalls(false))
+
+	assert.False(test, f.GetMethodCalls())
+
+	for _, message := range []string{"{user.DisplayName}", `{user.Greet "Hi"}`, "{$u := user}{$u.DisplayName}",
+		`{with user}{.Greet "Hi"}{end}`} {
+		_, err = f.Format(message, formatter.Arg("user", person))
+
+		assert.True(test, errors.Is(err, formatter.ErrMethodCallsDisabled), message)
+	}
+
+	formatted, err = f.Format("{user.Name} {$u := user}{$u.Name}", formatter.Arg("user", person))
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Bob Bob", formatted)
+
+	assert.True(test, f.SetMethodCalls(true).GetMethodCalls())
+	assert.False(test, f.SetSandbox(formatter.DefaultSandbox()).GetMethodCalls())
+}
+
+type taggedAccount struct {
+	UserID   int    `json:"user_id"`
+	Email    string `json:"email,omitempty" format:"mail,redact"`
+	Internal string `json:"-"`
+	Level    int
+}
+
+func TestFormatterNameTag(test *testing.T) {
+	account := taggedAccount{UserID: 42, Email: "bob@example.com", Internal: "x", Level: 3}
+
+	_, err := formatter.New().SetStrict(true).Format("{user_id}", account)
+
+	assert.Error(test, err)
+
+	f := formatter.New(formatter.WithNameTag("json"))
+
+	formatted, err := f.Format("{user_id} {.user_id} {.UserID} {.Level} {email}", account)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "42 42 42 3 ***", formatted)
+
+	formatted, err = f.Format("{account.user_id} {account.Level}", formatter.Named{"account": &account})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "42 3", formatted)
+
+	_, err = f.Format("{account.Internal} {account.-}", formatter.Named{"account": account})
+
+	assert.Error(test, err)
+
+	formatted, err = f.SetNameTag(formatter.TagName).Format("{mail} {.mail}", &account)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "*** ***", formatted)
+	assert.Equal(test, formatter.TagName, f.GetNameTag())
+}
+
+func TestFormatterCaseInsensitive(test *testing.T) {
+	person := Person{Name: "Bob", Address: Address{City: "Oslo"}}
+	named := formatter.Named{"UserName": "bob", "username": "alice"}
+
+	_, err := formatter.New().SetStrict(true).Format("{USERNAME}", named)
+
+	assert.Error(test, err)
+
+	f := formatter.New(formatter.WithCaseInsensitive(true))
+
+	formatted, err := f.Format("{UserName} {username} {USERNAME} {Username}", named)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "bob alice bob bob", formatted)
+
+	formatted, err = f.Format("{P0.name} {p0.ADDRESS.city} {P0.displayname} {.NAME}", person)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Bob Oslo Mr. Bob Bob", formatted)
+
+	formatted, err = f.Format("{CONFIG.Region}", formatter.Named{"config": map[string]string{"region": "eu"}})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "eu", formatted)
+
+	formatted, err = f.FormatPartial("{USERNAME} {missing}", named)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "bob {missing}", formatted)
+
+	assert.True(test, f.GetCaseInsensitive())
+	assert.False(test, f.SetCaseInsensitive(false).GetCaseInsensitive())
+}
+
+func TestFormatterIndex(test *testing.T) {
+	people := []Person{{Name: "Bob"}, {Name: "Alice", Address: Address{City: "Oslo"}}}
+
+	formatted, err := formatter.Format("{p0[2]} {p1[1].Name} {p1[1].Address.City}", []int{1, 2, 3}, people)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "3 Alice Oslo", formatted)
+
+	formatted, err = formatter.Format("{items[0].Name | upper} {matrix[1][0]} {\"[0]\"}",
+		formatter.Named{"items": &people, "matrix": [2][]string{{"a"}, {"b"}}})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "BOB b [0]", formatted)
+
+	formatted, err = formatter.New().SetPlaceholderStyle(formatter.PythonStyle).Format("{0[1]}", []string{"x", "y"})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "y", formatted)
+
+	_, err = formatter.Format("{items[5]}", formatter.Named{"items": people})
+
+	assert.True(test, errors.Is(err, formatter.ErrIndexOutOfRange))
+	assert.Contains(test, err.Error(), `"items[5]"`)
+
+	_, err = formatter.Format("{p0[0]}", 42)
+
+	assert.Error(test, err)
+
+	formatted, err = formatter.FormatPartial("{p0[1]} {missing[0]}", []int{1, 2})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "2 {missing[0]}", formatted)
+}
+
+func TestFormatterEachLoop(test *testing.T) {
+	people := []Person{{Name: "Bob"}, {Name: "Alice"}}
+
+	formatted, err := formatter.Format("{each items}{.Name}, {end}", formatter.Named{"items": people})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Bob, Alice, ", formatted)
+
+	formatted, err = formatter.Format("{each $key, $value := p0}{$key}={$value} {end}|{- each p1 -} {.} {- else}none{end}",
+		map[string]int{"c": 3, "a": 1, "b": 2}, []int{})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "a=1 b=2 c=3 |none", formatted)
+
+	formatted, err = formatter.Format("{each}", formatter.Named{"each": "value"})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "value", formatted)
+
+	formatted, err = formatter.FormatPartial("{each p0}{.}{end} {missing}", []string{"a", "b"})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "ab {missing}", formatted)
+}
+
+func TestFormatterConditional(test *testing.T) {
+	message := "{if premium}Thanks for subscribing!{else}Upgrade today.{end}"
+
+	for _, tc := range []struct {
+		arguments []interface{}
+		expected  string
+	}{
+		{[]interface{}{formatter.Named{"premium": true}}, "Thanks for subscribing!"},
+		{[]interface{}{formatter.Named{"premium": "yes"}}, "Thanks for subscribing!"},
+		{[]interface{}{formatter.Named{"premium": 1}}, "Thanks for subscribing!"},
+		{[]interface{}{formatter.Named{"premium": false}}, "Upgrade today."},
+		{[]interface{}{formatter.Named{"premium": ""}}, "Upgrade today."},
+		{[]interface{}{formatter.Named{"premium": 0}}, "Upgrade today."},
+		{[]interface{}{formatter.Named{"premium": nil}}, "Upgrade today."},
+		{[]interface{}{formatter.Named{"premium": []int{}}}, "Upgrade today."},
+		{nil, "Upgrade today."},
+	} {
+		formatted, err := formatter.New().SetStrict(true).Format(message, tc.arguments...)
+
+		assert.NoError(test, err)
+		assert.Equal(test, tc.expected, formatted)
+	}
+
+	formatted, err := formatter.Format("{if not (or trial premium)}free{else if trial}trial{else}premium{end}",
+		formatter.Named{"trial": 1})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "trial", formatted)
+
+	_, err = formatter.Format("{if eq plan \"pro\"}pro{end}")
+
+	assert.Error(test, err)
+}
+
+func TestFormatterTernary(test *testing.T) {
+	formatted, err := formatter.Format(`{ternary "Thanks" "Upgrade" premium} {ternary "yes" "no" p1} {ternary "yes" "no" trial}`,
+		formatter.Named{"premium": true}, 0)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Thanks no no", formatted)
+
+	formatted, err = formatter.Format(`{ternary "items" "item" (gt count 1)}`, formatter.Named{"count": 3})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "items", formatted)
+
+	formatted, err = formatter.Format(`{premium | ternary "Thanks" "Upgrade"} {trial | ternary "yes" "no"} {gt count 1 | ternary "items" "item"}`,
+		formatter.Named{"premium": true, "count": 1})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Thanks no item", formatted)
+}
+
+func TestFormatterCoalesce(test *testing.T) {
+	formatted, err := formatter.Format(`{coalesce nickname name "anonymous"}|{coalesce missing user.Name "-"}`,
+		formatter.Named{"nickname": "", "name": "Bob", "user": map[string]string{}})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "Bob|-", formatted)
+
+	formatted, err = formatter.Format(`{coalesce count 1} {nickname | coalesce "x"}`, formatter.Named{"count": 0})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "0 x", formatted)
+
+	requirements, err := formatter.Required("{coalesce nickname name}")
+
+	assert.NoError(test, err)
+	assert.Empty(test, requirements.Names)
+	assert.Equal(test, []string{"name", "nickname"}, requirements.Optional)
+}
@@ -14,6 +14,8 @@
 
 package formatter_test
 
+import "fmt"
+
 // Error type.
 type Error string
 
@@ -56,3 +58,70 @@ func (w *WriterError) Write([]byte) (int, error) {
 
 	return 0, Error("error")
 }
+
+// Address type.
+type Address struct {
+	City   string
+	Street *string
+	Tags   map[string]string
+}
+
+// Person type.
+type Person struct {
+	Name    string
+	Address Address
+	Manager *Person
+}
+
+// DisplayName returns display name.
+func (p Person) DisplayName() string {
+	return "Mr. " + p.Name
+}
+
+// Greet returns greeting.
+func (p *Person) Greet(greeting string) string {
+	return greeting + " " + p.Name
+}
+
+// Validate returns an error.
+func (p *Person) Validate() (bool, error) {
+	return false, Error("invalid person")
+}
+
+// ManagerName returns manager name. It panics if manager is not set.
+func (p *Person) ManagerName() string {
+	return p.Manager.Name
+}
+
+// WriterPanic mocks Writer interface and panics.
+type WriterPanic struct{}
+
+// Write panics.
+func (w *WriterPanic) Write([]byte) (int, error) {
+	panic("writer panic")
+}
+
+// Money defines amount in cents that controls its own formatting.
+type Money int64
+
+// FormatValue formats money using provided spec.
+func (m Money) FormatValue(spec string) (string, error) {
+	switch spec {
+	case "":
+		return fmt.Sprintf("$%d.%02d", m/100, m%100), nil
+	case "short":
+		return fmt.Sprintf("$%d", m/100), nil
+	default:
+		return "", fmt.Errorf("unknown spec %q", spec)
+	}
+}
+
+// Credentials defines struct with fields that must not be logged.
+type Credentials struct {
+	User     string
+	Password string `format:"redact"`
+	Token    string `format:"redact,hash"`
+}
+
+// Secret defines type registered as redacted.
+type Secret string
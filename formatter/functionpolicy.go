@@ -0,0 +1,173 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// These constants define policies used when template function name collides
+// with a built-in function, a placeholder or an existing function.
+const (
+	// FunctionOverride lets added function shadow built-in functions,
+	// placeholders and functions added before.
+	FunctionOverride FunctionPolicy = iota
+
+	// FunctionIgnore keeps built-in functions, placeholders and functions
+	// added before. Colliding functions are ignored.
+	FunctionIgnore
+
+	// FunctionError keeps built-in functions and functions added before and
+	// reports collisions as ErrFunctionConflict when formatting.
+	FunctionError
+)
+
+// ErrFunctionConflict is returned when template function name collides with
+// a built-in function, a placeholder or an existing function.
+const ErrFunctionConflict = fError("function name collides with built-in function, placeholder or existing function")
+
+// FunctionPolicy defines what happens when template function name collides
+// with a built-in function, a placeholder or an existing function.
+type FunctionPolicy int
+
+// SetFunctionPolicy sets policy used when function added with AddFunction or
+// AddFunctions collides with a built-in function or an existing function and
+// when function collides with a placeholder defined by arguments. Default is
+// FunctionOverride.
+func (f *Formatter) SetFunctionPolicy(policy FunctionPolicy) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.functionPolicy = policy
+
+	return f
+}
+
+// GetFunctionPolicy returns policy used when template function name collides
+// with a built-in function, a placeholder or an existing function.
+func (f *Formatter) GetFunctionPolicy() FunctionPolicy {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.functionPolicy
+}
+
+// AddFunctionStrict adds template function used by formatter. It returns
+// ErrFunctionConflict and doesn't add function if its name collides with a
+// built-in function or an existing function regardless of policy.
+func (f *Formatter) AddFunctionStrict(name string, function interface{}) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if _, ok := f.functions[name]; ok || f.isBuiltinFunction(name) {
+		return fmt.Errorf("%w: %q", ErrFunctionConflict, name)
+	}
+
+	f.functions = copyFunctions(f.functions)
+	f.functions[name] = function
+
+	return nil
+}
+
+// addFunctions adds functions according to function policy. Collisions are
+// recorded for FunctionError policy.
+func (f *config) addFunctions(functions Functions) {
+	f.functions = copyFunctions(f.functions)
+
+	for name, function := range functions {
+		if f.functionPolicy != FunctionOverride {
+			if _, ok := f.functions[name]; ok || f.isBuiltinFunction(name) {
+				if f.functionPolicy == FunctionError {
+					f.functionConflicts = append(append([]string(nil), f.functionConflicts...), name)
+				}
+
+				continue
+			}
+		}
+
+		f.functions[name] = function
+	}
+}
+
+// removeFunctionConflicts forgets collisions recorded for provided names.
+func (f *config) removeFunctionConflicts(names []string) {
+	removed := make(map[string]bool, len(names))
+
+	for _, name := range names {
+		removed[name] = true
+	}
+
+	conflicts := []string(nil)
+
+	for _, conflict := range f.functionConflicts {
+		if !removed[conflict] {
+			conflicts = append(conflicts, conflict)
+		}
+	}
+
+	f.functionConflicts = conflicts
+}
+
+// isBuiltinFunction returns true if name is a built-in or text/template
+// function.
+func (f *config) isBuiltinFunction(name string) bool {
+	return isDefined(name, f.builtinFunctionMaps(io.Discard))
+}
+
+// customFunctions returns added functions. Functions that collide with
+// placeholders are removed for FunctionIgnore policy.
+func (f *config) customFunctions(placeholders template.FuncMap) template.FuncMap {
+	if f.functionPolicy != FunctionIgnore {
+		return template.FuncMap(f.functions)
+	}
+
+	functions := template.FuncMap{}
+
+	for name, function := range f.functions {
+		if _, ok := placeholders[name]; !ok {
+			functions[name] = function
+		}
+	}
+
+	return functions
+}
+
+// checkFunctionConflicts returns ErrFunctionConflict with colliding names for
+// FunctionError policy.
+func (f *config) checkFunctionConflicts(placeholders template.FuncMap) error {
+	if f.functionPolicy != FunctionError {
+		return nil
+	}
+
+	conflicts := append([]string(nil), f.functionConflicts...)
+
+	for name := range f.functions {
+		if _, ok := placeholders[name]; ok {
+			conflicts = append(conflicts, name)
+		}
+	}
+
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	sort.Strings(conflicts)
+
+	return fmt.Errorf("%w: %s", ErrFunctionConflict, strings.Join(conflicts, ", "))
+}
@@ -46,6 +46,15 @@ var gFunctions = template.FuncMap{ // nolint: gochecknoglobals
 	"cyan":       setCyan,
 	"white":      setWhite,
 	"gray":       setGray,
+	"bgBlack":    setBackgroundBlack,
+	"bgRed":      setBackgroundRed,
+	"bgGreen":    setBackgroundGreen,
+	"bgYellow":   setBackgroundYellow,
+	"bgBlue":     setBackgroundBlue,
+	"bgMagenta":  setBackgroundMagenta,
+	"bgCyan":     setBackgroundCyan,
+	"bgWhite":    setBackgroundWhite,
+	"bgGray":     setBackgroundGray,
 	"rgb":        setRGB,
 	"bright":     setBright,
 	"background": setBackground,
@@ -67,12 +76,58 @@ var gFunctions = template.FuncMap{ // nolint: gochecknoglobals
 	"upper":      strings.ToUpper,
 	"lower":      strings.ToLower,
 	"capitalize": strings.Title,
+	"camelCase":  camelCase,
+	"pascalCase": pascalCase,
+	"snakeCase":  snakeCase,
+	"kebabCase":  kebabCase,
 	"now":        time.Now,
 	"rfc3339":    setISO8601,
 	"iso8601":    setISO8601,
+	"tz":         setTimezone,
 	"absolute":   filepath.Abs,
 	"base":       filepath.Base,
 	"clean":      filepath.Clean,
 	"directory":  filepath.Dir,
 	"extension":  filepath.Ext,
+	"fallback":   fallback,
+	"coalesce":   coalesce,
+	"ternary":    ternary,
+	"format":     formatSpec,
+	"table":      table,
+	"json":       jsonFunction,
+	"jsonIndent": jsonIndentFunction,
+	"yaml":       yamlFunction,
+	"toml":       tomlFunction,
+	"logfmt":     logfmtFunction,
+	"dump":       dumpFunction,
+	"diff":       diffFunction,
+	"errorChain": errorChain,
+	"raw":        raw,
+	"stack":      stackFunction,
+	"mdBold":     mdBold,
+	"mdCode":     mdCode,
+	"mdLink":     mdLink,
+	"mdTable":    mdTableFunction,
+	"mdEscape":   mdEscape,
+	"xmlEscape":  xmlEscape,
+	"urlQuery":   urlQuery,
+	"urlPath":    urlPath,
+	"shellQuote": shellQuote,
+	"join":       join,
+	"bullets":    bullets,
+	"numbered":   numbered,
+	"indent":     indent,
+	"bytesIEC":   bytesIEC,
+	"bytesSI":    bytesSI,
+	"hex":        formatHex,
+	"hexPrefix":  formatHexPrefix,
+	"oct":        formatOct,
+	"bin":        formatBin,
+	"bits":       formatBits,
+
+	"humanizeDuration": humanizeDuration,
+	"hangingIndent":    hangingIndent,
+
+	"screamingSnakeCase": screamingSnakeCase,
+	"sentenceCase":       sentenceCase,
 }
@@ -0,0 +1,225 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"fmt"
+	"sort"
+	"text/template"
+	"text/template/parse"
+)
+
+// namespacePrefix is prefix of internal names of functions from function
+// sets. Dot cannot be used in names of template functions.
+const namespacePrefix = "_ns_"
+
+// ErrNamespaceConflict is returned when message uses function set with
+// namespace that is also a name of function or placeholder.
+const ErrNamespaceConflict = fError("namespace of function set is also a function or placeholder")
+
+// ErrUnknownFunctionSet is returned when settings enable function set that
+// wasn't added.
+const ErrUnknownFunctionSet = fError("unknown function set")
+
+// AddFunctionSet adds group of template functions under provided namespace.
+// Messages call them with namespace prefix like {p0 | str.upper}, so function
+// bundles can use the same names without clashes. Functions are merged with
+// functions added before under the same namespace. Using namespace that is
+// also a name of function or placeholder is reported as ErrNamespaceConflict.
+func (f *Formatter) AddFunctionSet(namespace string, functions Functions) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	sets := make(map[string]Functions, len(f.functionSets)+1)
+
+	for name, set := range f.functionSets {
+		sets[name] = set
+	}
+
+	set := copyFunctions(sets[namespace])
+
+	for name, function := range functions {
+		set[name] = function
+	}
+
+	sets[namespace] = set
+	f.functionSets = sets
+
+	return f
+}
+
+// GetFunctionSet returns a copy of template functions added under provided
+// namespace or nil if there is no such function set.
+func (f *Formatter) GetFunctionSet(namespace string) Functions {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	if set, ok := f.functionSets[namespace]; ok {
+		return copyFunctions(set)
+	}
+
+	return nil
+}
+
+// GetFunctionSets returns sorted namespaces of function sets.
+func (f *Formatter) GetFunctionSets() []string {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.namespaces()
+}
+
+// namespaces returns sorted namespaces of function sets.
+func (f *config) namespaces() []string {
+	namespaces := make([]string, 0, len(f.functionSets))
+
+	for namespace := range f.functionSets {
+		namespaces = append(namespaces, namespace)
+	}
+
+	sort.Strings(namespaces)
+
+	return namespaces
+}
+
+// RemoveFunctionSet removes template functions added under provided
+// namespace.
+func (f *Formatter) RemoveFunctionSet(namespace string) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	sets := make(map[string]Functions, len(f.functionSets))
+
+	for name, set := range f.functionSets {
+		if name != namespace {
+			sets[name] = set
+		}
+	}
+
+	f.functionSets = sets
+
+	return f
+}
+
+// keepFunctionSets removes function sets with namespaces not provided. It
+// returns ErrUnknownFunctionSet if there is no function set with provided
+// namespace.
+func (f *config) keepFunctionSets(namespaces []string) error {
+	sets := make(map[string]Functions, len(namespaces))
+
+	for _, namespace := range namespaces {
+		set, ok := f.functionSets[namespace]
+
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrUnknownFunctionSet, namespace)
+		}
+
+		sets[namespace] = set
+	}
+
+	f.functionSets = sets
+
+	return nil
+}
+
+// transformNamespaces replaces calls like str.upper with calls to internal
+// names of functions from function sets. It returns function map with used
+// functions.
+func (f *config) transformNamespaces(trees map[string]*parse.Tree, functions []template.FuncMap) (template.FuncMap, error) {
+	used := template.FuncMap{}
+
+	if len(f.functionSets) == 0 {
+		return used, nil
+	}
+
+	var err error
+
+	walkTrees(trees, func(tree *parse.Tree, node parse.Node) {
+		command, ok := node.(*parse.CommandNode)
+
+		if !ok || (err != nil) {
+			return
+		}
+
+		for index, argument := range command.Args {
+			var replaced parse.Node
+
+			if replaced, err = f.namespaceCall(tree, argument, functions, used); err != nil {
+				return
+			} else if replaced != nil {
+				command.Args[index] = replaced
+			}
+		}
+	})
+
+	return used, err
+}
+
+// namespaceCall returns identifier of function from function set for chain
+// like str.upper or nil for other nodes.
+func (f *config) namespaceCall(tree *parse.Tree, node parse.Node, functions []template.FuncMap,
+	used template.FuncMap) (parse.Node, error) {
+	chain, ok := node.(*parse.ChainNode)
+
+	if !ok {
+		return nil, nil
+	}
+
+	identifier, ok := chain.Node.(*parse.IdentifierNode)
+
+	if !ok {
+		return nil, nil
+	}
+
+	namespace, name := identifier.Ident, chain.Field[0]
+	set, ok := f.functionSets[namespace]
+
+	if !ok {
+		return nil, nil
+	}
+
+	if isDefined(namespace, functions) {
+		return nil, fmt.Errorf("%w: %q", ErrNamespaceConflict, namespace)
+	}
+
+	if (f.sandbox != nil) && !f.sandbox.isAllowed(namespace+"."+name) {
+		return nil, fmt.Errorf("%w: function %q", ErrSandboxViolation, namespace+"."+name)
+	}
+
+	function, ok := set[name]
+
+	if !ok {
+		names := make([]string, 0, len(set))
+
+		for candidate := range set {
+			names = append(names, candidate)
+		}
+
+		sort.Strings(names)
+
+		return nil, fmt.Errorf("function %q not found in function set %q%s", name, namespace,
+			didYouMean(suggestions(name, names)))
+	}
+
+	internal := namespacePrefix + namespace + "_" + name
+	used[internal] = function
+	called := parse.NewIdentifier(internal).SetTree(tree).SetPos(chain.Pos)
+
+	if len(chain.Field) == 1 {
+		return called, nil
+	}
+
+	return &parse.ChainNode{NodeType: parse.NodeChain, Pos: chain.Pos, Node: called, Field: chain.Field[1:]}, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"sync"
+)
+
+var (
+	gGlobalMutex     sync.RWMutex  // nolint: gochecknoglobals
+	gGlobalFunctions = Functions{} // nolint: gochecknoglobals
+)
+
+// RegisterGlobal registers template function that all formatters created
+// later with New pick up. It lets libraries and plugins contribute functions,
+// typically from init functions. Formatters created before are not changed.
+func RegisterGlobal(name string, function interface{}) {
+	RegisterGlobals(Functions{name: function})
+}
+
+// RegisterGlobals registers template functions that all formatters created
+// later with New pick up.
+func RegisterGlobals(functions Functions) {
+	gGlobalMutex.Lock()
+	defer gGlobalMutex.Unlock()
+
+	registered := copyFunctions(gGlobalFunctions)
+
+	for name, function := range functions {
+		registered[name] = function
+	}
+
+	gGlobalFunctions = registered
+}
+
+// UnregisterGlobal removes globally registered template function. Formatters
+// created before are not changed.
+func UnregisterGlobal(name string) {
+	gGlobalMutex.Lock()
+	defer gGlobalMutex.Unlock()
+
+	registered := copyFunctions(gGlobalFunctions)
+
+	delete(registered, name)
+
+	gGlobalFunctions = registered
+}
+
+// GlobalFunctions returns a copy of globally registered template functions.
+// Built-in functions are not included.
+func GlobalFunctions() Functions {
+	gGlobalMutex.RLock()
+	defer gGlobalMutex.RUnlock()
+
+	return copyFunctions(gGlobalFunctions)
+}
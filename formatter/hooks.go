@@ -0,0 +1,113 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"context"
+	"io"
+)
+
+// ArgumentHook transforms argument at provided position before formatting.
+// For arguments provided with Arg it gets argument value.
+type ArgumentHook func(position int, value interface{}) interface{}
+
+// OutputHook transforms formatted output.
+type OutputHook func(output string) string
+
+// SetArgumentHook sets hook called for every argument before formatting. It
+// can be used to redact secrets, truncate huge values or normalize unicode.
+// Lazy arguments are passed to hook after evaluation. Nil disables it.
+func (f *Formatter) SetArgumentHook(hook ArgumentHook) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.argumentHook = hook
+
+	return f
+}
+
+// GetArgumentHook returns hook called for every argument before formatting.
+func (f *Formatter) GetArgumentHook() ArgumentHook {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.argumentHook
+}
+
+// SetOutputHook sets hook called with formatted output before it is returned
+// or written. When it is set FormatWriter writes output at once after
+// formatting. Maximum output size applies to output before and after hook. It
+// is not used by FormatPartial. Nil disables it.
+func (f *Formatter) SetOutputHook(hook OutputHook) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.outputHook = hook
+
+	return f
+}
+
+// GetOutputHook returns hook called with formatted output.
+func (f *Formatter) GetOutputHook() OutputHook {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.outputHook
+}
+
+// hookArguments returns arguments transformed by argument hook.
+func (f *config) hookArguments(arguments []interface{}) []interface{} {
+	if (f.argumentHook == nil) || (len(arguments) == 0) {
+		return arguments
+	}
+
+	hooked := make([]interface{}, len(arguments))
+
+	for position, argument := range arguments {
+		hooked[position] = f.hookArgument(position, argument)
+	}
+
+	return hooked
+}
+
+func (f *config) hookArgument(position int, argument interface{}) interface{} {
+	if named, ok := argument.(Argument); ok {
+		return Argument{Name: named.Name, Value: f.hookArgument(position, named.Value)}
+	}
+
+	if value, ok := lazy(argument).(*lazyValue); ok {
+		return Lazy(func() interface{} {
+			return f.argumentHook(position, value.get())
+		})
+	}
+
+	return f.argumentHook(position, argument)
+}
+
+// hookOutput formats message to buffer and writes output transformed by
+// output hook.
+func (f *config) hookOutput(ctx context.Context, writer io.Writer, message string, arguments []interface{}) error {
+	buffer := getBuffer()
+	defer putBuffer(buffer)
+
+	c := *f
+	c.outputHook = nil
+
+	if err := c.formatMessage(ctx, buffer, message, arguments...); err != nil {
+		return err
+	}
+
+	return write(f.limitWriter(writer), f.outputHook(buffer.String()))
+}
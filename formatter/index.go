@@ -0,0 +1,136 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const indexFunction = "_index"
+
+// ErrIndexOutOfRange is returned when index like {items[5]} is out of range
+// of slice or array.
+const ErrIndexOutOfRange = fError("index out of range")
+
+// translateIndexes replaces index expressions like {p0[2]} or
+// {items[0].Name} in actions with calls to the index function.
+func (f *config) translateIndexes(message string) string {
+	if (f.leftDelimiter == "") || (f.rightDelimiter == "") || !strings.Contains(message, "[") {
+		return message
+	}
+
+	var builder strings.Builder
+
+	for _, s := range scan(message, f.leftDelimiter, f.rightDelimiter) {
+		if (s.kind == actionSegment) && strings.HasSuffix(s.text, f.rightDelimiter) &&
+			(len(s.text) >= len(f.leftDelimiter)+len(f.rightDelimiter)) {
+			inner := s.text[len(f.leftDelimiter) : len(s.text)-len(f.rightDelimiter)]
+			builder.WriteString(f.leftDelimiter + translateIndexAction(inner) + f.rightDelimiter)
+		} else {
+			builder.WriteString(s.text)
+		}
+	}
+
+	return builder.String()
+}
+
+// translateIndexAction replaces operands followed by non-negative integer
+// index in brackets with calls to the index function. Quoted strings and
+// comments are left intact.
+func translateIndexAction(action string) string {
+	var translated []byte
+
+	start, origin := -1, -1
+
+	for index := 0; index < len(action); {
+		c := action[index]
+
+		switch {
+		case (c == '"') || (c == '\'') || (c == '`'):
+			end := quotedEnd(action, index+1, c)
+			translated = append(translated, action[index:end]...)
+			index, start = end, -1
+		case strings.HasPrefix(action[index:], "/*"):
+			end := len(action)
+
+			if stop := strings.Index(action[index+2:], "*/"); stop >= 0 {
+				end = index + stop + 4
+			}
+
+			translated = append(translated, action[index:end]...)
+			index, start = end, -1
+		case (c == '[') && (start >= 0):
+			end := index + 1
+
+			for (end < len(action)) && (action[end] >= '0') && (action[end] <= '9') {
+				end++
+			}
+
+			if (end == index+1) || (end >= len(action)) || (action[end] != ']') {
+				translated = append(translated, c)
+				index, start = index+1, -1
+
+				continue
+			}
+
+			operand := string(translated[start:])
+			translated = append(translated[:start], "("+indexFunction+" "+strconv.Quote(action[origin:end+1])+" "+
+				operand+" "+action[index+1:end]+")"...)
+			index = end + 1
+		case isIdentifierByte(c) || (c == '.') || (c == '$'):
+			if start < 0 {
+				start, origin = len(translated), index
+			}
+
+			translated = append(translated, c)
+			index++
+		default:
+			translated = append(translated, c)
+			index, start = index+1, -1
+		}
+	}
+
+	return string(translated)
+}
+
+// index returns element of slice or array at position. Position out of range
+// is reported as ErrIndexOutOfRange.
+func (f *config) index(path string, object interface{}, position int) (interface{}, error) {
+	value := reflect.ValueOf(object)
+
+	for (value.Kind() == reflect.Ptr) || (value.Kind() == reflect.Interface) {
+		if value.IsNil() {
+			return nil, fmt.Errorf("cannot evaluate %q: nil pointer", path)
+		}
+
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		if (position < 0) || (position >= value.Len()) {
+			return nil, fmt.Errorf("cannot evaluate %q: %w: %d with length %d", path, ErrIndexOutOfRange, position, value.Len())
+		}
+
+		return value.Index(position).Interface(), nil
+	case reflect.Invalid:
+		return nil, fmt.Errorf("cannot evaluate %q: nil value cannot be indexed", path)
+	default:
+		return nil, fmt.Errorf("cannot evaluate %q: type %s cannot be indexed", path, value.Type())
+	}
+}
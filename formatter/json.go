@@ -0,0 +1,75 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DefaultJSONIndent defines indentation used by the jsonIndent function.
+const DefaultJSONIndent = "  "
+
+// jsonFunction is the json function registered in built-in functions. It is
+// replaced by formatter specific function during formatting.
+func jsonFunction(value interface{}) (string, error) {
+	return (&config{}).json(value)
+}
+
+// jsonIndentFunction is the jsonIndent function registered in built-in
+// functions. It is replaced by formatter specific function during formatting.
+func jsonIndentFunction(arguments ...interface{}) (string, error) {
+	return (&config{}).jsonIndent(arguments...)
+}
+
+// json marshals value to compact JSON. Redacted values are replaced.
+func (f *config) json(value interface{}) (string, error) {
+	return f.marshalJSON(value, "")
+}
+
+// jsonIndent marshals value to indented JSON. Optional first argument is
+// indentation, default is DefaultJSONIndent. Redacted values are replaced.
+func (f *config) jsonIndent(arguments ...interface{}) (string, error) {
+	switch len(arguments) {
+	case 1:
+		return f.marshalJSON(arguments[0], DefaultJSONIndent)
+	case 2:
+		indent, ok := arguments[0].(string)
+
+		if !ok {
+			return "", fmt.Errorf("JSON indentation must be a string, got %T", arguments[0])
+		}
+
+		return f.marshalJSON(arguments[1], indent)
+	default:
+		return "", fmt.Errorf("expected optional indentation and value, got %d arguments", len(arguments))
+	}
+}
+
+func (f *config) marshalJSON(value interface{}, indent string) (string, error) {
+	var buffer bytes.Buffer
+
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", indent)
+
+	if err := encoder.Encode(f.redactedCopy(evaluate(value))); err != nil {
+		return "", fmt.Errorf("cannot marshal %T to JSON: %w", value, err)
+	}
+
+	return strings.TrimSuffix(buffer.String(), "\n"), nil
+}
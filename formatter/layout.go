@@ -0,0 +1,90 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"context"
+	"fmt"
+	"text/template/parse"
+)
+
+// BodySlot is name of layout slot filled with body message.
+const BodySlot = "body"
+
+// FormatWithLayout formats layout message added with AddTemplate with slots
+// filled by body message added with AddTemplate. Layout defines slots with
+// {block "name" .}default content{end} or includes them with
+// {template "name"}. Body message fills the body slot and can fill other
+// slots with {define "name"}content{end}. Slots not filled by body use default
+// content from layout.
+func (f *Formatter) FormatWithLayout(layout, body string, arguments ...interface{}) (string, error) {
+	c, arguments := f.snapshot().withOptions(arguments)
+
+	layoutText, ok := c.templates[layout]
+
+	if !ok {
+		return "", fmt.Errorf("layout template %q is not defined", layout)
+	}
+
+	bodyText, ok := c.templates[body]
+
+	if !ok {
+		return "", fmt.Errorf("body template %q is not defined", body)
+	}
+
+	c.overrides = map[string]string{BodySlot: bodyText}
+
+	buffer := getBuffer()
+	defer putBuffer(buffer)
+
+	if err := c.formatWriter(context.Background(), buffer, layoutText, arguments...); err != nil {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
+
+// addOverrideTrees parses templates that override templates defined in
+// message like layout slots and adds them to trees.
+func (f *config) addOverrideTrees(trees map[string]*parse.Tree) error {
+	for name, text := range f.overrides {
+		overrides := make(map[string]*parse.Tree)
+
+		if err := parseTree(name, escapeDelimiters(text, f.leftDelimiter, f.rightDelimiter),
+			f.leftDelimiter, f.rightDelimiter, overrides); err != nil {
+			return err
+		}
+
+		for overrideName, tree := range overrides {
+			if (overrideName == name) || !parse.IsEmptyTree(tree.Root) {
+				trees[overrideName] = tree
+			}
+		}
+	}
+
+	return nil
+}
+
+// overridesText returns text of all overrides used to find referenced
+// positional placeholders.
+func (f *config) overridesText() string {
+	text := ""
+
+	for _, override := range f.overrides {
+		text += override
+	}
+
+	return text
+}
@@ -0,0 +1,208 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"fmt"
+	"io"
+	"text/template/parse"
+)
+
+// These errors are returned when formatting exceeds configured limits.
+const (
+	ErrOutputTooLarge = fError("formatted output exceeds maximum size")
+	ErrTooDeep        = fError("template exceeds maximum nesting depth")
+)
+
+// SetMaxOutputSize sets maximum size in bytes of formatted output. Formatting
+// that produces more output is aborted with ErrOutputTooLarge. Zero value
+// disables it. Default is 0.
+func (f *Formatter) SetMaxOutputSize(size int) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.maxOutputSize = size
+
+	return f
+}
+
+// GetMaxOutputSize returns maximum size in bytes of formatted output.
+func (f *Formatter) GetMaxOutputSize() int {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.maxOutputSize
+}
+
+// SetMaxDepth sets maximum nesting depth of if, range and with blocks,
+// parenthesized pipelines and template calls. Message that exceeds it is
+// rejected with ErrTooDeep before execution. Recursive template calls are
+// always rejected when it is set. Zero value disables it. Default is 0.
+func (f *Formatter) SetMaxDepth(depth int) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.maxDepth = depth
+
+	return f
+}
+
+// GetMaxDepth returns maximum nesting depth of templates.
+func (f *Formatter) GetMaxDepth() int {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.maxDepth
+}
+
+// limitWriter returns writer that fails with ErrOutputTooLarge when more than
+// maximum output size is written.
+func (f *config) limitWriter(writer io.Writer) io.Writer {
+	if f.maxOutputSize <= 0 {
+		return writer
+	}
+
+	return &limitedWriter{writer: writer, remaining: f.maxOutputSize}
+}
+
+// checkDepth returns ErrTooDeep if main tree exceeds maximum nesting depth.
+func (f *config) checkDepth(trees map[string]*parse.Tree) error {
+	if (f.maxDepth <= 0) || (trees[""] == nil) {
+		return nil
+	}
+
+	d := depth{trees: trees, visiting: make(map[string]bool), limit: f.maxDepth}
+
+	if d.list(trees[""].Root) > f.maxDepth {
+		return fmt.Errorf("%w: %d", ErrTooDeep, f.maxDepth)
+	}
+
+	return nil
+}
+
+type limitedWriter struct {
+	writer    io.Writer
+	remaining int
+}
+
+func (w *limitedWriter) Write(data []byte) (int, error) {
+	if len(data) > w.remaining {
+		return 0, ErrOutputTooLarge
+	}
+
+	w.remaining -= len(data)
+
+	return w.writer.Write(data)
+}
+
+func (w *limitedWriter) WriteString(text string) (int, error) {
+	if len(text) > w.remaining {
+		return 0, ErrOutputTooLarge
+	}
+
+	w.remaining -= len(text)
+
+	return io.WriteString(w.writer, text)
+}
+
+// depth computes nesting depth of parse nodes. Recursive template call is
+// reported as depth over the limit.
+type depth struct {
+	trees    map[string]*parse.Tree
+	visiting map[string]bool
+	limit    int
+}
+
+func (d *depth) list(list *parse.ListNode) (result int) {
+	if list == nil {
+		return 0
+	}
+
+	for _, node := range list.Nodes {
+		result = maxInt(result, d.node(node))
+	}
+
+	return result
+}
+
+func (d *depth) node(node parse.Node) int {
+	switch n := node.(type) {
+	case *parse.ActionNode:
+		return d.pipe(n.Pipe)
+	case *parse.IfNode:
+		return d.branch(&n.BranchNode)
+	case *parse.RangeNode:
+		return d.branch(&n.BranchNode)
+	case *parse.WithNode:
+		return d.branch(&n.BranchNode)
+	case *parse.TemplateNode:
+		return maxInt(d.pipe(n.Pipe), d.template(n.Name))
+	default:
+		return 0
+	}
+}
+
+func (d *depth) branch(branch *parse.BranchNode) int {
+	return 1 + maxInt(d.pipe(branch.Pipe), maxInt(d.list(branch.List), d.list(branch.ElseList)))
+}
+
+func (d *depth) pipe(pipe *parse.PipeNode) (result int) {
+	if pipe == nil {
+		return 0
+	}
+
+	for _, command := range pipe.Cmds {
+		for _, argument := range command.Args {
+			if nested, ok := argument.(*parse.PipeNode); ok {
+				result = maxInt(result, 1+d.pipe(nested))
+			}
+		}
+	}
+
+	return result
+}
+
+func (d *depth) template(name string) int {
+	tree := d.trees[name]
+
+	if tree == nil {
+		return 0
+	}
+
+	if d.visiting[name] {
+		return d.limit + 1
+	}
+
+	d.visiting[name] = true
+	defer delete(d.visiting, name)
+
+	return 1 + d.list(tree.Root)
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,107 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// joinAnd joins list items with commas and localized "and" conjunction like
+// "a, b, and c".
+func (l *Locale) joinAnd(list interface{}) string {
+	return l.joinConjunction(listItems(list), l.And, "and")
+}
+
+// joinOr joins list items with commas and localized "or" conjunction like
+// "a, b, or c".
+func (l *Locale) joinOr(list interface{}) string {
+	return l.joinConjunction(listItems(list), l.Or, "or")
+}
+
+func (l *Locale) joinConjunction(items []string, conjunction, fallback string) string {
+	if conjunction == "" {
+		conjunction = fallback
+	}
+
+	switch len(items) {
+	case 0:
+		return ""
+	case 1:
+		return items[0]
+	case 2:
+		return items[0] + " " + conjunction + " " + items[1]
+	}
+
+	separator := " "
+
+	if l.SerialComma {
+		separator = ", "
+	}
+
+	return strings.Join(items[:len(items)-1], ", ") + separator + conjunction + " " + items[len(items)-1]
+}
+
+// join joins list items with separator.
+func join(separator string, list interface{}) string {
+	return strings.Join(listItems(list), separator)
+}
+
+// bullets renders list items in separate lines prefixed with marker.
+func bullets(marker string, list interface{}) string {
+	items := listItems(list)
+
+	for index, item := range items {
+		items[index] = marker + " " + item
+	}
+
+	return strings.Join(items, "\n")
+}
+
+// numbered renders list items in separate lines prefixed with numbers
+// starting from 1.
+func numbered(list interface{}) string {
+	items := listItems(list)
+
+	for index, item := range items {
+		items[index] = strconv.Itoa(index+1) + ". " + item
+	}
+
+	return strings.Join(items, "\n")
+}
+
+// listItems returns string representations of slice or array elements. Other
+// values are returned as a single item and nil as no items.
+func listItems(list interface{}) []string {
+	if list == nil {
+		return nil
+	}
+
+	value := reflect.ValueOf(list)
+
+	if (value.Kind() != reflect.Slice) && (value.Kind() != reflect.Array) {
+		return []string{fmt.Sprint(list)}
+	}
+
+	items := make([]string, 0, value.Len())
+
+	for index := 0; index < value.Len(); index++ {
+		items = append(items, fmt.Sprint(value.Index(index).Interface()))
+	}
+
+	return items
+}
@@ -0,0 +1,270 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// DefaultLocale defines default locale used by formatter.
+const DefaultLocale = "en"
+
+// Plural returns index of plural form for given count.
+type Plural func(count int64) int
+
+// Locale defines language specific data used by built-in functions.
+type Locale struct {
+	Months      [12]string
+	ShortMonths [12]string
+	Days        [7]string
+	ShortDays   [7]string
+
+	// Plural selects plural form from TimeUnits and forms provided to the
+	// plural function. Default is English rule.
+	Plural Plural
+
+	// TimeUnits maps time unit (second, minute, hour, day, week, month, year)
+	// to its plural forms.
+	TimeUnits map[string][]string
+
+	// Ago, Until and JustNow are used by ago and until functions. Verb %s is
+	// replaced with count and time unit like "3 minutes".
+	Ago     string
+	Until   string
+	JustNow string
+
+	// And and Or are conjunctions used by joinAnd and joinOr functions.
+	// SerialComma adds comma before conjunction like in "a, b, and c".
+	And         string
+	Or          string
+	SerialComma bool
+
+	// Ordinal is used by the ordinal function. Default is English rule like
+	// 1st, 2nd or 3rd.
+	Ordinal Ordinal
+
+	// NumberWords is used by the spell function. Without it numbers are
+	// rendered as digits.
+	NumberWords NumberWords
+
+	// Yes and No are used by the yesno function, On and Off by the onoff
+	// function. Empty texts fall back to English.
+	Yes string
+	No  string
+	On  string
+	Off string
+}
+
+var gLocalesMutex sync.RWMutex // nolint: gochecknoglobals
+
+var gLocales = map[string]*Locale{ // nolint: gochecknoglobals
+	"en": {
+		Months: [12]string{
+			"January", "February", "March", "April", "May", "June",
+			"July", "August", "September", "October", "November", "December",
+		},
+		ShortMonths: [12]string{
+			"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec",
+		},
+		Days: [7]string{
+			"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday",
+		},
+		ShortDays: [7]string{
+			"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat",
+		},
+		Plural: pluralEnglish,
+		TimeUnits: map[string][]string{
+			"second": {"second", "seconds"},
+			"minute": {"minute", "minutes"},
+			"hour":   {"hour", "hours"},
+			"day":    {"day", "days"},
+			"week":   {"week", "weeks"},
+			"month":  {"month", "months"},
+			"year":   {"year", "years"},
+		},
+		Ago:         "%s ago",
+		Until:       "in %s",
+		JustNow:     "just now",
+		And:         "and",
+		Or:          "or",
+		SerialComma: true,
+		Ordinal:     ordinalEnglish,
+		NumberWords: spellEnglish,
+		Yes:         "yes",
+		No:          "no",
+		On:          "on",
+		Off:         "off",
+	},
+	"pl": {
+		Months: [12]string{
+			"stycznia", "lutego", "marca", "kwietnia", "maja", "czerwca",
+			"lipca", "sierpnia", "września", "października", "listopada", "grudnia",
+		},
+		ShortMonths: [12]string{
+			"sty", "lut", "mar", "kwi", "maj", "cze", "lip", "sie", "wrz", "paź", "lis", "gru",
+		},
+		Days: [7]string{
+			"niedziela", "poniedziałek", "wtorek", "środa", "czwartek", "piątek", "sobota",
+		},
+		ShortDays: [7]string{
+			"nie", "pon", "wto", "śro", "czw", "pią", "sob",
+		},
+		Plural: pluralPolish,
+		TimeUnits: map[string][]string{
+			"second": {"sekunda", "sekundy", "sekund"},
+			"minute": {"minuta", "minuty", "minut"},
+			"hour":   {"godzina", "godziny", "godzin"},
+			"day":    {"dzień", "dni", "dni"},
+			"week":   {"tydzień", "tygodnie", "tygodni"},
+			"month":  {"miesiąc", "miesiące", "miesięcy"},
+			"year":   {"rok", "lata", "lat"},
+		},
+		Ago:     "%s temu",
+		Until:   "za %s",
+		JustNow: "przed chwilą",
+		And:     "i",
+		Or:      "lub",
+		Ordinal: ordinalDot,
+		Yes:     "tak",
+		No:      "nie",
+		On:      "wł.",
+		Off:     "wył.",
+	},
+	"de": {
+		Months: [12]string{
+			"Januar", "Februar", "März", "April", "Mai", "Juni",
+			"Juli", "August", "September", "Oktober", "November", "Dezember",
+		},
+		ShortMonths: [12]string{
+			"Jan", "Feb", "Mär", "Apr", "Mai", "Jun", "Jul", "Aug", "Sep", "Okt", "Nov", "Dez",
+		},
+		Days: [7]string{
+			"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag",
+		},
+		ShortDays: [7]string{
+			"So", "Mo", "Di", "Mi", "Do", "Fr", "Sa",
+		},
+		Plural: pluralEnglish,
+		TimeUnits: map[string][]string{
+			"second": {"Sekunde", "Sekunden"},
+			"minute": {"Minute", "Minuten"},
+			"hour":   {"Stunde", "Stunden"},
+			"day":    {"Tag", "Tagen"},
+			"week":   {"Woche", "Wochen"},
+			"month":  {"Monat", "Monaten"},
+			"year":   {"Jahr", "Jahren"},
+		},
+		Ago:     "vor %s",
+		Until:   "in %s",
+		JustNow: "gerade eben",
+		And:     "und",
+		Or:      "oder",
+		Ordinal: ordinalDot,
+		Yes:     "ja",
+		No:      "nein",
+		On:      "an",
+		Off:     "aus",
+	},
+}
+
+// RegisterLocale registers locale under given name like "en" or "pl". It
+// replaces already registered locale with the same name.
+func RegisterLocale(name string, locale *Locale) {
+	gLocalesMutex.Lock()
+	defer gLocalesMutex.Unlock()
+
+	gLocales[normalizeLocale(name)] = locale
+}
+
+// GetLocale returns registered locale. It falls back to the language part of
+// name like "pl" for "pl-PL" and then to the default locale.
+func GetLocale(name string) *Locale {
+	gLocalesMutex.RLock()
+	defer gLocalesMutex.RUnlock()
+
+	name = normalizeLocale(name)
+
+	if locale, ok := gLocales[name]; ok {
+		return locale
+	}
+
+	if index := strings.Index(name, "-"); index > 0 {
+		if locale, ok := gLocales[name[:index]]; ok {
+			return locale
+		}
+	}
+
+	return gLocales[DefaultLocale]
+}
+
+func normalizeLocale(name string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "_", "-")
+}
+
+func (l *Locale) functions() template.FuncMap {
+	return template.FuncMap{
+		"date":    l.formatDate,
+		"ago":     l.relative,
+		"until":   l.relative,
+		"joinAnd": l.joinAnd,
+		"joinOr":  l.joinOr,
+		"ordinal": l.ordinal,
+		"spell":   l.spell,
+		"plural":  l.pluralForm,
+		"yesno":   l.yesNo,
+		"onoff":   l.onOff,
+	}
+}
+
+func (l *Locale) plural(count int64, forms []string) string {
+	if len(forms) == 0 {
+		return ""
+	}
+
+	rule := l.Plural
+
+	if rule == nil {
+		rule = pluralEnglish
+	}
+
+	index := rule(count)
+
+	if index < 0 || index >= len(forms) {
+		index = len(forms) - 1
+	}
+
+	return forms[index]
+}
+
+func pluralEnglish(count int64) int {
+	if count == 1 {
+		return 0
+	}
+
+	return 1
+}
+
+func pluralPolish(count int64) int {
+	switch {
+	case count == 1:
+		return 0
+	case (count%10 >= 2) && (count%10 <= 4) && ((count%100 < 12) || (count%100 > 14)):
+		return 1
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// logfmtFunction is the logfmt function registered in built-in functions. It
+// is replaced by formatter specific function during formatting.
+func logfmtFunction(value interface{}) (string, error) {
+	return (&config{}).logfmt(value)
+}
+
+// logfmt renders map with string keys or struct as sorted key=value pairs.
+// Values with spaces, quotes or equal signs are quoted. Nested maps and
+// structs are flattened with keys joined by dot. Redacted values are
+// replaced.
+func (f *config) logfmt(value interface{}) (string, error) {
+	pairs := make(map[string]string)
+
+	if err := f.logfmtPairs(pairs, "", reflect.ValueOf(f.redactedCopy(evaluate(value))), true); err != nil {
+		return "", err
+	}
+
+	keys := make([]string, 0, len(pairs))
+
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for index, key := range keys {
+		keys[index] = key + "=" + pairs[key]
+	}
+
+	return strings.Join(keys, " "), nil
+}
+
+func (f *config) logfmtPairs(pairs map[string]string, prefix string, value reflect.Value, top bool) error {
+	for value.IsValid() && ((value.Kind() == reflect.Interface) || (value.Kind() == reflect.Ptr)) && !value.IsNil() &&
+		!implementsPrinter(value.Type()) {
+		value = value.Elem()
+	}
+
+	switch {
+	case !value.IsValid() || implementsPrinter(value.Type()):
+	case (value.Kind() == reflect.Map) && (value.Type().Key().Kind() == reflect.String):
+		for _, key := range value.MapKeys() {
+			if err := f.logfmtPairs(pairs, logfmtKey(prefix, key.String()), value.MapIndex(key), false); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case value.Kind() == reflect.Struct:
+		for index := 0; index < value.NumField(); index++ {
+			if field := value.Type().Field(index); field.PkgPath == "" {
+				if err := f.logfmtPairs(pairs, logfmtKey(prefix, field.Name), value.Field(index), false); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+
+	if top {
+		return fmt.Errorf("logfmt value must be a map with string keys or a struct, got %s", valueType(value))
+	}
+
+	if isNil(value) {
+		pairs[prefix] = logfmtValue(nil)
+		return nil
+	}
+
+	rendered, err := f.formatValue(valueInterface(value))
+
+	if err != nil {
+		return err
+	}
+
+	pairs[prefix] = logfmtValue(rendered)
+
+	return nil
+}
+
+func logfmtKey(prefix, key string) string {
+	key = strings.Map(func(r rune) rune {
+		if (r == '=') || (r == '"') || unicode.IsSpace(r) || unicode.IsControl(r) {
+			return '_'
+		}
+
+		return r
+	}, key)
+
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + "." + key
+}
+
+func logfmtValue(value interface{}) string {
+	if value == nil {
+		return "null"
+	}
+
+	text := fmt.Sprint(value)
+
+	if (text == "") || strings.ContainsAny(text, " =\"\\") || strings.IndexFunc(text, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsControl(r)
+	}) >= 0 {
+		return strconv.Quote(text)
+	}
+
+	return text
+}
+
+func valueType(value reflect.Value) string {
+	if !value.IsValid() {
+		return "nil"
+	}
+
+	return value.Type().String()
+}
+
+func isNil(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return value.IsNil()
+	default:
+		return false
+	}
+}
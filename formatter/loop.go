@@ -0,0 +1,62 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"strings"
+)
+
+// eachKeyword starts loop action like {each items} that is translated to
+// range action.
+const eachKeyword = "each"
+
+// translateEach replaces loop actions like {each items} or
+// {each $key, $value := items} with range actions. Loop ends with {end} and
+// can contain {else} printed for empty values.
+func (f *config) translateEach(message string) string {
+	if (f.leftDelimiter == "") || (f.rightDelimiter == "") || !strings.Contains(message, eachKeyword) {
+		return message
+	}
+
+	var builder strings.Builder
+
+	for _, s := range scan(message, f.leftDelimiter, f.rightDelimiter) {
+		if s.kind == actionSegment {
+			builder.WriteString(f.translateEachAction(s.text))
+		} else {
+			builder.WriteString(s.text)
+		}
+	}
+
+	return builder.String()
+}
+
+// translateEachAction replaces each keyword followed by white space at the
+// beginning of action with range keyword.
+func (f *config) translateEachAction(action string) string {
+	if !strings.HasSuffix(action, f.rightDelimiter) || (len(action) < len(f.leftDelimiter)+len(f.rightDelimiter)) {
+		return action
+	}
+
+	inner := action[len(f.leftDelimiter) : len(action)-len(f.rightDelimiter)]
+	body := strings.TrimLeft(strings.TrimPrefix(inner, trimLeftMarker), " \t")
+	rest := strings.TrimPrefix(body, eachKeyword)
+
+	if (len(rest) == len(body)) || (strings.IndexAny(rest, " \t") != 0) {
+		return action
+	}
+
+	return f.leftDelimiter + inner[:len(inner)-len(body)] + "range" + rest + f.rightDelimiter
+}
@@ -0,0 +1,153 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"fmt"
+	"strings"
+	"text/template/parse"
+)
+
+const markdownEscapeFunction = "_markdownEscape"
+
+// markdownSpecial defines characters escaped with backslash in Markdown text.
+const markdownSpecial = "\\`*_{}[]<>()#+-.!|~"
+
+// Markdown defines text with Markdown markup that is not escaped again in
+// Markdown-safe mode. It is returned by Markdown helper functions.
+type Markdown string
+
+// SetSafeMarkdown enables or disables Markdown-safe mode. In Markdown-safe
+// mode all interpolated values are escaped for Markdown contexts, values of
+// type Markdown are written as they are.
+func (f *Formatter) SetSafeMarkdown(enabled bool) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.safeMarkdown = enabled
+
+	return f
+}
+
+// IsSafeMarkdown returns true if Markdown-safe mode is enabled.
+func (f *Formatter) IsSafeMarkdown() bool {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.safeMarkdown
+}
+
+// transformMarkdown appends call of the Markdown escape function to every
+// action that prints value.
+func transformMarkdown(trees map[string]*parse.Tree) {
+	walkTrees(trees, func(tree *parse.Tree, node parse.Node) {
+		if action, ok := node.(*parse.ActionNode); ok && (len(action.Pipe.Decl) == 0) {
+			action.Pipe.Cmds = append(action.Pipe.Cmds, &parse.CommandNode{
+				NodeType: parse.NodeCommand,
+				Pos:      action.Pos,
+				Args:     []parse.Node{parse.NewIdentifier(markdownEscapeFunction).SetTree(tree).SetPos(action.Pos)},
+			})
+		}
+	})
+}
+
+// mdEscape escapes Markdown special characters in value with backslash.
+// Values of type Markdown are returned unchanged.
+func mdEscape(value interface{}) Markdown {
+	if markdown, ok := value.(Markdown); ok {
+		return markdown
+	}
+
+	text := fmt.Sprint(evaluate(value))
+
+	var builder strings.Builder
+
+	for _, r := range text {
+		if strings.ContainsRune(markdownSpecial, r) {
+			builder.WriteByte('\\')
+		}
+
+		builder.WriteRune(r)
+	}
+
+	return Markdown(builder.String())
+}
+
+func mdBold(value interface{}) Markdown {
+	return "**" + mdEscape(value) + "**"
+}
+
+// mdCode returns value as inline code. Fence is longer than any run of
+// backticks in value.
+func mdCode(value interface{}) Markdown {
+	text := fmt.Sprint(evaluate(value))
+	longest, run := 0, 0
+
+	for _, r := range text {
+		if r == '`' {
+			run++
+			longest = maxInt(longest, run)
+		} else {
+			run = 0
+		}
+	}
+
+	fence := strings.Repeat("`", longest+1)
+
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		text = " " + text + " "
+	}
+
+	return Markdown(fence + text + fence)
+}
+
+// mdLink returns link with escaped text. Spaces and parentheses in address
+// are percent-encoded.
+func mdLink(text, address interface{}) Markdown {
+	escaped := strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(fmt.Sprint(evaluate(address)))
+
+	return "[" + mdEscape(text) + "](" + Markdown(escaped) + ")"
+}
+
+// mdTableFunction is the mdTable function registered in built-in functions.
+// It is replaced by formatter specific function during formatting.
+func mdTableFunction(rows interface{}, headers ...string) (Markdown, error) {
+	return (&config{}).mdTable(rows, headers...)
+}
+
+// mdTable renders rows as Markdown table with escaped headers and cells.
+func (f *config) mdTable(rows interface{}, headers ...string) (Markdown, error) {
+	cells, headers, err := f.tableCells(headers, rows)
+
+	if err != nil {
+		return "", err
+	}
+
+	escaped := make([]string, 0, len(headers))
+
+	for _, header := range headers {
+		escaped = append(escaped, string(mdEscape(header)))
+	}
+
+	for _, row := range cells {
+		for index, cell := range row {
+			row[index] = string(mdEscape(cell))
+		}
+	}
+
+	rendered, err := f.renderCells(escaped, cells, TableOptions{Style: MarkdownTable, Align: f.tableOptions.Align})
+
+	return Markdown(rendered), err
+}
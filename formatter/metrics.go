@@ -0,0 +1,121 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"io"
+	"time"
+)
+
+// These constants define names of caches reported to metrics sink.
+const (
+	// RedactionCache holds struct types that have fields tagged to redact.
+	RedactionCache = "redaction"
+
+	// FieldCache holds struct fields looked up by name.
+	FieldCache = "field"
+)
+
+// MetricsSink receives metrics of formatting operations. It can be used to
+// export metrics to monitoring systems. Methods can be called concurrently.
+type MetricsSink interface {
+	// FormatDuration is called with duration of every formatting.
+	FormatDuration(duration time.Duration)
+
+	// OutputSize is called with size in bytes of every formatted output.
+	OutputSize(size int)
+
+	// ParseError is called when message cannot be parsed.
+	ParseError(err error)
+
+	// CacheHit is called when value is found in named cache.
+	CacheHit(cache string)
+
+	// CacheMiss is called when value is not found in named cache.
+	CacheMiss(cache string)
+}
+
+// SetMetrics sets metrics sink called during formatting. Formatting
+// duration and output size are reported for Format, FormatWriter,
+// FormatContext, FormatPartial, FormatWithLayout and Sprintf. Nil disables it.
+func (f *Formatter) SetMetrics(metrics MetricsSink) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.metrics = metrics
+
+	return f
+}
+
+// GetMetrics returns metrics sink called during formatting.
+func (f *Formatter) GetMetrics() MetricsSink {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.metrics
+}
+
+// measure calls format with writer counting written bytes and reports
+// formatting duration and output size to metrics sink.
+func (f *config) measure(writer io.Writer, format func(writer io.Writer) error) error {
+	if f.metrics == nil {
+		return format(writer)
+	}
+
+	counter := &countingWriter{writer: writer}
+	start := time.Now()
+
+	err := format(counter)
+
+	f.metrics.FormatDuration(time.Since(start))
+	f.metrics.OutputSize(counter.size)
+
+	return err
+}
+
+func (f *config) reportParseError(err error) {
+	if (f.metrics != nil) && (err != nil) {
+		f.metrics.ParseError(err)
+	}
+}
+
+func (f *config) reportCache(cache string, hit bool) {
+	switch {
+	case f.metrics == nil:
+	case hit:
+		f.metrics.CacheHit(cache)
+	default:
+		f.metrics.CacheMiss(cache)
+	}
+}
+
+type countingWriter struct {
+	writer io.Writer
+	size   int
+}
+
+func (w *countingWriter) Write(data []byte) (int, error) {
+	n, err := w.writer.Write(data)
+	w.size += n
+
+	return n, err
+}
+
+func (w *countingWriter) WriteString(text string) (int, error) {
+	n, err := io.WriteString(w.writer, text)
+	w.size += n
+
+	return n, err
+}
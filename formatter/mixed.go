@@ -0,0 +1,73 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"fmt"
+	"text/template/parse"
+)
+
+// ErrMixedPlaceholders is returned in strict placeholders mode when message
+// mixes automatic and positional placeholders.
+const ErrMixedPlaceholders = fError("automatic and positional placeholders are mixed")
+
+// SetStrictPlaceholders enables or disables strict placeholders mode. In
+// strict placeholders mode message that mixes automatic placeholder like {p}
+// with positional placeholders like {p0} is reported as ErrMixedPlaceholders.
+func (f *Formatter) SetStrictPlaceholders(enabled bool) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.strictPlaceholders = enabled
+
+	return f
+}
+
+// IsStrictPlaceholders returns true if strict placeholders mode is enabled.
+func (f *Formatter) IsStrictPlaceholders() bool {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.strictPlaceholders
+}
+
+// checkMixedPlaceholders returns ErrMixedPlaceholders with locations of
+// first automatic and positional placeholders if both are used.
+func (f *config) checkMixedPlaceholders(trees map[string]*parse.Tree) error {
+	if !f.strictPlaceholders {
+		return nil
+	}
+
+	var automatic, positional string
+
+	walkTrees(trees, func(tree *parse.Tree, node parse.Node) {
+		identifier, ok := node.(*parse.IdentifierNode)
+
+		switch {
+		case !ok:
+		case (identifier.Ident == f.placeholder) && (automatic == ""):
+			automatic, _ = tree.ErrorContext(node)
+		case (f.position(identifier.Ident) >= 0) && (positional == ""):
+			location, _ := tree.ErrorContext(node)
+			positional = fmt.Sprintf("%q at %s", identifier.Ident, location)
+		}
+	})
+
+	if (automatic != "") && (positional != "") {
+		return fmt.Errorf("%w: %q at %s and %s", ErrMixedPlaceholders, f.placeholder, automatic, positional)
+	}
+
+	return nil
+}
@@ -0,0 +1,179 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// NumberWords returns number spelled out in words like forty-two.
+type NumberWords func(number int64) string
+
+// Ordinal returns ordinal number like 1st, 2nd or 3rd.
+type Ordinal func(number int64) string
+
+var gEnglishOnes = []string{ // nolint: gochecknoglobals
+	"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten",
+	"eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen",
+}
+
+var gEnglishTens = []string{ // nolint: gochecknoglobals
+	"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety",
+}
+
+var gEnglishScales = []string{ // nolint: gochecknoglobals
+	"", "thousand", "million", "billion", "trillion", "quadrillion", "quintillion",
+}
+
+// ordinal renders integer as ordinal number using locale rule. Locales without
+// rule use English rule.
+func (l *Locale) ordinal(value interface{}) (string, error) {
+	number, err := toInt(value)
+
+	if err != nil {
+		return "", err
+	}
+
+	if l.Ordinal != nil {
+		return l.Ordinal(number), nil
+	}
+
+	return ordinalEnglish(number), nil
+}
+
+// spell renders integer spelled out in words using locale rule. Locales
+// without rule render digits.
+func (l *Locale) spell(value interface{}) (string, error) {
+	number, err := toInt(value)
+
+	if err != nil {
+		return "", err
+	}
+
+	if l.NumberWords != nil {
+		return l.NumberWords(number), nil
+	}
+
+	return strconv.FormatInt(number, 10), nil
+}
+
+func ordinalEnglish(number int64) string {
+	rest := number % 100
+
+	if rest < 0 {
+		rest = -rest
+	}
+
+	suffix := "th"
+
+	switch {
+	case (rest >= 11) && (rest <= 13):
+	case rest%10 == 1:
+		suffix = "st"
+	case rest%10 == 2:
+		suffix = "nd"
+	case rest%10 == 3:
+		suffix = "rd"
+	}
+
+	return strconv.FormatInt(number, 10) + suffix
+}
+
+func ordinalDot(number int64) string {
+	return strconv.FormatInt(number, 10) + "."
+}
+
+func spellEnglish(number int64) string {
+	if number == 0 {
+		return gEnglishOnes[0]
+	}
+
+	prefix := ""
+	magnitude := uint64(number)
+
+	if number < 0 {
+		prefix = "minus "
+		magnitude = uint64(-(number + 1)) + 1
+	}
+
+	var groups []string
+
+	for scale := 0; magnitude > 0; scale++ {
+		if group := magnitude % 1000; group > 0 {
+			words := spellEnglishHundreds(int(group))
+
+			if gEnglishScales[scale] != "" {
+				words += " " + gEnglishScales[scale]
+			}
+
+			groups = append([]string{words}, groups...)
+		}
+
+		magnitude /= 1000
+	}
+
+	return prefix + strings.Join(groups, " ")
+}
+
+// spellEnglishHundreds spells number from 1 to 999.
+func spellEnglishHundreds(number int) string {
+	var words []string
+
+	if number >= 100 {
+		words = append(words, gEnglishOnes[number/100]+" hundred")
+		number %= 100
+	}
+
+	switch {
+	case number == 0:
+	case number < len(gEnglishOnes):
+		words = append(words, gEnglishOnes[number])
+	case number%10 == 0:
+		words = append(words, gEnglishTens[number/10])
+	default:
+		words = append(words, gEnglishTens[number/10]+"-"+gEnglishOnes[number%10])
+	}
+
+	return strings.Join(words, " ")
+}
+
+// toInt converts integer numbers and floating point numbers without fraction
+// to int64.
+func toInt(value interface{}) (int64, error) {
+	valueOf := reflect.ValueOf(value)
+
+	switch valueOf.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return valueOf.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if valueOf.Uint() > math.MaxInt64 {
+			return 0, fmt.Errorf("number %d is too large", valueOf.Uint())
+		}
+
+		return int64(valueOf.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		if number := valueOf.Float(); (number == math.Trunc(number)) && (math.Abs(number) < math.MaxInt64) {
+			return int64(number), nil
+		}
+
+		return 0, fmt.Errorf("number %v is not an integer", value)
+	default:
+		return 0, fmt.Errorf("expected integer, got %T", value)
+	}
+}
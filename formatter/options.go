@@ -0,0 +1,386 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"reflect"
+	"time"
+)
+
+// Option defines formatter option used by New. Options can be also passed
+// among arguments to change formatter configuration only for single call.
+// They are removed from arguments before formatting.
+type Option func(f *Formatter)
+
+// withOptions returns copy of configuration with applied options found in
+// arguments and arguments without options.
+func (f *config) withOptions(arguments []interface{}) (*config, []interface{}) {
+	var override *Formatter
+
+	var filtered []interface{}
+
+	for position, argument := range arguments {
+		option, ok := argument.(Option)
+
+		if !ok {
+			if override != nil {
+				filtered = append(filtered, argument)
+			}
+
+			continue
+		}
+
+		if override == nil {
+			override = &Formatter{config: *f}
+			filtered = append(make([]interface{}, 0, len(arguments)), arguments[:position]...)
+		}
+
+		option(override)
+	}
+
+	if override == nil {
+		return f, arguments
+	}
+
+	return &override.config, filtered
+}
+
+// WithPlaceholder sets placeholder string prefix used for automatic and
+// positional placeholders.
+func WithPlaceholder(placeholder string) Option {
+	return func(f *Formatter) {
+		f.SetPlaceholder(placeholder)
+	}
+}
+
+// WithDelimiters sets delimiters used by formatter.
+func WithDelimiters(left, right string) Option {
+	return func(f *Formatter) {
+		f.SetDelimiters(left, right)
+	}
+}
+
+// WithFunctions adds template functions used by formatter.
+func WithFunctions(functions Functions) Option {
+	return func(f *Formatter) {
+		f.AddFunctions(functions)
+	}
+}
+
+// WithFunction adds template function used by formatter.
+func WithFunction(name string, function interface{}) Option {
+	return func(f *Formatter) {
+		f.AddFunction(name, function)
+	}
+}
+
+// WithLocale sets locale used by built-in functions.
+func WithLocale(locale string) Option {
+	return func(f *Formatter) {
+		f.SetLocale(locale)
+	}
+}
+
+// WithColorMode sets color mode used by built-in text and color functions.
+func WithColorMode(mode ColorMode) Option {
+	return func(f *Formatter) {
+		f.SetColorMode(mode)
+	}
+}
+
+// WithSafeHTML enables HTML-safe mode.
+func WithSafeHTML() Option {
+	return func(f *Formatter) {
+		f.SetSafeHTML(true)
+	}
+}
+
+// WithStrict enables strict mode.
+func WithStrict() Option {
+	return func(f *Formatter) {
+		f.SetStrict(true)
+	}
+}
+
+// WithExecutionTimeout sets maximum duration of template execution.
+func WithExecutionTimeout(timeout time.Duration) Option {
+	return func(f *Formatter) {
+		f.SetExecutionTimeout(timeout)
+	}
+}
+
+// WithMaxOutputSize sets maximum size in bytes of formatted output.
+func WithMaxOutputSize(size int) Option {
+	return func(f *Formatter) {
+		f.SetMaxOutputSize(size)
+	}
+}
+
+// WithMaxDepth sets maximum nesting depth of templates.
+func WithMaxDepth(depth int) Option {
+	return func(f *Formatter) {
+		f.SetMaxDepth(depth)
+	}
+}
+
+// WithPseudoLocalization enables pseudo-localization mode.
+func WithPseudoLocalization() Option {
+	return func(f *Formatter) {
+		f.SetPseudoLocalization(true)
+	}
+}
+
+// WithArgumentHook sets hook called for every argument before formatting.
+func WithArgumentHook(hook ArgumentHook) Option {
+	return func(f *Formatter) {
+		f.SetArgumentHook(hook)
+	}
+}
+
+// WithOutputHook sets hook called with formatted output.
+func WithOutputHook(hook OutputHook) Option {
+	return func(f *Formatter) {
+		f.SetOutputHook(hook)
+	}
+}
+
+// WithRedact registers types whose values are always rendered as
+// RedactedText.
+func WithRedact(types ...reflect.Type) Option {
+	return func(f *Formatter) {
+		f.Redact(types...)
+	}
+}
+
+// WithMissingHandler sets handler called when named placeholder has no
+// argument.
+func WithMissingHandler(handler MissingHandler) Option {
+	return func(f *Formatter) {
+		f.SetMissingHandler(handler)
+	}
+}
+
+// WithResolver adds resolvers of named placeholders without arguments.
+func WithResolver(resolvers ...Resolver) Option {
+	return func(f *Formatter) {
+		f.AddResolver(resolvers...)
+	}
+}
+
+// WithEnvAllowlist enables access to environment variables matching provided
+// patterns.
+func WithEnvAllowlist(patterns ...string) Option {
+	return func(f *Formatter) {
+		f.SetEnvAllowlist(patterns...)
+	}
+}
+
+// WithTrimWhitespace enables trimming of whitespace around control actions.
+func WithTrimWhitespace() Option {
+	return func(f *Formatter) {
+		f.SetTrimWhitespace(true)
+	}
+}
+
+// WithTableOptions sets options used by the table function.
+func WithTableOptions(options TableOptions) Option {
+	return func(f *Formatter) {
+		f.SetTableOptions(options)
+	}
+}
+
+// WithWidthAlgorithm sets algorithm used to measure width of text.
+func WithWidthAlgorithm(algorithm WidthAlgorithm) Option {
+	return func(f *Formatter) {
+		f.SetWidthAlgorithm(algorithm)
+	}
+}
+
+// WithDumpOptions sets options used by the dump function.
+func WithDumpOptions(options DumpOptions) Option {
+	return func(f *Formatter) {
+		f.SetDumpOptions(options)
+	}
+}
+
+// WithSafeMarkdown enables Markdown-safe mode.
+func WithSafeMarkdown() Option {
+	return func(f *Formatter) {
+		f.SetSafeMarkdown(true)
+	}
+}
+
+// WithNilText sets text rendered for nil values.
+func WithNilText(text string) Option {
+	return func(f *Formatter) {
+		f.SetNilText(text)
+	}
+}
+
+// WithZeroRenderer sets renderer used for zero values of provided type.
+func WithZeroRenderer(t reflect.Type, renderer ZeroRenderer) Option {
+	return func(f *Formatter) {
+		f.SetZeroRenderer(t, renderer)
+	}
+}
+
+// WithBoolStrings sets texts rendered for true and false boolean values.
+func WithBoolStrings(trueText, falseText string) Option {
+	return func(f *Formatter) {
+		f.SetBoolStrings(trueText, falseText)
+	}
+}
+
+// WithStackOptions sets options used by the stack function.
+func WithStackOptions(options StackOptions) Option {
+	return func(f *Formatter) {
+		f.SetStackOptions(options)
+	}
+}
+
+// WithFmtVerb sets fmt verb used to render values implementing fmt.Formatter
+// or fmt.GoStringer.
+func WithFmtVerb(verb string) Option {
+	return func(f *Formatter) {
+		f.SetFmtVerb(verb)
+	}
+}
+
+// WithUseStringer enables or disables calling String method of arguments
+// implementing fmt.Stringer.
+func WithUseStringer(enabled bool) Option {
+	return func(f *Formatter) {
+		f.SetUseStringer(enabled)
+	}
+}
+
+// WithPlaceholderStyle sets syntax of automatic and positional placeholders.
+func WithPlaceholderStyle(style PlaceholderStyle) Option {
+	return func(f *Formatter) {
+		f.SetPlaceholderStyle(style)
+	}
+}
+
+// WithStrictPlaceholders enables strict placeholders mode.
+func WithStrictPlaceholders() Option {
+	return func(f *Formatter) {
+		f.SetStrictPlaceholders(true)
+	}
+}
+
+// WithConflictPolicy sets policy used when many arguments define the same
+// placeholder name.
+func WithConflictPolicy(policy ConflictPolicy) Option {
+	return func(f *Formatter) {
+		f.SetConflictPolicy(policy)
+	}
+}
+
+// WithUnusedPrefix sets text written before appended unused arguments.
+func WithUnusedPrefix(prefix string) Option {
+	return func(f *Formatter) {
+		f.SetUnusedPrefix(prefix)
+	}
+}
+
+// WithUnusedSeparator sets text written between appended unused arguments.
+func WithUnusedSeparator(separator string) Option {
+	return func(f *Formatter) {
+		f.SetUnusedSeparator(separator)
+	}
+}
+
+// WithMetrics sets metrics sink called during formatting.
+func WithMetrics(metrics MetricsSink) Option {
+	return func(f *Formatter) {
+		f.SetMetrics(metrics)
+	}
+}
+
+// WithTracer sets tracer that starts spans around parsing and execution of
+// messages.
+func WithTracer(tracer Tracer) Option {
+	return func(f *Formatter) {
+		f.SetTracer(tracer)
+	}
+}
+
+// WithEngine sets engine that executes parsed message.
+func WithEngine(engine Engine) Option {
+	return func(f *Formatter) {
+		f.SetEngine(engine)
+	}
+}
+
+// WithWriteBufferSize sets size of buffer used to write formatted output to
+// writers that are not buffered.
+func WithWriteBufferSize(size int) Option {
+	return func(f *Formatter) {
+		f.SetWriteBufferSize(size)
+	}
+}
+
+// WithBatchWorkers sets number of workers used by FormatAll.
+func WithBatchWorkers(workers int) Option {
+	return func(f *Formatter) {
+		f.SetBatchWorkers(workers)
+	}
+}
+
+// WithAddedDelimiters adds additional pair of delimiters recognized together
+// with main delimiters.
+func WithAddedDelimiters(left, right string) Option {
+	return func(f *Formatter) {
+		f.AddDelimiters(left, right)
+	}
+}
+
+// WithFunctionSet adds group of template functions under provided namespace.
+func WithFunctionSet(namespace string, functions Functions) Option {
+	return func(f *Formatter) {
+		f.AddFunctionSet(namespace, functions)
+	}
+}
+
+// WithFunctionPolicy sets policy used when template function name collides
+// with a built-in function, a placeholder or an existing function.
+func WithFunctionPolicy(policy FunctionPolicy) Option {
+	return func(f *Formatter) {
+		f.SetFunctionPolicy(policy)
+	}
+}
+
+// WithMethodCalls enables or disables calling exported methods of arguments.
+func WithMethodCalls(enabled bool) Option {
+	return func(f *Formatter) {
+		f.SetMethodCalls(enabled)
+	}
+}
+
+// WithNameTag sets struct tag like json used to resolve struct fields by
+// names set in tags.
+func WithNameTag(tag string) Option {
+	return func(f *Formatter) {
+		f.SetNameTag(tag)
+	}
+}
+
+// WithCaseInsensitive enables or disables case-insensitive resolution of
+// placeholders.
+func WithCaseInsensitive(enabled bool) Option {
+	return func(f *Formatter) {
+		f.SetCaseInsensitive(enabled)
+	}
+}
@@ -0,0 +1,310 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+const (
+	partialLiteralFunction = "_partialLiteral"
+	partialEscapeFunction  = "_partialEscape"
+)
+
+// FormatPartial formats string like Format but it leaves replacement fields
+// that cannot be resolved intact, together with original delimiters, for a
+// later formatting pass. Values and escaped delimiters are escaped again.
+// Unused arguments are not appended.
+func FormatPartial(message string, arguments ...interface{}) (string, error) {
+	return New().FormatPartial(message, arguments...)
+}
+
+// FormatPartial formats string like Format but it leaves replacement fields
+// that cannot be resolved intact, together with original delimiters, for a
+// later formatting pass. Values and escaped delimiters are escaped again.
+// Unused arguments are not appended.
+func (f *Formatter) FormatPartial(message string, arguments ...interface{}) (string, error) {
+	c, arguments := f.snapshot().withOptions(arguments)
+
+	return c.formatPartial(message, arguments...)
+}
+
+func (f *config) formatPartial(message string, arguments ...interface{}) (string, error) {
+	buffer := getBuffer()
+	defer putBuffer(buffer)
+
+	if err := f.measure(buffer, func(writer io.Writer) error {
+		return f.formatPartialWriter(writer, message, arguments...)
+	}); err != nil {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
+
+func (f *config) formatPartialWriter(writer io.Writer, message string, arguments ...interface{}) error {
+	message = f.translateText(message)
+	key := TemplateKey(message)
+
+	arguments = lazyArguments(f.hookArguments(f.redactArguments(arguments)))
+
+	if err := f.checkConflicts(arguments); err != nil {
+		return err
+	}
+
+	used := make(map[int]bool)
+	placeholders, object := f.placeholders(message, used, arguments)
+
+	if err := f.checkFunctionConflicts(placeholders); err != nil {
+		return err
+	}
+
+	functions := f.functionMaps(writer, placeholders)
+
+	if resolved := f.resolveMessage(message, functions); resolved != nil {
+		functions = append(functions, resolved)
+	}
+
+	writer = f.limitWriter(writer)
+
+	message = f.translateIndexes(f.translateEach(f.preserveUnresolved(message, functions, object != nil, len(arguments))))
+
+	trees, functions, err := f.parseTraced(context.Background(), key, message, append(functions, template.FuncMap{
+		partialLiteralFunction: partialLiteral,
+		partialEscapeFunction:  f.partialEscape,
+	}))
+
+	if err != nil {
+		return err
+	}
+
+	walkTrees(trees, func(tree *parse.Tree, node parse.Node) {
+		if action, ok := node.(*parse.ActionNode); ok && (len(action.Pipe.Decl) == 0) && !isPartialLiteral(action) {
+			action.Pipe.Cmds = append(action.Pipe.Cmds, &parse.CommandNode{
+				NodeType: parse.NodeCommand,
+				Pos:      action.Pos,
+				Args:     []parse.Node{parse.NewIdentifier(partialEscapeFunction).SetTree(tree).SetPos(action.Pos)},
+			})
+		}
+	})
+
+	return f.executeTraced(context.Background(), key, writer, trees, functions, object)
+}
+
+// preserveUnresolved replaces actions that cannot be resolved and escaped
+// delimiters with actions printing them literally. Control blocks like
+// {if premium}...{end} or {each items}...{end} are printed literally as a
+// whole when their conditions or pipelines cannot be resolved. Automatic
+// placeholders like {p} are resolved in order until provided count of
+// arguments is used.
+func (f *config) preserveUnresolved(message string, functions []template.FuncMap, hasObject bool, count int) string {
+	var builder strings.Builder
+
+	blocks := []bool{}
+	segments := scan(message, f.leftDelimiter, f.rightDelimiter)
+
+	for index := 0; index < len(segments); index++ {
+		s := segments[index]
+
+		switch s.kind {
+		case actionSegment:
+			keyword := actionKeyword(s.text, f.leftDelimiter, f.rightDelimiter)
+
+			switch keyword {
+			case "if", "range", "with", eachKeyword:
+				if end, ok := f.unresolvedBlock(segments, index, functions, hasObject || isDotChanged(blocks)); ok {
+					builder.WriteString(f.partialLiteral(joinSegments(segments[index : end+1])))
+					index = end
+
+					continue
+				}
+			}
+
+			switch keyword {
+			case "if", "define", "block":
+				blocks = append(blocks, false)
+			case "range", "with", eachKeyword:
+				blocks = append(blocks, true)
+			case "end":
+				if len(blocks) > 0 {
+					blocks = blocks[:len(blocks)-1]
+				}
+			}
+
+			if keyword != "" {
+				builder.WriteString(s.text)
+				continue
+			}
+
+			automatic := f.automaticCount(s.text)
+
+			if (automatic > count) || !f.isResolved(s.text, functions, hasObject || isDotChanged(blocks)) {
+				builder.WriteString(f.partialLiteral(s.text))
+			} else {
+				builder.WriteString(s.text)
+				count -= automatic
+			}
+		case textSegment, commentSegment:
+			builder.WriteString(s.text)
+		default:
+			builder.WriteString(f.partialLiteral(s.text))
+		}
+	}
+
+	return builder.String()
+}
+
+// unresolvedBlock returns index of end action of control block started at
+// provided segment if the block opening or else actions at the same level
+// cannot be resolved.
+func (f *config) unresolvedBlock(segments []segment, start int, functions []template.FuncMap, hasObject bool) (int, bool) {
+	skeleton := segments[start].text
+	depth := 0
+
+	for index := start + 1; index < len(segments); index++ {
+		if segments[index].kind != actionSegment {
+			continue
+		}
+
+		switch actionKeyword(segments[index].text, f.leftDelimiter, f.rightDelimiter) {
+		case "if", "range", "with", "define", "block", eachKeyword:
+			depth++
+		case "else":
+			if depth == 0 {
+				skeleton += segments[index].text
+			}
+		case "end":
+			if depth == 0 {
+				return index, !f.isResolved(skeleton+segments[index].text, functions, hasObject)
+			}
+
+			depth--
+		}
+	}
+
+	return 0, false
+}
+
+func joinSegments(segments []segment) string {
+	var builder strings.Builder
+
+	for _, s := range segments {
+		builder.WriteString(s.text)
+	}
+
+	return builder.String()
+}
+
+// isResolved returns true if all functions used in action are defined and
+// dot is used only with data object.
+func (f *config) isResolved(action string, functions []template.FuncMap, hasObject bool) bool {
+	trees, err := parseTrees(f.translateIndexes(f.translateEach(action)), f.leftDelimiter, f.rightDelimiter)
+
+	if err != nil {
+		return true
+	}
+
+	resolved := true
+
+	walkTrees(trees, func(_ *parse.Tree, node parse.Node) {
+		switch n := node.(type) {
+		case *parse.IdentifierNode:
+			_, namespace := f.functionSets[n.Ident]
+			_, folded := f.foldedName(n.Ident, functions)
+			resolved = resolved && (namespace || folded || isDefined(n.Ident, functions))
+		case *parse.FieldNode, *parse.DotNode:
+			resolved = resolved && hasObject
+		}
+	})
+
+	return resolved
+}
+
+// automaticCount returns number of automatic placeholders used in action.
+func (f *config) automaticCount(action string) int {
+	trees, err := parseTrees(f.translateIndexes(f.translateEach(action)), f.leftDelimiter, f.rightDelimiter)
+
+	if err != nil {
+		return 0
+	}
+
+	count := 0
+
+	walkTrees(trees, func(_ *parse.Tree, node parse.Node) {
+		if identifier, ok := node.(*parse.IdentifierNode); ok && (identifier.Ident == f.placeholder) {
+			count++
+		}
+	})
+
+	return count
+}
+
+func (f *config) partialLiteral(text string) string {
+	return f.leftDelimiter + partialLiteralFunction + " " + strconv.Quote(text) + f.rightDelimiter
+}
+
+func (f *config) partialEscape(value interface{}) string {
+	return doubleDelimiters(fmt.Sprint(value), f.leftDelimiter, f.rightDelimiter)
+}
+
+func partialLiteral(text string) string {
+	return text
+}
+
+func isPartialLiteral(action *parse.ActionNode) bool {
+	if len(action.Pipe.Cmds) == 0 {
+		return false
+	}
+
+	identifier, ok := action.Pipe.Cmds[0].Args[0].(*parse.IdentifierNode)
+
+	return ok && (identifier.Ident == partialLiteralFunction)
+}
+
+func isDotChanged(blocks []bool) bool {
+	for _, changed := range blocks {
+		if changed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// actionKeyword returns control keyword like if, range or end used in action.
+// It returns empty string for other actions.
+func actionKeyword(action, left, right string) string {
+	action = strings.TrimSuffix(strings.TrimPrefix(action, left), right)
+	action = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(action), "-"))
+
+	fields := strings.Fields(action)
+
+	if len(fields) == 0 {
+		return ""
+	}
+
+	switch keyword := strings.TrimSuffix(fields[0], "-"); keyword {
+	case "if", "else", "end", "range", "with", "define", "block", "template", "break", "continue", eachKeyword:
+		return keyword
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// Reference defines a single use of placeholder in message.
+type Reference struct {
+	// Name is placeholder name like name, p0 or p. Object fields are
+	// prefixed with dot like .Name.
+	Name string
+
+	// Position is argument position of positional placeholder like p0. It is
+	// -1 for other placeholders.
+	Position int
+
+	// Automatic is true for automatic placeholder p.
+	Automatic bool
+
+	// Fallback is true if placeholder is used with the fallback or coalesce
+	// function.
+	Fallback bool
+
+	// Location is placeholder location in message like :1:5.
+	Location string
+}
+
+// Placeholders returns sorted names of placeholders used in message. These
+// are identifiers that are not built-in or custom functions, like name,
+// p0 or p, and object fields prefixed with dot, like .Name.
+func Placeholders(message string) ([]string, error) {
+	return New().Placeholders(message)
+}
+
+// References returns all uses of placeholders in message in order of
+// appearance.
+func References(message string) ([]Reference, error) {
+	return New().References(message)
+}
+
+// Placeholders returns sorted names of placeholders used in message. These
+// are identifiers that are not built-in or custom functions, like name,
+// p0 or p, and object fields prefixed with dot, like .Name.
+func (f *Formatter) Placeholders(message string) ([]string, error) {
+	references, err := f.References(message)
+
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool)
+	names := []string{}
+
+	for _, reference := range references {
+		if !found[reference.Name] {
+			found[reference.Name] = true
+			names = append(names, reference.Name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// References returns all uses of placeholders in message in order of
+// appearance.
+func (f *Formatter) References(message string) ([]Reference, error) {
+	return f.snapshot().references(message, nil)
+}
+
+// references returns uses of placeholders in message. Provided names are
+// placeholders even if they shadow functions.
+func (f *config) references(message string, names map[string]bool) ([]Reference, error) {
+	message = f.translate(message)
+
+	trees, err := parseTrees(escapeDelimiters(message, f.leftDelimiter, f.rightDelimiter), f.leftDelimiter, f.rightDelimiter)
+
+	if err != nil {
+		return nil, err
+	}
+
+	functions := f.functionMaps(io.Discard, template.FuncMap{})
+	namespaced, err := f.transformNamespaces(trees, functions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	functions = append(functions, namespaced)
+	missing := missingPlaceholders(trees, functions)
+
+	var references []Reference
+
+	walkTrees(trees, func(tree *parse.Tree, node parse.Node) {
+		reference := Reference{Position: -1}
+
+		switch n := node.(type) {
+		case *parse.IdentifierNode:
+			if isDefined(n.Ident, functions) && !names[n.Ident] {
+				return
+			}
+
+			_, reference.Fallback = missing[n.Ident]
+			reference.Name = n.Ident
+			reference.Automatic = n.Ident == f.placeholder
+			reference.Position = f.position(n.Ident)
+		case *parse.FieldNode:
+			reference.Name = "." + n.Ident[0]
+		default:
+			return
+		}
+
+		reference.Location, _ = tree.ErrorContext(node)
+		references = append(references, reference)
+	})
+
+	sort.SliceStable(references, func(i, j int) bool {
+		return locationLess(references[i].Location, references[j].Location)
+	})
+
+	return references, nil
+}
+
+// position returns argument position of positional placeholder or -1.
+func (f *config) position(name string) int {
+	if !strings.HasPrefix(name, f.placeholder) || (len(name) == len(f.placeholder)) {
+		return -1
+	}
+
+	digits := name[len(f.placeholder):]
+
+	if (len(digits) > 1) && (digits[0] == '0') {
+		return -1
+	}
+
+	position, err := strconv.Atoi(digits)
+
+	if (err != nil) || (position < 0) || !isDigits(digits) {
+		return -1
+	}
+
+	return position
+}
+
+func isDigits(text string) bool {
+	for _, c := range []byte(text) {
+		if (c < '0') || (c > '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// locationLess compares locations like :1:5 by line and column.
+func locationLess(a, b string) bool {
+	aLine, aColumn := parseLocation(a)
+	bLine, bColumn := parseLocation(b)
+
+	if aLine != bLine {
+		return aLine < bLine
+	}
+
+	return aColumn < bColumn
+}
+
+func parseLocation(location string) (line, column int) {
+	parts := strings.Split(location, ":")
+
+	if len(parts) >= 2 {
+		line, _ = strconv.Atoi(parts[len(parts)-2])
+		column, _ = strconv.Atoi(parts[len(parts)-1])
+	}
+
+	return line, column
+}
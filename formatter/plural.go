@@ -0,0 +1,53 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import "fmt"
+
+// pluralForm selects plural form for count using locale plural rule. Count
+// can be the last argument used with pipeline like {count | plural "file"
+// "files"} or the first one like {plural count "file" "files"}. Locale rule
+// selects form index, for example English uses singular and plural form and
+// Polish uses three forms. The last form is used when form is missing.
+func (l *Locale) pluralForm(arguments ...interface{}) (string, error) {
+	if len(arguments) < 2 {
+		return "", fmt.Errorf("expected count and plural forms, got %d arguments", len(arguments))
+	}
+
+	count, err := toInt(arguments[len(arguments)-1])
+	forms := arguments[:len(arguments)-1]
+
+	if err != nil {
+		if count, err = toInt(arguments[0]); err != nil {
+			return "", fmt.Errorf("plural count: %w", err)
+		}
+
+		forms = arguments[1:]
+	}
+
+	texts := make([]string, 0, len(forms))
+
+	for _, form := range forms {
+		text, ok := form.(string)
+
+		if !ok {
+			return "", fmt.Errorf("plural form must be a string, got %T", form)
+		}
+
+		texts = append(texts, text)
+	}
+
+	return l.plural(count, texts), nil
+}
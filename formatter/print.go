@@ -0,0 +1,83 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"io"
+	"os"
+)
+
+// Print formats string and writes it to standard output. It returns the
+// number of bytes written and any error encountered.
+func Print(message string, arguments ...interface{}) (int, error) {
+	return New().Fprint(os.Stdout, message, arguments...)
+}
+
+// Println formats string and writes it to standard output followed by a
+// newline. It returns the number of bytes written and any error encountered.
+func Println(message string, arguments ...interface{}) (int, error) {
+	return New().Fprintln(os.Stdout, message, arguments...)
+}
+
+// Fprint formats string and writes it to writer. It returns the number of
+// bytes written and any error encountered.
+func Fprint(writer io.Writer, message string, arguments ...interface{}) (int, error) {
+	return New().Fprint(writer, message, arguments...)
+}
+
+// Fprintln formats string and writes it to writer followed by a newline. It
+// returns the number of bytes written and any error encountered.
+func Fprintln(writer io.Writer, message string, arguments ...interface{}) (int, error) {
+	return New().Fprintln(writer, message, arguments...)
+}
+
+// Print formats string and writes it to standard output. It returns the
+// number of bytes written and any error encountered.
+func (f *Formatter) Print(message string, arguments ...interface{}) (int, error) {
+	return f.Fprint(os.Stdout, message, arguments...)
+}
+
+// Println formats string and writes it to standard output followed by a
+// newline. It returns the number of bytes written and any error encountered.
+func (f *Formatter) Println(message string, arguments ...interface{}) (int, error) {
+	return f.Fprintln(os.Stdout, message, arguments...)
+}
+
+// Fprint formats string and writes it to writer. Nothing is written if
+// message cannot be formatted. It returns the number of bytes written and any
+// error encountered.
+func (f *Formatter) Fprint(writer io.Writer, message string, arguments ...interface{}) (int, error) {
+	return f.fprint(writer, "", message, arguments...)
+}
+
+// Fprintln formats string and writes it to writer followed by a newline.
+// Nothing is written if message cannot be formatted. It returns the number of
+// bytes written and any error encountered.
+func (f *Formatter) Fprintln(writer io.Writer, message string, arguments ...interface{}) (int, error) {
+	return f.fprint(writer, "\n", message, arguments...)
+}
+
+func (f *Formatter) fprint(writer io.Writer, suffix, message string, arguments ...interface{}) (int, error) {
+	buffer := getBuffer()
+	defer putBuffer(buffer)
+
+	if err := f.FormatWriter(buffer, message, arguments...); err != nil {
+		return 0, err
+	}
+
+	buffer.WriteString(suffix)
+
+	return writer.Write(buffer.Bytes())
+}
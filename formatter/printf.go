@@ -0,0 +1,196 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const printfFlags = "+-# 0"
+
+// Sprintf formats string with classic fmt verbs like %s, %d or %0.2f. Verbs
+// are translated to replacement fields that use the printf function.
+func Sprintf(format string, arguments ...interface{}) string {
+	return New().Sprintf(format, arguments...)
+}
+
+// TranslatePrintf translates string with classic fmt verbs like %s, %d or
+// %0.2f to string with replacement fields.
+func TranslatePrintf(format string) string {
+	return New().TranslatePrintf(format)
+}
+
+// Sprintf formats string with classic fmt verbs like %s, %d or %0.2f. Verbs
+// are translated to replacement fields that use the printf function. Missing
+// arguments are reported like in the fmt package, unused arguments are
+// appended like in Format. Formatting error is returned
+// as %!(ERROR=message).
+func (f *Formatter) Sprintf(format string, arguments ...interface{}) string {
+	buffer := getBuffer()
+	defer putBuffer(buffer)
+
+	c, arguments := f.snapshot().withOptions(arguments)
+
+	if err := c.formatWriter(context.Background(), buffer, c.translatePrintf(format, len(arguments)), arguments...); err != nil {
+		return "%!(ERROR=" + err.Error() + ")"
+	}
+
+	return buffer.String()
+}
+
+// TranslatePrintf translates string with classic fmt verbs like %s, %d or
+// %0.2f to string with replacement fields. Literal delimiters are escaped.
+func (f *Formatter) TranslatePrintf(format string) string {
+	return f.snapshot().translatePrintf(format, -1)
+}
+
+// translatePrintf translates format string. Arguments count is used to report
+// missing arguments, negative value disables it.
+func (f *config) translatePrintf(format string, count int) string {
+	var builder strings.Builder
+
+	position := 0
+
+	for len(format) > 0 {
+		index := strings.IndexByte(format, '%')
+
+		if index < 0 {
+			builder.WriteString(doubleDelimiters(format, f.leftDelimiter, f.rightDelimiter))
+			break
+		}
+
+		builder.WriteString(doubleDelimiters(format[:index], f.leftDelimiter, f.rightDelimiter))
+		format = format[index+1:]
+
+		if strings.HasPrefix(format, "%") {
+			builder.WriteString("%")
+			format = format[1:]
+			continue
+		}
+
+		verb, placeholders, missing, rest := f.printfVerb(format, &position, count)
+		format = rest
+
+		switch {
+		case verb == "":
+			builder.WriteString("%!(NOVERB)")
+		case strings.HasPrefix(verb, "%!"):
+			builder.WriteString(verb)
+		case missing:
+			_, length := utf8.DecodeLastRuneInString(verb)
+			builder.WriteString("%!" + verb[len(verb)-length:] + "(MISSING)")
+		default:
+			builder.WriteString(f.leftDelimiter + "printf " + strconv.Quote("%"+verb))
+
+			for _, placeholder := range placeholders {
+				builder.WriteString(" " + placeholder)
+			}
+
+			builder.WriteString(f.rightDelimiter)
+		}
+	}
+
+	return builder.String()
+}
+
+// printfVerb parses single verb without leading % and argument indexes. It
+// returns verb, placeholders for consumed arguments and unparsed rest. Verb
+// with invalid argument index is returned already formatted as an error.
+func (f *config) printfVerb(format string, position *int, count int) (verb string, placeholders []string, missing bool, rest string) {
+	var builder strings.Builder
+
+	bad := false
+
+	consume := func() {
+		if (count >= 0) && (*position >= count) {
+			missing = true
+		}
+
+		placeholders = append(placeholders, f.placeholder+strconv.Itoa(*position))
+		*position++
+	}
+
+	index := func() {
+		if !strings.HasPrefix(format, "[") {
+			return
+		}
+
+		end := strings.IndexByte(format, ']')
+
+		if end < 0 {
+			bad = true
+			format = format[1:]
+
+			return
+		}
+
+		if number, err := strconv.Atoi(format[1:end]); (err == nil) && (number > 0) {
+			*position = number - 1
+		} else {
+			bad = true
+		}
+
+		format = format[end+1:]
+	}
+
+	number := func() {
+		index()
+
+		if strings.HasPrefix(format, "*") {
+			builder.WriteByte('*')
+			format = format[1:]
+			consume()
+
+			return
+		}
+
+		for (len(format) > 0) && (format[0] >= '0') && (format[0] <= '9') {
+			builder.WriteByte(format[0])
+			format = format[1:]
+		}
+	}
+
+	for (len(format) > 0) && strings.IndexByte(printfFlags, format[0]) >= 0 {
+		builder.WriteByte(format[0])
+		format = format[1:]
+	}
+
+	number()
+
+	if strings.HasPrefix(format, ".") {
+		builder.WriteByte('.')
+		format = format[1:]
+		number()
+	}
+
+	index()
+
+	if format == "" {
+		return "", nil, false, format
+	}
+
+	_, length := utf8.DecodeRuneInString(format)
+	builder.WriteString(format[:length])
+	consume()
+
+	if bad {
+		return "%!" + format[:length] + "(BADINDEX)", nil, false, format[length:]
+	}
+
+	return builder.String(), placeholders, missing, format[length:]
+}
@@ -0,0 +1,122 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"strings"
+	"text/template/parse"
+	"unicode"
+)
+
+// These constants define pseudo-localization markers.
+const (
+	PseudoStart   = "["
+	PseudoEnd     = "]"
+	PseudoPadding = "~"
+)
+
+// pseudoExpansion defines how much longer in percents pseudo-localized text
+// is. Translations are often about 30% longer than English text.
+const pseudoExpansion = 30
+
+var gPseudoCharacters = map[rune]rune{ // nolint: gochecknoglobals
+	'a': 'á', 'b': 'ƀ', 'c': 'ç', 'd': 'ð', 'e': 'é', 'f': 'ƒ', 'g': 'ĝ', 'h': 'ĥ', 'i': 'í',
+	'j': 'ĵ', 'k': 'ķ', 'l': 'ļ', 'm': 'ɱ', 'n': 'ñ', 'o': 'ö', 'p': 'þ', 'q': 'ǫ', 'r': 'ŕ',
+	's': 'š', 't': 'ţ', 'u': 'ü', 'v': 'ṽ', 'w': 'ŵ', 'x': 'ẋ', 'y': 'ý', 'z': 'ž',
+	'A': 'Á', 'B': 'Ɓ', 'C': 'Ç', 'D': 'Ð', 'E': 'É', 'F': 'Ƒ', 'G': 'Ĝ', 'H': 'Ĥ', 'I': 'Í',
+	'J': 'Ĵ', 'K': 'Ķ', 'L': 'Ļ', 'M': 'Ṁ', 'N': 'Ñ', 'O': 'Ö', 'P': 'Þ', 'Q': 'Ǫ', 'R': 'Ŕ',
+	'S': 'Š', 'T': 'Ţ', 'U': 'Ü', 'V': 'Ṽ', 'W': 'Ŵ', 'X': 'Ẋ', 'Y': 'Ý', 'Z': 'Ž',
+}
+
+// PseudoLocalize returns pseudo-localized text. Latin letters are replaced
+// with accented ones, text is padded to be about 30% longer and surrounded
+// with brackets. It helps to find hard-coded strings and layout overflows.
+func PseudoLocalize(text string) string {
+	return PseudoStart + pseudoAccents(text) + pseudoPadding(text) + PseudoEnd
+}
+
+// SetPseudoLocalization enables or disables pseudo-localization mode. In that
+// mode literal text of every message is pseudo-localized like with
+// PseudoLocalize. Placeholder values and results of functions are not
+// changed.
+func (f *Formatter) SetPseudoLocalization(enabled bool) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.pseudoLocalization = enabled
+
+	return f
+}
+
+// IsPseudoLocalization returns true if pseudo-localization mode is enabled.
+func (f *Formatter) IsPseudoLocalization() bool {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.pseudoLocalization
+}
+
+// pseudoLocalizeTrees pseudo-localizes text nodes of all trees. Only main
+// tree is padded and surrounded with brackets.
+func pseudoLocalizeTrees(trees map[string]*parse.Tree) {
+	var text strings.Builder
+
+	walkTrees(trees, func(tree *parse.Tree, node parse.Node) {
+		if n, ok := node.(*parse.TextNode); ok {
+			if tree.Name == "" {
+				text.Write(n.Text)
+			}
+
+			n.Text = []byte(pseudoAccents(string(n.Text)))
+		}
+	})
+
+	tree := trees[""]
+
+	if (tree == nil) || (tree.Root == nil) {
+		return
+	}
+
+	start := tree.Root.Position()
+
+	tree.Root.Nodes = append([]parse.Node{newText(start, PseudoStart)}, tree.Root.Nodes...)
+	tree.Root.Nodes = append(tree.Root.Nodes, newText(start, pseudoPadding(text.String())+PseudoEnd))
+}
+
+func pseudoAccents(text string) string {
+	return strings.Map(func(r rune) rune {
+		if replaced, ok := gPseudoCharacters[r]; ok {
+			return replaced
+		}
+
+		return r
+	}, text)
+}
+
+func pseudoPadding(text string) string {
+	letters := 0
+
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			letters++
+		}
+	}
+
+	return strings.Repeat(PseudoPadding, (letters*pseudoExpansion+99)/100)
+}
+
+func newText(position parse.Pos, text string) *parse.TextNode {
+	return &parse.TextNode{NodeType: parse.NodeText, Pos: position, Text: []byte(text)}
+}
@@ -0,0 +1,96 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"strings"
+)
+
+// These constants define keywords of raw block like {raw}{name}{end} that is
+// printed verbatim.
+const (
+	rawKeyword    = "raw"
+	rawEndKeyword = "end"
+)
+
+// translate translates additional delimiters, raw blocks, placeholder style,
+// loop actions and index expressions to message with main delimiters that can
+// be parsed.
+func (f *config) translate(message string) string {
+	return f.translateIndexes(f.translateEach(f.translateText(message)))
+}
+
+// translateText translates additional delimiters, raw blocks and placeholder
+// style to message with main delimiters.
+func (f *config) translateText(message string) string {
+	return f.translateStyle(f.translateRaw(f.translateDelimiters(message)))
+}
+
+// translateRaw replaces raw blocks like {raw}{name}{end} with their content
+// with escaped delimiters. Content ends at the first {end}. Raw action
+// without following {end} is left intact.
+func (f *config) translateRaw(message string) string {
+	left, right := f.leftDelimiter, f.rightDelimiter
+
+	if (left == "") || (right == "") || !strings.Contains(message, rawKeyword) {
+		return message
+	}
+
+	var builder strings.Builder
+
+	start := 0
+
+	for index := 0; index < len(message); {
+		rest := message[index:]
+
+		switch {
+		case strings.HasPrefix(rest, left+left):
+			index += 2 * len(left)
+		case strings.HasPrefix(rest, left+commentStart):
+			index = commentEndIndex(message, index+len(left)+len(commentStart), right)
+		case strings.HasPrefix(rest, left):
+			end := actionEnd(message, index+len(left), right)
+
+			if f.isRawAction(message[index:end]) {
+				if stop := strings.Index(message[end:], left+rawEndKeyword+right); stop >= 0 {
+					builder.WriteString(message[start:index])
+					builder.WriteString(doubleDelimiters(message[end:end+stop], left, right))
+					end += stop + len(left+rawEndKeyword+right)
+					start = end
+				}
+			}
+
+			index = end
+		default:
+			index++
+		}
+	}
+
+	if start == 0 {
+		return message
+	}
+
+	builder.WriteString(message[start:])
+
+	return builder.String()
+}
+
+func (f *config) isRawAction(action string) bool {
+	if !strings.HasSuffix(action, f.rightDelimiter) || (len(action) < len(f.leftDelimiter)+len(f.rightDelimiter)) {
+		return false
+	}
+
+	return strings.TrimSpace(action[len(f.leftDelimiter):len(action)-len(f.rightDelimiter)]) == rawKeyword
+}
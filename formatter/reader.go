@@ -0,0 +1,46 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"context"
+	"io"
+)
+
+// FormatReader returns reader with formatted string.
+func FormatReader(message string, arguments ...interface{}) io.Reader {
+	return New().FormatReader(message, arguments...)
+}
+
+// FormatReader returns reader with formatted string. Message is formatted in
+// a separate goroutine while reader is read, so large output is not buffered
+// in memory. Formatting error is returned by reader after already formatted
+// output. Reader implements io.Closer, closing it before the end stops
+// formatting.
+func (f *Formatter) FormatReader(message string, arguments ...interface{}) io.Reader {
+	c, arguments := f.snapshot().withOptions(arguments)
+
+	return c.formatReader(message, arguments...)
+}
+
+func (f *config) formatReader(message string, arguments ...interface{}) *io.PipeReader {
+	reader, writer := io.Pipe()
+
+	go func() {
+		writer.CloseWithError(f.formatWriter(context.Background(), writer, message, arguments...))
+	}()
+
+	return reader
+}
@@ -0,0 +1,397 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// These constants define struct tag used to redact struct fields.
+const (
+	TagName      = "format"
+	RedactOption = "redact"
+	HashOption   = "hash"
+)
+
+// RedactedText replaces redacted values.
+const RedactedText = "***"
+
+var gRedactedFields sync.Map // nolint: gochecknoglobals
+
+var ( // nolint: gochecknoglobals
+	gStringerType    = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	gFormattableType = reflect.TypeOf((*Formattable)(nil)).Elem()
+)
+
+// Redact registers types whose values are always rendered as RedactedText.
+// Struct fields tagged with `format:"redact"` are also rendered as
+// RedactedText and fields tagged with `format:"redact,hash"` are rendered as
+// a short hash of their value, so equal values can be correlated in logs
+// without revealing them.
+func (f *Formatter) Redact(types ...reflect.Type) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	redacted := make(map[reflect.Type]bool, len(f.redacted)+len(types))
+
+	for t := range f.redacted {
+		redacted[t] = true
+	}
+
+	for _, t := range types {
+		redacted[t] = true
+	}
+
+	f.redacted = redacted
+
+	return f
+}
+
+// IsRedacted returns true if values of provided type are rendered as
+// RedactedText.
+func (f *Formatter) IsRedacted(t reflect.Type) bool {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.redacted[t]
+}
+
+// redactArguments returns arguments with values of registered types, also
+// provided with Arg, Args or Named, replaced by RedactedText.
+func (f *config) redactArguments(arguments []interface{}) []interface{} {
+	if len(f.redacted) == 0 {
+		return arguments
+	}
+
+	redacted := make([]interface{}, len(arguments))
+
+	for position, argument := range arguments {
+		switch value := argument.(type) {
+		case Argument:
+			redacted[position] = Argument{Name: value.Name, Value: f.redactType(value.Value)}
+		case Named:
+			redacted[position] = Named(f.redactMap(value))
+		case Args:
+			redacted[position] = Args(f.redactMap(value))
+		default:
+			redacted[position] = f.redactType(argument)
+		}
+	}
+
+	return redacted
+}
+
+func (f *config) redactMap(values map[string]interface{}) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(values))
+
+	for name, value := range values {
+		redacted[name] = f.redactType(value)
+	}
+
+	return redacted
+}
+
+// redactType returns RedactedText for value of registered type or value
+// itself.
+func (f *config) redactType(value interface{}) interface{} {
+	if (value != nil) && f.redacted[reflect.TypeOf(value)] {
+		return RedactedText
+	}
+
+	return value
+}
+
+// redact returns value with redacted registered types and tagged struct
+// fields. Value is returned unchanged if nothing has to be redacted.
+func (f *config) redact(value interface{}) interface{} {
+	valueOf := reflect.ValueOf(value)
+
+	if !valueOf.IsValid() {
+		return value
+	}
+
+	if f.redacted[valueOf.Type()] {
+		return RedactedText
+	}
+
+	if !f.hasRedacted(valueOf.Type()) || implementsPrinter(valueOf.Type()) {
+		return value
+	}
+
+	return f.redactedString(valueOf, true)
+}
+
+// redactedCopy returns copy of value with redacted registered types and
+// tagged struct fields used by encoding functions like json. Redacted strings
+// are replaced by RedactedText or hash, other redacted values by zero values.
+// Value is returned unchanged if nothing has to be redacted.
+func (f *config) redactedCopy(value interface{}) interface{} {
+	valueOf := reflect.ValueOf(value)
+
+	if !valueOf.IsValid() || !f.mayRedact(valueOf.Type()) {
+		return value
+	}
+
+	return f.copyRedacted(valueOf, false, false).Interface()
+}
+
+func (f *config) copyRedacted(value reflect.Value, redact, hash bool) reflect.Value {
+	t := value.Type()
+
+	if redact || f.redacted[t] {
+		result := reflect.New(t).Elem()
+
+		if t.Kind() == reflect.String {
+			result.SetString(redactedField(value, hash))
+		}
+
+		return result
+	}
+
+	if !f.mayRedact(t) {
+		return value
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		if value.IsNil() {
+			return value
+		}
+
+		result := reflect.New(t).Elem()
+		result.Set(f.copyRedacted(value.Elem(), false, false))
+
+		return result
+	case reflect.Map:
+		if value.IsNil() {
+			return value
+		}
+
+		result := reflect.MakeMapWithSize(t, value.Len())
+
+		for _, key := range value.MapKeys() {
+			result.SetMapIndex(key, f.copyRedacted(value.MapIndex(key), false, false))
+		}
+
+		return result
+	case reflect.Ptr:
+		if value.IsNil() {
+			return value
+		}
+
+		result := reflect.New(t.Elem())
+		result.Elem().Set(f.copyRedacted(value.Elem(), false, false))
+
+		return result
+	case reflect.Struct:
+		result := reflect.New(t).Elem()
+		result.Set(value)
+
+		for index := 0; index < t.NumField(); index++ {
+			if field := t.Field(index); field.PkgPath == "" {
+				redact, hash := tagOptions(field)
+				result.Field(index).Set(f.copyRedacted(value.Field(index), redact, hash))
+			}
+		}
+
+		return result
+	case reflect.Slice:
+		if value.IsNil() {
+			return value
+		}
+
+		result := reflect.MakeSlice(t, value.Len(), value.Len())
+
+		for index := 0; index < value.Len(); index++ {
+			result.Index(index).Set(f.copyRedacted(value.Index(index), false, false))
+		}
+
+		return result
+	case reflect.Array:
+		result := reflect.New(t).Elem()
+
+		for index := 0; index < value.Len(); index++ {
+			result.Index(index).Set(f.copyRedacted(value.Index(index), false, false))
+		}
+
+		return result
+	default:
+		return value
+	}
+}
+
+// redactedString returns value rendered like with fmt.Sprint with redacted
+// values.
+func (f *config) redactedString(value reflect.Value, top bool) string {
+	if f.redacted[value.Type()] {
+		return RedactedText
+	}
+
+	if !f.hasRedacted(value.Type()) || implementsPrinter(value.Type()) {
+		return fmt.Sprint(value)
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() || !top {
+			return fmt.Sprint(value)
+		}
+
+		return "&" + f.redactedString(value.Elem(), false)
+	case reflect.Struct:
+		fields := make([]string, 0, value.NumField())
+
+		for index := 0; index < value.NumField(); index++ {
+			if redact, hash := tagOptions(value.Type().Field(index)); redact {
+				fields = append(fields, redactedField(value.Field(index), hash))
+			} else {
+				fields = append(fields, f.redactedString(value.Field(index), false))
+			}
+		}
+
+		return "{" + strings.Join(fields, " ") + "}"
+	case reflect.Slice, reflect.Array:
+		elements := make([]string, 0, value.Len())
+
+		for index := 0; index < value.Len(); index++ {
+			elements = append(elements, f.redactedString(value.Index(index), top))
+		}
+
+		return "[" + strings.Join(elements, " ") + "]"
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// mayRedact returns true if values of type have to be redacted or they may
+// contain values of registered types in interfaces or maps.
+func (f *config) mayRedact(t reflect.Type) bool {
+	if f.hasRedacted(t) {
+		return true
+	}
+
+	if len(f.redacted) == 0 {
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Map, reflect.Ptr, reflect.Slice, reflect.Array:
+		return f.mayRedact(t.Elem())
+	default:
+		return false
+	}
+}
+
+// hasRedacted returns true if type is registered as redacted or it is
+// a struct with redacted fields, pointer or slice of such types.
+func (f *config) hasRedacted(t reflect.Type) bool {
+	if f.redacted[t] {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return f.hasRedacted(t.Elem())
+	case reflect.Struct:
+	default:
+		return false
+	}
+
+	for index := 0; index < t.NumField(); index++ {
+		if f.redacted[t.Field(index).Type] {
+			return true
+		}
+	}
+
+	return f.hasRedactedFields(t)
+}
+
+// hasRedactedFields returns true if struct type has fields tagged to redact,
+// also in nested structs.
+func (f *config) hasRedactedFields(t reflect.Type) bool {
+	if cached, ok := gRedactedFields.Load(t); ok {
+		f.reportCache(RedactionCache, true)
+		return cached.(bool)
+	}
+
+	f.reportCache(RedactionCache, false)
+
+	result := false
+
+	for index := 0; index < t.NumField(); index++ {
+		field := t.Field(index)
+
+		if redact, _ := tagOptions(field); redact || ((field.Type.Kind() == reflect.Struct) && f.hasRedactedFields(field.Type)) {
+			result = true
+			break
+		}
+	}
+
+	gRedactedFields.Store(t, result)
+
+	return result
+}
+
+// tagOptions returns redaction options from struct field tag.
+func tagOptions(field reflect.StructField) (redact, hash bool) {
+	for _, option := range strings.Split(field.Tag.Get(TagName), ",") {
+		switch option {
+		case RedactOption:
+			redact = true
+		case HashOption:
+			hash = true
+		}
+	}
+
+	return redact, hash
+}
+
+// tagFieldName returns struct field name from provided tag like json or
+// format. It returns empty string if tag doesn't set name or if it is "-".
+// The first element of format tag is a name unless it is redaction option.
+func tagFieldName(field reflect.StructField, tag string) string {
+	name := strings.Split(field.Tag.Get(tag), ",")[0]
+
+	switch {
+	case name == "-":
+		return ""
+	case (tag == TagName) && ((name == RedactOption) || (name == HashOption)):
+		return ""
+	default:
+		return name
+	}
+}
+
+// redactedField returns RedactedText or short hash of value.
+func redactedField(value reflect.Value, hash bool) string {
+	if !hash {
+		return RedactedText
+	}
+
+	sum := sha256.Sum256([]byte(fmt.Sprint(value)))
+
+	return "sha256:" + hex.EncodeToString(sum[:4])
+}
+
+func implementsPrinter(t reflect.Type) bool {
+	return t.Implements(gStringerType) || t.Implements(gErrorType) || t.Implements(gFormattableType)
+}
@@ -0,0 +1,98 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"sort"
+	"strings"
+)
+
+// Requirements defines arguments required by message.
+type Requirements struct {
+	// Positions holds sorted positions of arguments used by positional and
+	// automatic placeholders.
+	Positions []int
+
+	// Names holds sorted names of named placeholders used without the
+	// fallback or coalesce function.
+	Names []string
+
+	// Optional holds sorted names of named placeholders used only with the
+	// fallback or coalesce function.
+	Optional []string
+
+	// Fields holds sorted object fields prefixed with dot like .Name.
+	Fields []string
+}
+
+// Required returns arguments required by message without formatting it.
+func Required(message string) (*Requirements, error) {
+	return New().Required(message)
+}
+
+// Required returns arguments required by message without formatting it. It
+// can be used to check that all needed data is available before building
+// arguments. Automatic placeholders require consecutive positions from zero.
+func (f *Formatter) Required(message string) (*Requirements, error) {
+	references, err := f.References(message)
+
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make(map[int]bool)
+	names := make(map[string]bool)
+	fields := make(map[string]bool)
+	automatic := 0
+
+	for _, reference := range references {
+		switch {
+		case reference.Automatic:
+			positions[automatic] = true
+			automatic++
+		case reference.Position >= 0:
+			positions[reference.Position] = true
+		case strings.HasPrefix(reference.Name, "."):
+			fields[reference.Name] = true
+		default:
+			names[reference.Name] = names[reference.Name] || !reference.Fallback
+		}
+	}
+
+	requirements := &Requirements{Positions: []int{}, Names: []string{}, Optional: []string{}, Fields: []string{}}
+
+	for position := range positions {
+		requirements.Positions = append(requirements.Positions, position)
+	}
+
+	for name, required := range names {
+		if required {
+			requirements.Names = append(requirements.Names, name)
+		} else {
+			requirements.Optional = append(requirements.Optional, name)
+		}
+	}
+
+	for name := range fields {
+		requirements.Fields = append(requirements.Fields, name)
+	}
+
+	sort.Ints(requirements.Positions)
+	sort.Strings(requirements.Names)
+	sort.Strings(requirements.Optional)
+	sort.Strings(requirements.Fields)
+
+	return requirements, nil
+}
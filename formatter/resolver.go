@@ -0,0 +1,160 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"text/template"
+	"text/template/parse"
+)
+
+// Resolver provides values for named placeholders without arguments from
+// pluggable sources like config store, request context or secrets vault.
+type Resolver interface {
+	Resolve(name string) (interface{}, bool)
+}
+
+// ResolverFunc is an adapter to use function as Resolver.
+type ResolverFunc func(name string) (interface{}, bool)
+
+// Resolve returns value for named placeholder.
+func (r ResolverFunc) Resolve(name string) (interface{}, bool) {
+	return r(name)
+}
+
+// MissingHandler returns value for named placeholder without argument. It
+// returns false if value is not available.
+type MissingHandler func(name string) (interface{}, bool)
+
+// SetMissingHandler sets handler called when named placeholder has no
+// argument. Value can be looked up for example in environment variables,
+// context or feature flags service. When handler doesn't provide value
+// undefined placeholder is reported as an error or fallback value is used.
+// Lazy values returned by handler are evaluated only when they are used.
+// Resolvers added with AddResolver are asked before handler. Nil disables it.
+func (f *Formatter) SetMissingHandler(handler MissingHandler) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.missingHandler = handler
+
+	return f
+}
+
+// GetMissingHandler returns handler called when named placeholder has no
+// argument.
+func (f *Formatter) GetMissingHandler() MissingHandler {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.missingHandler
+}
+
+// AddResolver adds resolvers of named placeholders without arguments.
+// Resolvers are asked in order they were added, before missing handler.
+func (f *Formatter) AddResolver(resolvers ...Resolver) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.resolvers = append(append([]Resolver(nil), f.resolvers...), resolvers...)
+
+	return f
+}
+
+// GetResolvers returns resolvers of named placeholders without arguments.
+func (f *Formatter) GetResolvers() []Resolver {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return append([]Resolver(nil), f.resolvers...)
+}
+
+// ResetResolvers removes all resolvers.
+func (f *Formatter) ResetResolvers() *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.resolvers = nil
+
+	return f
+}
+
+// hasResolvers returns true if resolvers or missing handler are set.
+func (f *config) hasResolvers() bool {
+	return (len(f.resolvers) > 0) || (f.missingHandler != nil)
+}
+
+// resolve returns value for named placeholder without argument from
+// resolvers or missing handler.
+func (f *config) resolve(name string) (interface{}, bool) {
+	for _, resolver := range f.resolvers {
+		if value, ok := resolver.Resolve(name); ok {
+			return value, true
+		}
+	}
+
+	if f.missingHandler != nil {
+		return f.missingHandler(name)
+	}
+
+	return nil, false
+}
+
+// resolvePlaceholders returns placeholders for undefined named placeholders
+// with values provided by resolvers or missing handler.
+func (f *config) resolvePlaceholders(trees map[string]*parse.Tree, functions []template.FuncMap) template.FuncMap {
+	resolved := make(template.FuncMap)
+
+	if !f.hasResolvers() {
+		return resolved
+	}
+
+	checked := make(map[string]bool)
+
+	walkTrees(trees, func(_ *parse.Tree, node parse.Node) {
+		identifier, ok := node.(*parse.IdentifierNode)
+
+		if !ok || checked[identifier.Ident] || (f.position(identifier.Ident) >= 0) || isDefined(identifier.Ident, functions) {
+			return
+		}
+
+		checked[identifier.Ident] = true
+
+		if value, ok := f.resolve(identifier.Ident); ok {
+			value = lazy(value)
+
+			resolved[identifier.Ident] = func() interface{} {
+				return evaluate(value)
+			}
+		}
+	})
+
+	return resolved
+}
+
+// resolveMessage returns placeholders for undefined named placeholders used in
+// message with values provided by resolvers or missing handler.
+func (f *config) resolveMessage(message string, functions []template.FuncMap) template.FuncMap {
+	if !f.hasResolvers() {
+		return nil
+	}
+
+	trees, err := parseTrees(escapeDelimiters(message, f.leftDelimiter, f.rightDelimiter), f.leftDelimiter, f.rightDelimiter)
+
+	if err != nil {
+		return nil
+	}
+
+	return f.resolvePlaceholders(trees, functions)
+}
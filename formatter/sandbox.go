@@ -0,0 +1,269 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+const sandboxRangeFunction = "_sandboxRange"
+
+// These errors are returned when message breaks sandbox restrictions.
+const (
+	ErrSandboxViolation  = fError("not allowed in sandbox")
+	ErrTooManyIterations = fError("template exceeds maximum number of iterations")
+)
+
+// UnsafeFunctions lists built-in functions that expose process, user, host
+// or file system details or call arbitrary functions. They are not allowed by
+// DefaultSandbox.
+var UnsafeFunctions = []string{ // nolint: gochecknoglobals
+	"absolute", "call", "cwd", "egid", "env", "euid", "executable", "expand", "gid", "hostname", "ip", "pid", "ppid",
+	"uid", "user",
+}
+
+// Sandbox defines restrictions used to format untrusted messages like
+// templates authored by customers.
+type Sandbox struct {
+	// Functions lists names of functions messages can call, including
+	// text/template functions like index or printf. Functions from function
+	// sets are named with namespace like str.upper. Placeholders are always
+	// allowed.
+	Functions []string
+
+	// MaxIterations limits total number of range loop iterations during
+	// single formatting. Zero value disables it.
+	MaxIterations int
+
+	// MaxDepth replaces formatter maximum nesting depth if set.
+	MaxDepth int
+
+	// MaxOutputSize replaces formatter maximum output size if set.
+	MaxOutputSize int
+
+	// Timeout replaces formatter execution timeout if set.
+	Timeout time.Duration
+}
+
+// DefaultSandbox returns sandbox that allows all built-in functions except
+// UnsafeFunctions, 10000 iterations, nesting depth 10, 1 MiB of output and
+// 1 second of execution.
+func DefaultSandbox() *Sandbox {
+	unsafe := make(map[string]bool, len(UnsafeFunctions))
+
+	for _, name := range UnsafeFunctions {
+		unsafe[name] = true
+	}
+
+	found := make(map[string]bool)
+
+	for name := range gTemplateFunctions {
+		found[name] = true
+	}
+
+	c := defaultConfig()
+
+	for _, functions := range c.builtinFunctionMaps(io.Discard) {
+		for name := range functions {
+			found[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(found))
+
+	for name := range found {
+		if !unsafe[name] {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return &Sandbox{
+		Functions:     names,
+		MaxIterations: 10000,
+		MaxDepth:      10,
+		MaxOutputSize: 1 << 20,
+		Timeout:       time.Second,
+	}
+}
+
+// SetSandbox enables sandbox restrictions. Messages can call only allowed
+// functions, method calls are disabled like with SetMethodCalls(false) and
+// range loops are limited.
+// Limits set in sandbox replace maximum depth, maximum output size and
+// execution timeout of formatter. Nil value disables sandbox restrictions but
+// replaced limits are kept.
+func (f *Formatter) SetSandbox(sandbox *Sandbox) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if sandbox == nil {
+		f.sandbox = nil
+		return f
+	}
+
+	copied := *sandbox
+	copied.Functions = append([]string(nil), sandbox.Functions...)
+	f.sandbox = &copied
+
+	if sandbox.MaxDepth > 0 {
+		f.maxDepth = sandbox.MaxDepth
+	}
+
+	if sandbox.MaxOutputSize > 0 {
+		f.maxOutputSize = sandbox.MaxOutputSize
+	}
+
+	if sandbox.Timeout > 0 {
+		f.executionTimeout = sandbox.Timeout
+	}
+
+	return f
+}
+
+// GetSandbox returns a copy of sandbox restrictions or nil if sandbox is not
+// enabled.
+func (f *Formatter) GetSandbox() *Sandbox {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	if f.sandbox == nil {
+		return nil
+	}
+
+	copied := *f.sandbox
+	copied.Functions = append([]string(nil), f.sandbox.Functions...)
+
+	return &copied
+}
+
+// isAllowed returns true if function can be called in sandbox.
+func (s *Sandbox) isAllowed(name string) bool {
+	for _, allowed := range s.Functions {
+		if allowed == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sandboxFunctions returns functions with names allowed in sandbox.
+func (f *config) sandboxFunctions(functions template.FuncMap) template.FuncMap {
+	if f.sandbox == nil {
+		return functions
+	}
+
+	allowed := template.FuncMap{}
+
+	for name, function := range functions {
+		if f.sandbox.isAllowed(name) {
+			allowed[name] = function
+		}
+	}
+
+	return allowed
+}
+
+// checkSandbox returns ErrSandboxViolation for functions that are not
+// allowed. Range pipelines are limited by maximum number of iterations.
+func (f *config) checkSandbox(trees map[string]*parse.Tree, functions []template.FuncMap) error {
+	if f.sandbox == nil {
+		return nil
+	}
+
+	var err error
+
+	walkTrees(trees, func(tree *parse.Tree, node parse.Node) {
+		if err != nil {
+			return
+		}
+
+		switch n := node.(type) {
+		case *parse.IdentifierNode:
+			err = f.checkSandboxFunction(n.Ident, functions)
+		case *parse.RangeNode:
+			n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+				NodeType: parse.NodeCommand,
+				Pos:      n.Pos,
+				Args:     []parse.Node{parse.NewIdentifier(sandboxRangeFunction).SetTree(tree).SetPos(n.Pos)},
+			})
+		}
+	})
+
+	return err
+}
+
+func (f *config) checkSandboxFunction(name string, functions []template.FuncMap) error {
+	if (name == sandboxRangeFunction) || f.sandbox.isAllowed(name) {
+		return nil
+	}
+
+	for _, funcs := range functions {
+		if _, ok := funcs[name]; ok {
+			return nil
+		}
+	}
+
+	if _, ok := f.functions[name]; ok || gTemplateFunctions[name] || f.isBuiltinFunction(name) {
+		return fmt.Errorf("%w: function %q", ErrSandboxViolation, name)
+	}
+
+	return nil
+}
+
+// sandboxRange returns function that passes range values through and
+// returns ErrTooManyIterations when total number of iterations exceeds limit.
+func (f *config) sandboxRange() template.FuncMap {
+	remaining := f.sandbox.MaxIterations
+
+	return template.FuncMap{sandboxRangeFunction: func(value interface{}) (interface{}, error) {
+		if f.sandbox.MaxIterations <= 0 {
+			return value, nil
+		}
+
+		v := reflect.ValueOf(value)
+
+		for v.IsValid() && ((v.Kind() == reflect.Ptr) || (v.Kind() == reflect.Interface)) && !v.IsNil() {
+			v = v.Elem()
+		}
+
+		switch v.Kind() {
+		case reflect.Invalid:
+			return value, nil
+		case reflect.Array, reflect.Slice, reflect.Map:
+			remaining -= v.Len()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			remaining -= int(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			remaining -= int(v.Uint())
+		default:
+			return nil, fmt.Errorf("%w: range over %s", ErrSandboxViolation, v.Type())
+		}
+
+		if remaining < 0 {
+			return nil, fmt.Errorf("%w: %d", ErrTooManyIterations, f.sandbox.MaxIterations)
+		}
+
+		return value, nil
+	}}
+}
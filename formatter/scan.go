@@ -0,0 +1,317 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoMatch is returned by Scan when input doesn't match pattern.
+const ErrNoMatch = fError("input does not match pattern")
+
+var ( // nolint: gochecknoglobals
+	gDurationType        = reflect.TypeOf(time.Duration(0))
+	gTextUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// assigner stores scanned text in target.
+type assigner func(text string) error
+
+// Scan extracts values from input formatted with pattern and stores them in
+// targets. It is the reverse of Format, see Formatter.Scan.
+func Scan(pattern, input string, targets ...interface{}) error {
+	return New().Scan(pattern, input, targets...)
+}
+
+// Scan extracts values from input formatted with pattern and stores them in
+// targets. Pattern uses the same placeholders like Format. Automatic {p} and
+// positional {pN} placeholders store values in pointers provided as targets.
+// Named placeholders {name} store values in pointers provided with Arg or in
+// map targets like Named. Object placeholders {.Field} store values in fields
+// of struct pointer target. Values are converted to target types like
+// strings, numbers, booleans, time.Duration or encoding.TextUnmarshaler.
+// Replacement fields with functions or pipelines are not supported.
+func (f *Formatter) Scan(pattern, input string, targets ...interface{}) error {
+	return f.snapshot().scanInput(pattern, input, targets)
+}
+
+func (f *config) scanInput(pattern, input string, targets []interface{}) error {
+	expression, names, assigners, err := f.scanExpression(pattern, targets)
+
+	if err != nil {
+		return err
+	}
+
+	matches := expression.FindStringSubmatch(input)
+
+	if matches == nil {
+		return ErrNoMatch
+	}
+
+	values := make(map[string]string, len(names))
+
+	for index, name := range names {
+		text := matches[index+1]
+
+		if previous, ok := values[name]; ok {
+			if previous != text {
+				return fmt.Errorf("%w: placeholder %s has different values %q and %q", ErrNoMatch, name, previous, text)
+			}
+
+			continue
+		}
+
+		values[name] = text
+
+		if err := assigners[index](text); err != nil {
+			return fmt.Errorf("cannot scan %q for placeholder %s: %w", text, name, err)
+		}
+	}
+
+	return nil
+}
+
+// scanExpression returns regular expression for pattern with a capturing
+// group for every replacement field.
+func (f *config) scanExpression(pattern string, targets []interface{}) (*regexp.Regexp, []string, []assigner, error) {
+	var builder strings.Builder
+
+	var names []string
+
+	var assigners []assigner
+
+	automatic := 0
+
+	builder.WriteString("(?s)^")
+
+	for _, s := range scan(pattern, f.leftDelimiter, f.rightDelimiter) {
+		switch s.kind {
+		case textSegment:
+			builder.WriteString(regexp.QuoteMeta(s.text))
+		case leftEscapeSegment:
+			builder.WriteString(regexp.QuoteMeta(f.leftDelimiter))
+		case rightEscapeSegment:
+			builder.WriteString(regexp.QuoteMeta(f.rightDelimiter))
+		case commentSegment:
+		default:
+			name := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(s.text, f.leftDelimiter), f.rightDelimiter))
+
+			if name == f.placeholder {
+				name += strconv.Itoa(automatic)
+				automatic++
+			}
+
+			assign, err := f.scanTarget(name, targets)
+
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("replacement field %s: %w", s.text, err)
+			}
+
+			names = append(names, name)
+			assigners = append(assigners, assign)
+			builder.WriteString("(.*?)")
+		}
+	}
+
+	builder.WriteString("$")
+
+	expression, err := regexp.Compile(builder.String())
+
+	return expression, names, assigners, err
+}
+
+// scanTarget returns assigner for placeholder name.
+func (f *config) scanTarget(name string, targets []interface{}) (assigner, error) {
+	if position := f.position(name); position >= 0 {
+		if position >= len(targets) {
+			return nil, fmt.Errorf("missing target at position %d", position)
+		}
+
+		return pointerAssigner(reflect.ValueOf(unwrapArgument(targets[position])))
+	}
+
+	if strings.HasPrefix(name, ".") && isIdentifier(name[1:]) {
+		return fieldAssigner(name[1:], targets)
+	}
+
+	if !isIdentifier(name) {
+		return nil, fError("only placeholders are supported")
+	}
+
+	for _, target := range targets {
+		if argument, ok := target.(Argument); ok && (argument.Name == name) {
+			return pointerAssigner(reflect.ValueOf(argument.Value))
+		}
+	}
+
+	for _, target := range targets {
+		if value := reflect.ValueOf(target); (value.Kind() == reflect.Map) && (value.Type().Key().Kind() == reflect.String) {
+			return mapAssigner(value, name)
+		}
+	}
+
+	return nil, fError("no target")
+}
+
+func pointerAssigner(pointer reflect.Value) (assigner, error) {
+	if (pointer.Kind() != reflect.Ptr) || pointer.IsNil() {
+		return nil, fmt.Errorf("target %s is not a non-nil pointer", pointer.Type())
+	}
+
+	return func(text string) error {
+		return scanValue(pointer.Elem(), text)
+	}, nil
+}
+
+func fieldAssigner(name string, targets []interface{}) (assigner, error) {
+	for _, target := range targets {
+		value := reflect.ValueOf(target)
+
+		if (value.Kind() != reflect.Ptr) || value.IsNil() || (value.Elem().Kind() != reflect.Struct) {
+			continue
+		}
+
+		if field := value.Elem().FieldByName(name); field.IsValid() && field.CanSet() {
+			return func(text string) error {
+				return scanValue(field, text)
+			}, nil
+		}
+
+		return nil, fmt.Errorf("field %q not found in type %s", name, value.Elem().Type())
+	}
+
+	return nil, fError("no struct pointer target")
+}
+
+func mapAssigner(target reflect.Value, name string) (assigner, error) {
+	if target.IsNil() {
+		return nil, fError("map target is nil")
+	}
+
+	key := reflect.ValueOf(name).Convert(target.Type().Key())
+
+	if existing := target.MapIndex(key); existing.IsValid() {
+		if existing.Kind() == reflect.Interface {
+			existing = existing.Elem()
+		}
+
+		if (existing.Kind() == reflect.Ptr) && !existing.IsNil() {
+			return pointerAssigner(existing)
+		}
+	}
+
+	return func(text string) error {
+		value := reflect.New(target.Type().Elem()).Elem()
+
+		if err := scanValue(value, text); err != nil {
+			return err
+		}
+
+		target.SetMapIndex(key, value)
+
+		return nil
+	}, nil
+}
+
+// scanValue converts text to type of value and stores it.
+func scanValue(value reflect.Value, text string) error {
+	if value.CanAddr() && value.Addr().Type().Implements(gTextUnmarshalerType) {
+		return value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text))
+	}
+
+	if value.Type() == gDurationType {
+		duration, err := time.ParseDuration(text)
+
+		if err == nil {
+			value.SetInt(int64(duration))
+		}
+
+		return err
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(text)
+	case reflect.Interface:
+		if value.NumMethod() != 0 {
+			return fmt.Errorf("unsupported target type %s", value.Type())
+		}
+
+		value.Set(reflect.ValueOf(text))
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(text)
+
+		if err == nil {
+			value.SetBool(parsed)
+		}
+
+		return err
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(text, 10, value.Type().Bits())
+
+		if err == nil {
+			value.SetInt(parsed)
+		}
+
+		return err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		parsed, err := strconv.ParseUint(text, 10, value.Type().Bits())
+
+		if err == nil {
+			value.SetUint(parsed)
+		}
+
+		return err
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(text, value.Type().Bits())
+
+		if err == nil {
+			value.SetFloat(parsed)
+		}
+
+		return err
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			value.SetBytes([]byte(text))
+			return nil
+		}
+
+		return fmt.Errorf("unsupported target type %s", value.Type())
+	default:
+		return fmt.Errorf("unsupported target type %s", value.Type())
+	}
+
+	return nil
+}
+
+func isIdentifier(name string) bool {
+	if (name == "") || ((name[0] >= '0') && (name[0] <= '9')) {
+		return false
+	}
+
+	for _, c := range []byte(name) {
+		if !isIdentifierByte(c) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,199 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"strconv"
+	"strings"
+)
+
+// These constants define kinds of message segments.
+const (
+	textSegment segmentKind = iota
+	actionSegment
+	leftEscapeSegment
+	rightEscapeSegment
+	commentSegment
+)
+
+// These constants define markers of comments like {# note #} placed just after
+// left and before right delimiter.
+const (
+	commentStart = "#"
+	commentEnd   = "#"
+)
+
+type segmentKind int
+
+// segment defines a part of message. Action segments contain delimiters.
+type segment struct {
+	kind segmentKind
+	text string
+}
+
+// scan splits message into text, action, comment and escaped delimiter
+// segments. Unclosed comment is returned as action segment.
+func scan(message, left, right string) []segment {
+	var segments []segment
+
+	if (left == "") || (right == "") {
+		return append(segments, segment{kind: textSegment, text: message})
+	}
+
+	start := 0
+
+	addText := func(index int) {
+		if index > start {
+			segments = append(segments, segment{kind: textSegment, text: message[start:index]})
+		}
+	}
+
+	for index := 0; index < len(message); {
+		rest := message[index:]
+
+		switch {
+		case strings.HasPrefix(rest, left+left):
+			addText(index)
+			segments = append(segments, segment{kind: leftEscapeSegment, text: left + left})
+			index += 2 * len(left)
+			start = index
+		case strings.HasPrefix(rest, left+commentStart):
+			addText(index)
+			end := commentEndIndex(message, index+len(left)+len(commentStart), right)
+			segments = append(segments, segment{kind: commentKind(message[index:end], right), text: message[index:end]})
+			index = end
+			start = index
+		case strings.HasPrefix(rest, left):
+			addText(index)
+			end := actionEnd(message, index+len(left), right)
+			segments = append(segments, segment{kind: actionSegment, text: message[index:end]})
+			index = end
+			start = index
+		case strings.HasPrefix(rest, right+right):
+			addText(index)
+			segments = append(segments, segment{kind: rightEscapeSegment, text: right + right})
+			index += 2 * len(right)
+			start = index
+		default:
+			index++
+		}
+	}
+
+	addText(len(message))
+
+	return segments
+}
+
+// escapeDelimiters replaces doubled delimiters outside of actions with
+// actions that print a single literal delimiter. Comments are removed.
+func escapeDelimiters(message, left, right string) string {
+	if !strings.Contains(message, left+left) && !strings.Contains(message, right+right) &&
+		!strings.Contains(message, left+commentStart) {
+		return message
+	}
+
+	var builder strings.Builder
+
+	for _, s := range scan(message, left, right) {
+		switch s.kind {
+		case leftEscapeSegment:
+			builder.WriteString(left + strconv.Quote(left) + right)
+		case rightEscapeSegment:
+			builder.WriteString(left + strconv.Quote(right) + right)
+		case commentSegment:
+		default:
+			builder.WriteString(s.text)
+		}
+	}
+
+	return builder.String()
+}
+
+// doubleDelimiters escapes all delimiters in text by doubling them.
+func doubleDelimiters(text, left, right string) string {
+	if (left == "") || (right == "") {
+		return text
+	}
+
+	text = strings.ReplaceAll(text, left, left+left)
+
+	return strings.ReplaceAll(text, right, right+right)
+}
+
+// commentEndIndex returns position just after comment end marker and right
+// delimiter. It returns length of message if comment is not closed.
+func commentEndIndex(message string, index int, right string) int {
+	if end := strings.Index(message[index:], commentEnd+right); end >= 0 {
+		return index + end + len(commentEnd) + len(right)
+	}
+
+	return len(message)
+}
+
+func commentKind(text, right string) segmentKind {
+	if strings.HasSuffix(text, commentEnd+right) {
+		return commentSegment
+	}
+
+	return actionSegment
+}
+
+// actionEnd returns position just after right delimiter that closes action
+// started at given position. Delimiters inside quoted strings, characters and
+// comments are skipped. It returns length of message if action is not closed.
+func actionEnd(message string, index int, right string) int {
+	for index < len(message) {
+		rest := message[index:]
+
+		switch {
+		case strings.HasPrefix(rest, right):
+			return index + len(right)
+		case strings.HasPrefix(rest, "/*"):
+			if end := strings.Index(rest[2:], "*/"); end >= 0 {
+				index += end + 4
+				continue
+			}
+
+			return len(message)
+		}
+
+		switch quote := message[index]; quote {
+		case '"', '\'', '`':
+			index = quotedEnd(message, index+1, quote)
+		default:
+			index++
+		}
+	}
+
+	return len(message)
+}
+
+// quotedEnd returns position just after closing quote character.
+func quotedEnd(message string, index int, quote byte) int {
+	for index < len(message) {
+		switch message[index] {
+		case quote:
+			return index + 1
+		case '\\':
+			if quote != '`' {
+				index++
+			}
+		}
+
+		index++
+	}
+
+	return len(message)
+}
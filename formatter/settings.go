@@ -0,0 +1,299 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Settings defines formatter configuration that can be serialized to and
+// deserialized from JSON or YAML. Functions, hooks and other values that
+// cannot be serialized are not part of it.
+type Settings struct {
+	Placeholder        string           `json:"placeholder" yaml:"placeholder"`
+	PlaceholderStyle   PlaceholderStyle `json:"placeholderStyle" yaml:"placeholderStyle"`
+	LeftDelimiter      string           `json:"leftDelimiter" yaml:"leftDelimiter"`
+	RightDelimiter     string           `json:"rightDelimiter" yaml:"rightDelimiter"`
+	AddedDelimiters    []Delimiters     `json:"addedDelimiters,omitempty" yaml:"addedDelimiters,omitempty"`
+	Locale             string           `json:"locale" yaml:"locale"`
+	ColorMode          ColorMode        `json:"colorMode" yaml:"colorMode"`
+	SafeHTML           bool             `json:"safeHTML" yaml:"safeHTML"`
+	SafeMarkdown       bool             `json:"safeMarkdown" yaml:"safeMarkdown"`
+	Strict             bool             `json:"strict" yaml:"strict"`
+	StrictPlaceholders bool             `json:"strictPlaceholders" yaml:"strictPlaceholders"`
+	ConflictPolicy     ConflictPolicy   `json:"conflictPolicy" yaml:"conflictPolicy"`
+	FunctionPolicy     FunctionPolicy   `json:"functionPolicy" yaml:"functionPolicy"`
+	UnusedPrefix       string           `json:"unusedPrefix" yaml:"unusedPrefix"`
+	UnusedSeparator    string           `json:"unusedSeparator" yaml:"unusedSeparator"`
+	ExecutionTimeout   string           `json:"executionTimeout,omitempty" yaml:"executionTimeout,omitempty"`
+	MaxOutputSize      int              `json:"maxOutputSize" yaml:"maxOutputSize"`
+	MaxDepth           int              `json:"maxDepth" yaml:"maxDepth"`
+	PseudoLocalization bool             `json:"pseudoLocalization" yaml:"pseudoLocalization"`
+	TrimWhitespace     bool             `json:"trimWhitespace" yaml:"trimWhitespace"`
+	WidthAlgorithm     WidthAlgorithm   `json:"widthAlgorithm" yaml:"widthAlgorithm"`
+	NilText            string           `json:"nilText" yaml:"nilText"`
+	FmtVerb            string           `json:"fmtVerb" yaml:"fmtVerb"`
+	UseStringer        bool             `json:"useStringer" yaml:"useStringer"`
+	MethodCalls        bool             `json:"methodCalls" yaml:"methodCalls"`
+	NameTag            string           `json:"nameTag,omitempty" yaml:"nameTag,omitempty"`
+	CaseInsensitive    bool             `json:"caseInsensitive" yaml:"caseInsensitive"`
+	TrueText           string           `json:"trueText" yaml:"trueText"`
+	FalseText          string           `json:"falseText" yaml:"falseText"`
+	EnvAllowlist       []string         `json:"envAllowlist,omitempty" yaml:"envAllowlist,omitempty"`
+	FunctionSets       []string         `json:"functionSets,omitempty" yaml:"functionSets,omitempty"`
+	Templates          Templates        `json:"templates,omitempty" yaml:"templates,omitempty"`
+	WriteBufferSize    int              `json:"writeBufferSize" yaml:"writeBufferSize"`
+	BatchWorkers       int              `json:"batchWorkers" yaml:"batchWorkers"`
+}
+
+// These variables define names of enumerations used in settings.
+var (
+	gColorModeNames        = []string{"auto", "always", "never"}     // nolint: gochecknoglobals
+	gPlaceholderStyleNames = []string{"default", "python"}           // nolint: gochecknoglobals
+	gConflictPolicyNames   = []string{"last", "first", "error"}      // nolint: gochecknoglobals
+	gFunctionPolicyNames   = []string{"override", "ignore", "error"} // nolint: gochecknoglobals
+	gWidthAlgorithmNames   = []string{"display", "rune", "byte"}     // nolint: gochecknoglobals
+)
+
+// GetSettings returns serializable formatter configuration.
+func (f *Formatter) GetSettings() Settings {
+	c := f.snapshot()
+
+	settings := Settings{
+		Placeholder:        c.placeholder,
+		PlaceholderStyle:   c.placeholderStyle,
+		LeftDelimiter:      c.leftDelimiter,
+		RightDelimiter:     c.rightDelimiter,
+		AddedDelimiters:    append([]Delimiters(nil), c.addedDelimiters...),
+		Locale:             c.locale,
+		ColorMode:          c.colorMode,
+		SafeHTML:           c.safeHTML,
+		SafeMarkdown:       c.safeMarkdown,
+		Strict:             c.strict,
+		StrictPlaceholders: c.strictPlaceholders,
+		ConflictPolicy:     c.conflictPolicy,
+		FunctionPolicy:     c.functionPolicy,
+		UnusedPrefix:       c.unusedPrefix,
+		UnusedSeparator:    c.unusedSeparator,
+		MaxOutputSize:      c.maxOutputSize,
+		MaxDepth:           c.maxDepth,
+		PseudoLocalization: c.pseudoLocalization,
+		TrimWhitespace:     c.trimWhitespace,
+		WidthAlgorithm:     c.widthAlgorithm,
+		NilText:            c.nilText,
+		FmtVerb:            c.fmtVerb,
+		UseStringer:        !c.noStringer,
+		MethodCalls:        !c.noMethods,
+		NameTag:            c.nameTag,
+		CaseInsensitive:    c.caseInsensitive,
+		TrueText:           c.trueText,
+		FalseText:          c.falseText,
+		EnvAllowlist:       append([]string(nil), c.envAllowlist...),
+		FunctionSets:       c.namespaces(),
+		WriteBufferSize:    c.writeBufferSize,
+		BatchWorkers:       c.batchWorkers,
+	}
+
+	if c.executionTimeout > 0 {
+		settings.ExecutionTimeout = c.executionTimeout.String()
+	}
+
+	if len(c.templates) > 0 {
+		settings.Templates = copyTemplates(c.templates)
+	}
+
+	return settings
+}
+
+// SetSettings sets formatter configuration from settings. Templates and added
+// delimiters replace all templates and delimiters added before. Function sets
+// not listed in non-nil function sets are removed. It returns an error if
+// execution timeout is not a valid duration like 1.5s or if listed function
+// set wasn't added.
+func (f *Formatter) SetSettings(settings Settings) error {
+	var timeout time.Duration
+
+	if settings.ExecutionTimeout != "" {
+		var err error
+
+		if timeout, err = time.ParseDuration(settings.ExecutionTimeout); err != nil {
+			return fmt.Errorf("invalid execution timeout: %w", err)
+		}
+	}
+
+	if settings.FunctionSets != nil {
+		c := f.snapshot()
+
+		if err := c.keepFunctionSets(settings.FunctionSets); err != nil {
+			return err
+		}
+	}
+
+	f.SetPlaceholder(settings.Placeholder).
+		SetPlaceholderStyle(settings.PlaceholderStyle).
+		SetDelimiters(settings.LeftDelimiter, settings.RightDelimiter).
+		SetLocale(settings.Locale).
+		SetColorMode(settings.ColorMode).
+		SetSafeHTML(settings.SafeHTML).
+		SetSafeMarkdown(settings.SafeMarkdown).
+		SetStrict(settings.Strict).
+		SetStrictPlaceholders(settings.StrictPlaceholders).
+		SetConflictPolicy(settings.ConflictPolicy).
+		SetFunctionPolicy(settings.FunctionPolicy).
+		SetUnusedPrefix(settings.UnusedPrefix).
+		SetUnusedSeparator(settings.UnusedSeparator).
+		SetExecutionTimeout(timeout).
+		SetMaxOutputSize(settings.MaxOutputSize).
+		SetMaxDepth(settings.MaxDepth).
+		SetPseudoLocalization(settings.PseudoLocalization).
+		SetTrimWhitespace(settings.TrimWhitespace).
+		SetWidthAlgorithm(settings.WidthAlgorithm).
+		SetNilText(settings.NilText).
+		SetFmtVerb(settings.FmtVerb).
+		SetUseStringer(settings.UseStringer).
+		SetMethodCalls(settings.MethodCalls).
+		SetNameTag(settings.NameTag).
+		SetCaseInsensitive(settings.CaseInsensitive).
+		SetBoolStrings(settings.TrueText, settings.FalseText).
+		SetEnvAllowlist(settings.EnvAllowlist...).
+		SetWriteBufferSize(settings.WriteBufferSize).
+		SetBatchWorkers(settings.BatchWorkers).
+		ResetAddedDelimiters()
+
+	for _, pair := range settings.AddedDelimiters {
+		f.AddDelimiters(pair.Left, pair.Right)
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.templates = copyTemplates(settings.Templates)
+
+	if settings.FunctionSets != nil {
+		return f.keepFunctionSets(settings.FunctionSets)
+	}
+
+	return nil
+}
+
+// MarshalJSON returns formatter settings encoded as JSON.
+func (f *Formatter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.GetSettings())
+}
+
+// UnmarshalJSON sets formatter configuration from settings encoded as JSON.
+// Missing settings keep current values.
+func (f *Formatter) UnmarshalJSON(data []byte) error {
+	settings := f.GetSettings()
+
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return err
+	}
+
+	return f.SetSettings(settings)
+}
+
+// MarshalYAML returns formatter settings encoded as YAML.
+func (f *Formatter) MarshalYAML() (interface{}, error) {
+	return f.GetSettings(), nil
+}
+
+// UnmarshalYAML sets formatter configuration from settings encoded as YAML.
+// Missing settings keep current values.
+func (f *Formatter) UnmarshalYAML(node *yaml.Node) error {
+	settings := f.GetSettings()
+
+	if err := node.Decode(&settings); err != nil {
+		return err
+	}
+
+	return f.SetSettings(settings)
+}
+
+// MarshalText returns color mode name auto, always or never.
+func (m ColorMode) MarshalText() ([]byte, error) {
+	return marshalName(int(m), gColorModeNames, "color mode")
+}
+
+// UnmarshalText sets color mode from name auto, always or never.
+func (m *ColorMode) UnmarshalText(text []byte) error {
+	return unmarshalName((*int)(m), text, gColorModeNames, "color mode")
+}
+
+// MarshalText returns placeholder style name default or python.
+func (s PlaceholderStyle) MarshalText() ([]byte, error) {
+	return marshalName(int(s), gPlaceholderStyleNames, "placeholder style")
+}
+
+// UnmarshalText sets placeholder style from name default or python.
+func (s *PlaceholderStyle) UnmarshalText(text []byte) error {
+	return unmarshalName((*int)(s), text, gPlaceholderStyleNames, "placeholder style")
+}
+
+// MarshalText returns conflict policy name last, first or error.
+func (p ConflictPolicy) MarshalText() ([]byte, error) {
+	return marshalName(int(p), gConflictPolicyNames, "conflict policy")
+}
+
+// UnmarshalText sets conflict policy from name last, first or error.
+func (p *ConflictPolicy) UnmarshalText(text []byte) error {
+	return unmarshalName((*int)(p), text, gConflictPolicyNames, "conflict policy")
+}
+
+// MarshalText returns function policy name override, ignore or error.
+func (p FunctionPolicy) MarshalText() ([]byte, error) {
+	return marshalName(int(p), gFunctionPolicyNames, "function policy")
+}
+
+// UnmarshalText sets function policy from name override, ignore or error.
+func (p *FunctionPolicy) UnmarshalText(text []byte) error {
+	return unmarshalName((*int)(p), text, gFunctionPolicyNames, "function policy")
+}
+
+// MarshalText returns width algorithm name display, rune or byte.
+func (a WidthAlgorithm) MarshalText() ([]byte, error) {
+	return marshalName(int(a), gWidthAlgorithmNames, "width algorithm")
+}
+
+// UnmarshalText sets width algorithm from name display, rune or byte.
+func (a *WidthAlgorithm) UnmarshalText(text []byte) error {
+	return unmarshalName((*int)(a), text, gWidthAlgorithmNames, "width algorithm")
+}
+
+func marshalName(value int, names []string, kind string) ([]byte, error) {
+	if (value < 0) || (value >= len(names)) {
+		return nil, fmt.Errorf("invalid %s %d", kind, value)
+	}
+
+	return []byte(names[value]), nil
+}
+
+func unmarshalName(value *int, text []byte, names []string, kind string) error {
+	name := strings.ToLower(strings.TrimSpace(string(text)))
+
+	for index, candidate := range names {
+		if candidate == name {
+			*value = index
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid %s %q, expected one of: %s", kind, text, strings.Join(names, ", "))
+}
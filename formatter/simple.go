@@ -0,0 +1,168 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// simpleSegments returns segments of message if it contains only text,
+// comments, escaped delimiters and actions with single placeholder defined by
+// arguments like {p}, {p0} or {name}. Such message is formatted without
+// text/template. Action segments hold only placeholder names.
+func (f *config) simpleSegments(message string, placeholders template.FuncMap) ([]segment, bool) {
+	if (f.engine != nil) || f.safeHTML || f.safeMarkdown || f.pseudoLocalization || f.trimWhitespace ||
+		f.strictPlaceholders || (len(f.overrides) > 0) || (f.leftDelimiter == "") || (f.rightDelimiter == "") {
+		return nil, false
+	}
+
+	segments := scan(message, f.leftDelimiter, f.rightDelimiter)
+
+	for index, s := range segments {
+		if s.kind != actionSegment {
+			continue
+		}
+
+		if !strings.HasSuffix(s.text, f.rightDelimiter) || (len(s.text) < len(f.leftDelimiter)+len(f.rightDelimiter)) {
+			return nil, false
+		}
+
+		name := strings.TrimSpace(s.text[len(f.leftDelimiter) : len(s.text)-len(f.rightDelimiter)])
+
+		if _, ok := placeholders[name].(func() interface{}); !ok || !isSimpleName(name) {
+			return nil, false
+		}
+
+		if _, ok := f.functions[name]; ok {
+			return nil, false
+		}
+
+		segments[index].text = name
+	}
+
+	return segments, true
+}
+
+// executeSimple writes segments returned by simpleSegments with placeholders
+// replaced by formatted values.
+func (f *config) executeSimple(ctx context.Context, key string, writer io.Writer, segments []segment,
+	placeholders template.FuncMap) error {
+	return f.traceExecute(ctx, key, writer, func(ctx context.Context, writer io.Writer) error {
+		return f.withContext(ctx, writer, func(_ context.Context, writer io.Writer) (err error) {
+			defer recoverExecError(&err)
+
+			for _, s := range segments {
+				if err := f.writeSegment(writer, s, placeholders); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	})
+}
+
+func (f *config) writeSegment(writer io.Writer, s segment, placeholders template.FuncMap) error {
+	switch s.kind {
+	case textSegment:
+		_, err := io.WriteString(writer, s.text)
+		return err
+	case leftEscapeSegment:
+		_, err := io.WriteString(writer, f.leftDelimiter)
+		return err
+	case rightEscapeSegment:
+		_, err := io.WriteString(writer, f.rightDelimiter)
+		return err
+	case actionSegment:
+		value, err := f.formatValue(placeholders[s.text].(func() interface{})())
+
+		if err != nil {
+			return fmt.Errorf("error calling %s: %w", s.text, err)
+		}
+
+		if text, ok := value.(string); ok {
+			_, err = io.WriteString(writer, text)
+			return err
+		}
+
+		printable, err := printableValue(reflect.ValueOf(value))
+
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprint(writer, printable)
+
+		return err
+	default:
+		return nil
+	}
+}
+
+// isSimpleName returns true if name is an identifier that is not a keyword.
+func isSimpleName(name string) bool {
+	if (name == "") || ((name[0] >= '0') && (name[0] <= '9')) {
+		return false
+	}
+
+	for _, c := range []byte(name) {
+		if !isIdentifierByte(c) {
+			return false
+		}
+	}
+
+	switch name {
+	case "if", "else", "end", "range", "with", "define", "block", "template", "break", "continue", "nil", "true", "false":
+		return false
+	default:
+		return true
+	}
+}
+
+// printableValue returns value printed like text/template does. Pointers are
+// dereferenced unless they are nil or they implement error or fmt.Stringer.
+func printableValue(value reflect.Value) (interface{}, error) {
+	if value.Kind() == reflect.Interface {
+		value = reflect.ValueOf(value.Interface())
+	}
+
+	for (value.Kind() == reflect.Ptr) || (value.Kind() == reflect.Interface) {
+		if value.IsNil() {
+			break
+		}
+
+		value = value.Elem()
+	}
+
+	if !value.IsValid() {
+		return "<no value>", nil
+	}
+
+	if !value.Type().Implements(gErrorType) && !value.Type().Implements(gStringerType) {
+		if value.CanAddr() && (reflect.PtrTo(value.Type()).Implements(gErrorType) ||
+			reflect.PtrTo(value.Type()).Implements(gStringerType)) {
+			value = value.Addr()
+		} else if (value.Kind() == reflect.Chan) || (value.Kind() == reflect.Func) {
+			return nil, fmt.Errorf("can't print value of type %s", value.Type())
+		}
+	}
+
+	return value.Interface(), nil
+}
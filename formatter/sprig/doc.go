@@ -0,0 +1,32 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package sprig integrates the sprig function library with formatter. It is a
+separate module, so the formatter module doesn't depend on sprig.
+
+Register adds sprig functions to formatter with one call. Added functions
+collide with built-in functions like upper or date, collisions are handled
+according to formatter function policy. Functions can be also added under a
+namespace to avoid collisions. Example:
+
+	f := sprig.Register(formatter.New(), sprig.WithNamespace("sprig"), sprig.WithoutUnsafe())
+
+	formatted, err := f.Format("{p | sprig.snakecase}", "HelloWorld")
+
+Functions reading environment variables or resolving host names should not
+be available to untrusted templates. WithoutUnsafe removes them, WithDenylist
+and WithAllowlist select functions explicitly.
+*/
+package sprig
@@ -0,0 +1,112 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprig
+
+import (
+	masterminds "github.com/Masterminds/sprig/v3"
+	"gitlab.com/tymonx/go-formatter/formatter"
+)
+
+// UnsafeFunctions lists sprig functions that read environment variables or
+// resolve host names. They should not be available to untrusted templates.
+var UnsafeFunctions = []string{"env", "expandenv", "getHostByName"} // nolint: gochecknoglobals
+
+// Option configures which sprig functions are added.
+type Option func(o *options)
+
+type options struct {
+	allowlist map[string]bool
+	denylist  map[string]bool
+	namespace string
+}
+
+// WithAllowlist adds only sprig functions with provided names. It can be
+// used many times.
+func WithAllowlist(names ...string) Option {
+	return func(o *options) {
+		if o.allowlist == nil {
+			o.allowlist = make(map[string]bool)
+		}
+
+		for _, name := range names {
+			o.allowlist[name] = true
+		}
+	}
+}
+
+// WithDenylist excludes sprig functions with provided names. It can be used
+// many times and it takes precedence over allowlist.
+func WithDenylist(names ...string) Option {
+	return func(o *options) {
+		for _, name := range names {
+			o.denylist[name] = true
+		}
+	}
+}
+
+// WithoutUnsafe excludes functions listed in UnsafeFunctions.
+func WithoutUnsafe() Option {
+	return WithDenylist(UnsafeFunctions...)
+}
+
+// WithNamespace adds sprig functions as function set under provided
+// namespace, so messages call them like {p | sprig.upper}.
+func WithNamespace(namespace string) Option {
+	return func(o *options) {
+		o.namespace = namespace
+	}
+}
+
+// Functions returns sprig functions selected by options. Namespace is
+// ignored.
+func Functions(opts ...Option) formatter.Functions {
+	return newOptions(opts).functions()
+}
+
+// Register adds sprig functions selected by options to formatter and returns
+// it.
+func Register(f *formatter.Formatter, opts ...Option) *formatter.Formatter {
+	o := newOptions(opts)
+
+	if o.namespace != "" {
+		return f.AddFunctionSet(o.namespace, o.functions())
+	}
+
+	return f.AddFunctions(o.functions())
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{denylist: make(map[string]bool)}
+
+	for _, option := range opts {
+		option(o)
+	}
+
+	return o
+}
+
+func (o *options) functions() formatter.Functions {
+	functions := formatter.Functions{}
+
+	for name, function := range masterminds.TxtFuncMap() {
+		if o.denylist[name] || ((o.allowlist != nil) && !o.allowlist[name]) {
+			continue
+		}
+
+		functions[name] = function
+	}
+
+	return functions
+}
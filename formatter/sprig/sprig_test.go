@@ -0,0 +1,99 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprig_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/tymonx/go-formatter/formatter"
+	"gitlab.com/tymonx/go-formatter/formatter/sprig"
+)
+
+func TestRegister(test *testing.T) {
+	f := sprig.Register(formatter.New())
+
+	formatted, err := f.Format(`{p | snakecase} {list 1 2 3 | last}`, "HelloWorld")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "hello_world 3", formatted)
+}
+
+func TestRegisterNamespace(test *testing.T) {
+	f := sprig.Register(formatter.New(), sprig.WithNamespace("sprig"))
+
+	formatted, err := f.Format(`{p | sprig.camelcase} {sprig.default "none" ""}`, "hello_world")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "HelloWorld none", formatted)
+}
+
+func TestRegisterPolicy(test *testing.T) {
+	f := sprig.Register(formatter.New().SetFunctionPolicy(formatter.FunctionError))
+
+	_, err := f.Format("{p}", 1)
+
+	assert.True(test, errors.Is(err, formatter.ErrFunctionConflict))
+}
+
+func TestRegisterTernary(test *testing.T) {
+	message := `{premium | ternary "yes" "no"} {ternary "yes" "no" trial}`
+	arguments := formatter.Named{"premium": true, "trial": false}
+
+	expected, err := formatter.Format(message, arguments)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "yes no", expected)
+
+	formatted, err := sprig.Register(formatter.New()).Format(message, arguments)
+
+	assert.NoError(test, err)
+	assert.Equal(test, expected, formatted)
+
+	formatted, err = sprig.Register(formatter.New(), sprig.WithNamespace("sprig")).Format(
+		`{premium | ternary "yes" "no"} {premium | sprig.ternary "yes" "no"}`, arguments)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "yes yes", formatted)
+}
+
+func TestFunctions(test *testing.T) {
+	functions := sprig.Functions()
+
+	assert.Contains(test, functions, "env")
+	assert.Contains(test, functions, "upper")
+
+	functions = sprig.Functions(sprig.WithoutUnsafe())
+
+	for _, name := range sprig.UnsafeFunctions {
+		assert.NotContains(test, functions, name)
+	}
+
+	assert.Contains(test, functions, "upper")
+
+	functions = sprig.Functions(sprig.WithAllowlist("upper", "lower", "env"), sprig.WithDenylist("env"))
+
+	assert.Len(test, functions, 2)
+	assert.Contains(test, functions, "lower")
+}
+
+func TestUntrusted(test *testing.T) {
+	f := sprig.Register(formatter.New(), sprig.WithoutUnsafe())
+
+	_, err := f.Format(`{getHostByName "localhost"}`)
+
+	assert.Error(test, err)
+}
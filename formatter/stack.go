@@ -0,0 +1,251 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// DefaultStackDepth defines default maximum number of rendered stack frames.
+const DefaultStackDepth = 32
+
+// maxStackFrames limits number of captured program counters.
+const maxStackFrames = 256
+
+var gStackPrefixes = []string{ // nolint: gochecknoglobals
+	"runtime.", "reflect.", "text/template.", "html/template.", reflect.TypeOf(Stack{}).PkgPath() + ".",
+}
+
+// StackOptions defines how stack traces are rendered by the stack function.
+// Zero values mean defaults.
+type StackOptions struct {
+	// MaxDepth limits number of rendered frames. Remaining frames are
+	// summarized.
+	MaxDepth int
+
+	// Filter returns true for frames that are rendered. Default is
+	// DefaultStackFilter.
+	Filter func(frame runtime.Frame) bool
+}
+
+// Stack holds program counters of captured stack frames. It can be passed as
+// argument and rendered with the stack function.
+type Stack []uintptr
+
+// CaptureStack captures stack of the current goroutine. Skip is number of
+// frames to skip, zero means caller of CaptureStack.
+func CaptureStack(skip int) Stack {
+	pcs := make([]uintptr, maxStackFrames)
+
+	return Stack(pcs[:runtime.Callers(skip+2, pcs)])
+}
+
+// Callers returns program counters of stack frames.
+func (s Stack) Callers() []uintptr {
+	return s
+}
+
+// String returns stack frames rendered with default options.
+func (s Stack) String() string {
+	return renderFrames(s, StackOptions{})
+}
+
+// DefaultStackFilter skips frames of the Go runtime, reflection, template
+// packages and this package.
+func DefaultStackFilter(frame runtime.Frame) bool {
+	for _, prefix := range gStackPrefixes {
+		if strings.HasPrefix(frame.Function, prefix) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// SetStackOptions sets options used by the stack function.
+func (f *Formatter) SetStackOptions(options StackOptions) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.stackOptions = options
+
+	return f
+}
+
+// GetStackOptions returns options used by the stack function.
+func (f *Formatter) GetStackOptions() StackOptions {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.stackOptions
+}
+
+// stackFunction is the stack function registered in built-in functions. It is
+// replaced by formatter specific function during formatting.
+func stackFunction(arguments ...interface{}) (string, error) {
+	return (&config{}).stack(arguments...)
+}
+
+// stack renders stack of the current goroutine or stack attached to provided
+// error or Stack value. Optional maximum depth is placed before value.
+func (f *config) stack(arguments ...interface{}) (result string, err error) {
+	defer recoverExecError(&err)
+
+	options := f.stackOptions
+
+	if (len(arguments) == 2) || ((len(arguments) == 1) && isInteger(arguments[0])) {
+		depth, err := toInt(arguments[0])
+
+		if err != nil {
+			return "", err
+		}
+
+		options.MaxDepth = int(depth)
+		arguments = arguments[1:]
+	}
+
+	switch len(arguments) {
+	case 0:
+		return renderFrames(CaptureStack(0), options), nil
+	case 1:
+		value := evaluate(arguments[0])
+
+		if value == nil {
+			return "", nil
+		}
+
+		if text, ok := stackText(value, options); ok {
+			return text, nil
+		}
+
+		return "", fmt.Errorf("type %T doesn't carry stack trace", value)
+	default:
+		return "", fmt.Errorf("expected optional depth and value, got %d arguments", len(arguments))
+	}
+}
+
+// errorStack returns stack trace carried by error itself rendered with
+// default options.
+func errorStack(err error) string {
+	text, _ := stackText(err, StackOptions{})
+
+	return text
+}
+
+// stackText renders stack trace carried by value. It supports Stack,
+// ExecError and popular conventions: Callers method returning program
+// counters, StackTrace method returning frames like in the
+// github.com/pkg/errors package and Stack method returning text.
+func stackText(value interface{}, options StackOptions) (string, bool) {
+	if execError, ok := value.(*ExecError); ok {
+		return strings.TrimSpace(string(execError.Stack)), true
+	}
+
+	valueOf := reflect.ValueOf(value)
+
+	for _, name := range []string{"Callers", "StackTrace", "Stack"} {
+		method := valueOf.MethodByName(name)
+
+		if !method.IsValid() || (method.Type().NumIn() != 0) || (method.Type().NumOut() != 1) {
+			continue
+		}
+
+		trace := method.Call(nil)[0]
+
+		if pcs, ok := programCounters(trace); ok {
+			return renderFrames(pcs, options), true
+		}
+
+		switch text := trace.Interface().(type) {
+		case []byte:
+			return strings.TrimSpace(string(text)), true
+		case string:
+			return strings.TrimSpace(text), true
+		default:
+			return strings.TrimSpace(fmt.Sprintf("%+v", text)), true
+		}
+	}
+
+	return "", false
+}
+
+// programCounters returns program counters from slice of uintptr based
+// values like []uintptr or pkg/errors StackTrace.
+func programCounters(value reflect.Value) ([]uintptr, bool) {
+	if (value.Kind() != reflect.Slice) || (value.Type().Elem().Kind() != reflect.Uintptr) {
+		return nil, false
+	}
+
+	pcs := make([]uintptr, value.Len())
+
+	for index := range pcs {
+		pcs[index] = uintptr(value.Index(index).Uint())
+	}
+
+	return pcs, true
+}
+
+// renderFrames renders filtered frames as function name followed by indented
+// file and line.
+func renderFrames(pcs []uintptr, options StackOptions) string {
+	if options.MaxDepth <= 0 {
+		options.MaxDepth = DefaultStackDepth
+	}
+
+	if options.Filter == nil {
+		options.Filter = DefaultStackFilter
+	}
+
+	var lines []string
+
+	frames := runtime.CallersFrames(pcs)
+	count := 0
+
+	for more := len(pcs) > 0; more; {
+		var frame runtime.Frame
+
+		frame, more = frames.Next()
+
+		if (frame.Function == "") || !options.Filter(frame) {
+			continue
+		}
+
+		if count < options.MaxDepth {
+			lines = append(lines, frame.Function, "\t"+frame.File+":"+strconv.Itoa(frame.Line))
+		}
+
+		count++
+	}
+
+	if count > options.MaxDepth {
+		lines = append(lines, "... "+strconv.Itoa(count-options.MaxDepth)+" more")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func isInteger(value interface{}) bool {
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// SetUseStringer enables or disables calling String method of arguments
+// implementing fmt.Stringer. When it is disabled values with basic underlying
+// types like integer enums are rendered as their underlying values. It is
+// enabled by default.
+func (f *Formatter) SetUseStringer(enabled bool) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.noStringer = !enabled
+
+	return f
+}
+
+// IsUseStringer returns true if String method of arguments implementing
+// fmt.Stringer is called.
+func (f *Formatter) IsUseStringer() bool {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return !f.noStringer
+}
+
+// raw returns underlying value of value with basic underlying type like
+// integer enum, so its String or FormatValue methods are not used. Other
+// values are returned unchanged.
+func raw(value interface{}) interface{} {
+	valueOf := reflect.ValueOf(evaluate(value))
+
+	switch valueOf.Kind() {
+	case reflect.Bool:
+		return valueOf.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return valueOf.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return valueOf.Uint()
+	case reflect.Float32, reflect.Float64:
+		return valueOf.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return valueOf.Complex()
+	case reflect.String:
+		return valueOf.String()
+	default:
+		return evaluate(value)
+	}
+}
+
+// renderStringer returns underlying value of fmt.Stringer value if calling
+// String method is disabled.
+func (f *config) renderStringer(value interface{}) (interface{}, bool) {
+	if _, ok := value.(fmt.Stringer); !ok || !f.noStringer {
+		return nil, false
+	}
+
+	return raw(value), true
+}
@@ -0,0 +1,100 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"strings"
+)
+
+// These constants define placeholder styles.
+const (
+	// DefaultStyle uses automatic placeholder {p} and positional
+	// placeholders {p0}, {p1} with placeholder prefix.
+	DefaultStyle PlaceholderStyle = iota
+
+	// PythonStyle additionally accepts automatic placeholder {} and
+	// positional placeholders {0}, {1} without prefix like Python str.format.
+	PythonStyle
+)
+
+// These constants define text/template trim markers placed after left and
+// before right delimiter.
+const (
+	trimLeftMarker  = "- "
+	trimRightMarker = " -"
+)
+
+// PlaceholderStyle defines syntax of automatic and positional placeholders.
+type PlaceholderStyle int
+
+// SetPlaceholderStyle sets syntax of automatic and positional placeholders.
+func (f *Formatter) SetPlaceholderStyle(style PlaceholderStyle) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.placeholderStyle = style
+
+	return f
+}
+
+// GetPlaceholderStyle returns syntax of automatic and positional
+// placeholders.
+func (f *Formatter) GetPlaceholderStyle() PlaceholderStyle {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.placeholderStyle
+}
+
+// translateStyle translates placeholders of Python style like {} and {0} to
+// placeholders with prefix like {p} and {p0}. Message is returned unchanged
+// for default style.
+func (f *config) translateStyle(message string) string {
+	if (f.placeholderStyle != PythonStyle) || !strings.Contains(message, f.leftDelimiter) {
+		return message
+	}
+
+	var builder strings.Builder
+
+	for _, s := range scan(message, f.leftDelimiter, f.rightDelimiter) {
+		if s.kind == actionSegment && strings.HasSuffix(s.text, f.rightDelimiter) {
+			builder.WriteString(f.translateAction(s.text))
+		} else {
+			builder.WriteString(s.text)
+		}
+	}
+
+	return builder.String()
+}
+
+// translateAction prefixes leading position in action like {0.Name | upper}
+// with placeholder and fills empty action {} with automatic placeholder.
+func (f *config) translateAction(action string) string {
+	inner := action[len(f.leftDelimiter) : len(action)-len(f.rightDelimiter)]
+	body := strings.TrimLeft(strings.TrimPrefix(inner, trimLeftMarker), " \t")
+	lead := inner[:len(inner)-len(body)]
+
+	if content := strings.TrimSpace(strings.TrimSuffix(body, trimRightMarker)); content == "" {
+		return f.leftDelimiter + lead + f.placeholder + body + f.rightDelimiter
+	}
+
+	digits := len(body) - len(strings.TrimLeft(body, "0123456789"))
+
+	if (digits == 0) || ((digits < len(body)) && !strings.ContainsAny(body[digits:digits+1], " \t.|)[")) {
+		return action
+	}
+
+	return f.leftDelimiter + lead + f.placeholder + body + f.rightDelimiter
+}
@@ -0,0 +1,162 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// maxSuggestions limits number of did-you-mean suggestions.
+const maxSuggestions = 3
+
+// suggestions returns up to three candidates closest to name by edit
+// distance. Candidates that differ in more than third of name are skipped.
+func suggestions(name string, candidates []string) []string {
+	type match struct {
+		name     string
+		distance int
+	}
+
+	limit := maxInt(1, len([]rune(name))/3)
+	found := make(map[string]bool)
+
+	var matches []match
+
+	for _, candidate := range candidates {
+		if found[candidate] || (candidate == name) || strings.HasPrefix(candidate, "_") {
+			continue
+		}
+
+		found[candidate] = true
+
+		if distance := editDistance(strings.ToLower(name), strings.ToLower(candidate)); distance <= limit {
+			matches = append(matches, match{name: candidate, distance: distance})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+
+		return matches[i].name < matches[j].name
+	})
+
+	if len(matches) > maxSuggestions {
+		matches = matches[:maxSuggestions]
+	}
+
+	names := make([]string, 0, len(matches))
+
+	for _, m := range matches {
+		names = append(names, m.name)
+	}
+
+	return names
+}
+
+// didYouMean returns suggestion text like `, did you mean "name"?` or empty
+// string without suggestions.
+func didYouMean(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+
+	quoted := make([]string, 0, len(names))
+
+	for _, name := range names {
+		quoted = append(quoted, strconv.Quote(name))
+	}
+
+	return ", did you mean " + strings.Join(quoted, " or ") + "?"
+}
+
+// editDistance returns number of single rune insertions, deletions,
+// substitutions and transpositions of adjacent runes needed to change a into
+// b.
+func editDistance(a, b string) int {
+	x, y := []rune(a), []rune(b)
+	beforePrevious := make([]int, len(y)+1)
+	previous := make([]int, len(y)+1)
+	current := make([]int, len(y)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(x); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(y); j++ {
+			cost := 1
+
+			if x[i-1] == y[j-1] {
+				cost = 0
+			}
+
+			current[j] = minInt(minInt(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+
+			if (i > 1) && (j > 1) && (x[i-1] == y[j-2]) && (x[i-2] == y[j-1]) {
+				current[j] = minInt(current[j], beforePrevious[j-2]+1)
+			}
+		}
+
+		beforePrevious, previous, current = previous, current, beforePrevious
+	}
+
+	return previous[len(y)]
+}
+
+// functionNames returns names defined in function maps.
+func functionNames(functions []template.FuncMap) []string {
+	var names []string
+
+	for _, funcs := range functions {
+		for name := range funcs {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// memberNames returns exported field and method names of struct or keys of
+// map with string keys.
+func memberNames(value reflect.Value) []string {
+	var names []string
+
+	switch value.Kind() {
+	case reflect.Struct:
+		for index := 0; index < value.NumField(); index++ {
+			if field := value.Type().Field(index); field.PkgPath == "" {
+				names = append(names, field.Name)
+			}
+		}
+	case reflect.Map:
+		names = mapNames(value)
+	}
+
+	for _, t := range []reflect.Type{value.Type(), reflect.PtrTo(value.Type())} {
+		for index := 0; index < t.NumMethod(); index++ {
+			names = append(names, t.Method(index).Name)
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,443 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// These constants define table styles.
+const (
+	ASCIITable TableStyle = iota
+	UnicodeTable
+	MarkdownTable
+)
+
+// These constants define alignments of table columns.
+const (
+	AlignLeft Alignment = iota
+	AlignRight
+	AlignCenter
+)
+
+// TableStyle defines characters used to draw table borders.
+type TableStyle int
+
+// Alignment defines alignment of table column.
+type Alignment int
+
+// TableOptions defines how table is rendered.
+type TableOptions struct {
+	// Style defines table borders. Default is ASCIITable.
+	Style TableStyle
+
+	// Align defines alignments of columns. Columns without alignment are
+	// aligned to the left.
+	Align []Alignment
+
+	// MaxWidth limits width of columns. Longer cells are truncated with an
+	// ellipsis. Zero means no limit.
+	MaxWidth int
+}
+
+type tableBorders struct {
+	top, middle, bottom [3]string
+	line, vertical      string
+	ellipsis            string
+	measure             func(string) int
+}
+
+var gTableBorders = map[TableStyle]tableBorders{ // nolint: gochecknoglobals
+	ASCIITable: {
+		top:      [3]string{"+", "+", "+"},
+		middle:   [3]string{"+", "+", "+"},
+		bottom:   [3]string{"+", "+", "+"},
+		line:     "-",
+		vertical: "|",
+		ellipsis: "...",
+	},
+	UnicodeTable: {
+		top:      [3]string{"┌", "┬", "┐"},
+		middle:   [3]string{"├", "┼", "┤"},
+		bottom:   [3]string{"└", "┴", "┘"},
+		line:     "─",
+		vertical: "│",
+		ellipsis: "…",
+	},
+}
+
+// Table renders aligned table with provided headers. Rows must be a slice or
+// an array of structs, maps with string keys or slices. Headers select
+// struct fields, methods or map keys. For slices of slices headers are only
+// labels of columns. Without headers all exported struct fields or sorted map
+// keys are used. Missing map keys are rendered as empty cells.
+func Table(headers []string, rows interface{}, options TableOptions) (string, error) {
+	return New().Table(headers, rows, options)
+}
+
+// Table renders aligned table with provided headers. Rows must be a slice or
+// an array of structs, maps with string keys or slices. Headers select
+// struct fields, methods or map keys. For slices of slices headers are only
+// labels of columns. Without headers all exported struct fields or sorted map
+// keys are used. Missing map keys are rendered as empty cells. Cells are
+// rendered like replacement fields.
+func (f *Formatter) Table(headers []string, rows interface{}, options TableOptions) (string, error) {
+	return f.snapshot().renderTable(headers, rows, options)
+}
+
+// SetTableOptions sets options used by the table function like
+// {table .Rows "Name" "Age"}.
+func (f *Formatter) SetTableOptions(options TableOptions) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	options.Align = append([]Alignment(nil), options.Align...)
+	f.tableOptions = options
+
+	return f
+}
+
+// GetTableOptions returns options used by the table function.
+func (f *Formatter) GetTableOptions() TableOptions {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	options := f.tableOptions
+	options.Align = append([]Alignment(nil), options.Align...)
+
+	return options
+}
+
+// table is the table function registered in built-in functions. It is
+// replaced by formatter specific function during formatting.
+func table(rows interface{}, headers ...string) (string, error) {
+	return (&config{}).table(rows, headers...)
+}
+
+// table renders table using formatter table options.
+func (f *config) table(rows interface{}, headers ...string) (string, error) {
+	return f.renderTable(headers, rows, f.tableOptions)
+}
+
+func (f *config) renderTable(headers []string, rows interface{}, options TableOptions) (string, error) {
+	cells, headers, err := f.tableCells(headers, rows)
+
+	if err != nil {
+		return "", err
+	}
+
+	return f.renderCells(headers, cells, options)
+}
+
+// renderCells renders already formatted headers and cells as table.
+func (f *config) renderCells(headers []string, cells [][]string, options TableOptions) (string, error) {
+	borders, ok := gTableBorders[options.Style]
+
+	if !ok && (options.Style != MarkdownTable) {
+		return "", fmt.Errorf("unknown table style %d", options.Style)
+	}
+
+	if options.Style == MarkdownTable {
+		borders = tableBorders{vertical: "|", ellipsis: "…"}
+	}
+
+	borders.measure = f.widthAlgorithm.measure()
+	widths := borders.widths(headers, cells, options)
+
+	var lines []string
+
+	if options.Style != MarkdownTable {
+		lines = append(lines, borders.separator(borders.top, widths))
+	}
+
+	if len(headers) > 0 {
+		lines = append(lines, borders.row(headers, widths, options.Align, options.MaxWidth))
+
+		if options.Style == MarkdownTable {
+			lines = append(lines, markdownSeparator(widths, options.Align))
+		} else {
+			lines = append(lines, borders.separator(borders.middle, widths))
+		}
+	}
+
+	for _, row := range cells {
+		lines = append(lines, borders.row(row, widths, options.Align, options.MaxWidth))
+	}
+
+	if options.Style != MarkdownTable {
+		lines = append(lines, borders.separator(borders.bottom, widths))
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// tableCells returns rendered cells and headers of table.
+func (f *config) tableCells(headers []string, rows interface{}) ([][]string, []string, error) {
+	value := reflect.ValueOf(evaluate(rows))
+
+	if (value.Kind() != reflect.Slice) && (value.Kind() != reflect.Array) {
+		return nil, nil, fmt.Errorf("table rows must be a slice or an array, got %T", rows)
+	}
+
+	if len(headers) == 0 {
+		headers = tableHeaders(value)
+	}
+
+	cells := make([][]string, 0, value.Len())
+
+	for index := 0; index < value.Len(); index++ {
+		row := value.Index(index)
+
+		for (row.Kind() == reflect.Interface) || ((row.Kind() == reflect.Ptr) && !row.IsNil()) {
+			row = row.Elem()
+		}
+
+		var values []interface{}
+
+		switch row.Kind() {
+		case reflect.Slice, reflect.Array:
+			for column := 0; column < row.Len(); column++ {
+				values = append(values, row.Index(column).Interface())
+			}
+		case reflect.Map:
+			if row.Type().Key().Kind() != reflect.String {
+				return nil, nil, fmt.Errorf("table row %d map key type %s is not a string", index, row.Type().Key())
+			}
+
+			for _, header := range headers {
+				values = append(values, valueInterface(row.MapIndex(reflect.ValueOf(header).Convert(row.Type().Key()))))
+			}
+		case reflect.Struct:
+			for _, header := range headers {
+				cell, err := f.fieldValue(row, header)
+
+				if err != nil {
+					return nil, nil, fmt.Errorf("table row %d: %w", index, err)
+				}
+
+				values = append(values, valueInterface(cell))
+			}
+		default:
+			return nil, nil, fmt.Errorf("table row %d must be a struct, a map or a slice, got %s", index, row.Type())
+		}
+
+		rendered, err := f.tableRow(values)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		cells = append(cells, rendered)
+	}
+
+	return cells, headers, nil
+}
+
+func (f *config) tableRow(values []interface{}) ([]string, error) {
+	row := make([]string, 0, len(values))
+
+	for _, value := range values {
+		formatted, err := f.formatValue(evaluate(value))
+
+		if err != nil {
+			return nil, err
+		}
+
+		if formatted == nil {
+			formatted = ""
+		}
+
+		row = append(row, strings.ReplaceAll(fmt.Sprint(formatted), "\n", " "))
+	}
+
+	return row, nil
+}
+
+// tableHeaders returns exported field names of struct rows or sorted keys of
+// map rows.
+func tableHeaders(rows reflect.Value) []string {
+	elementType := rows.Type().Elem()
+
+	for elementType.Kind() == reflect.Ptr {
+		elementType = elementType.Elem()
+	}
+
+	var headers []string
+
+	switch elementType.Kind() {
+	case reflect.Struct:
+		for index := 0; index < elementType.NumField(); index++ {
+			if field := elementType.Field(index); field.PkgPath == "" {
+				headers = append(headers, field.Name)
+			}
+		}
+	case reflect.Map, reflect.Interface:
+		found := make(map[string]bool)
+
+		for index := 0; index < rows.Len(); index++ {
+			row := rows.Index(index)
+
+			for row.Kind() == reflect.Interface {
+				row = row.Elem()
+			}
+
+			if (row.Kind() == reflect.Map) && (row.Type().Key().Kind() == reflect.String) {
+				for _, key := range row.MapKeys() {
+					if !found[key.String()] {
+						found[key.String()] = true
+						headers = append(headers, key.String())
+					}
+				}
+			}
+		}
+
+		sort.Strings(headers)
+	}
+
+	return headers
+}
+
+func valueInterface(value reflect.Value) interface{} {
+	if !value.IsValid() || !value.CanInterface() {
+		return nil
+	}
+
+	return value.Interface()
+}
+
+// widths returns widths of columns.
+func (b tableBorders) widths(headers []string, cells [][]string, options TableOptions) []int {
+	var widths []int
+
+	update := func(row []string) {
+		for column, cell := range row {
+			if column >= len(widths) {
+				widths = append(widths, 0)
+			}
+
+			widths[column] = maxInt(widths[column], b.measure(truncateText(cell, options.MaxWidth, b.ellipsis, b.measure)))
+		}
+	}
+
+	update(headers)
+
+	for _, row := range cells {
+		update(row)
+	}
+
+	if options.Style == MarkdownTable {
+		for column := range widths {
+			widths[column] = maxInt(widths[column], 3)
+		}
+	}
+
+	return widths
+}
+
+func (b tableBorders) separator(corners [3]string, widths []int) string {
+	parts := make([]string, 0, len(widths))
+
+	for _, width := range widths {
+		parts = append(parts, strings.Repeat(b.line, width+2))
+	}
+
+	return corners[0] + strings.Join(parts, corners[1]) + corners[2]
+}
+
+func (b tableBorders) row(cells []string, widths []int, align []Alignment, maxWidth int) string {
+	parts := make([]string, 0, len(widths))
+
+	for column, width := range widths {
+		cell := ""
+
+		if column < len(cells) {
+			cell = truncateText(cells[column], maxWidth, b.ellipsis, b.measure)
+		}
+
+		alignment := AlignLeft
+
+		if column < len(align) {
+			alignment = align[column]
+		}
+
+		parts = append(parts, " "+alignText(cell, width, alignment, b.measure)+" ")
+	}
+
+	return b.vertical + strings.Join(parts, b.vertical) + b.vertical
+}
+
+func markdownSeparator(widths []int, align []Alignment) string {
+	parts := make([]string, 0, len(widths))
+
+	for column, width := range widths {
+		alignment := AlignLeft
+
+		if column < len(align) {
+			alignment = align[column]
+		}
+
+		switch alignment {
+		case AlignRight:
+			parts = append(parts, " "+strings.Repeat("-", width-1)+": ")
+		case AlignCenter:
+			parts = append(parts, " :"+strings.Repeat("-", width-2)+": ")
+		default:
+			parts = append(parts, " :"+strings.Repeat("-", width-1)+" ")
+		}
+	}
+
+	return "|" + strings.Join(parts, "|") + "|"
+}
+
+// alignText pads text with spaces to provided width.
+func alignText(text string, width int, alignment Alignment, measure func(string) int) string {
+	padding := maxInt(width-measure(text), 0)
+
+	switch alignment {
+	case AlignRight:
+		return strings.Repeat(" ", padding) + text
+	case AlignCenter:
+		return strings.Repeat(" ", padding/2) + text + strings.Repeat(" ", padding-padding/2)
+	default:
+		return text + strings.Repeat(" ", padding)
+	}
+}
+
+// truncateText truncates text longer than width and appends ellipsis. Zero
+// width means no limit. Text is truncated between grapheme clusters.
+func truncateText(text string, width int, ellipsis string, measure func(string) int) string {
+	if (width <= 0) || (measure(text) <= width) {
+		return text
+	}
+
+	limit := maxInt(width-measure(ellipsis), 0)
+	end := 0
+
+	for index, r := range text {
+		next := index + utf8.RuneLen(r)
+
+		if measure(text[:next]) > limit {
+			break
+		}
+
+		end = next
+	}
+
+	return text[:end] + ellipsis
+}
@@ -0,0 +1,164 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"context"
+	htmltemplate "html/template"
+	"io"
+	"text/template"
+	"text/template/parse"
+)
+
+// parse parses message and checks if all used functions are defined. It
+// returns provided function maps extended with missing placeholders.
+func (f *config) parse(message string, functions []template.FuncMap) (map[string]*parse.Tree, []template.FuncMap, error) {
+	trees, err := f.sourceTrees(message)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	namespaced, err := f.transformNamespaces(trees, functions)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	functions = append(functions, namespaced)
+	functions = append(functions, f.foldFunctions(trees, functions))
+
+	if err := f.checkSandbox(trees, functions); err != nil {
+		return nil, nil, err
+	}
+
+	if err := f.checkMethodCalls(trees); err != nil {
+		return nil, nil, err
+	}
+
+	if f.sandbox != nil {
+		functions = append(functions, f.sandboxRange())
+	}
+	functions = append(functions, f.resolvePlaceholders(trees, functions))
+	functions = append(functions, missingPlaceholders(trees, functions), missingConditions(trees, functions))
+	functions = append(functions, template.FuncMap{
+		fieldFunction:          f.field,
+		fieldOrNilFunction:     f.fieldOrNil,
+		formatValueFunction:    f.formatValue,
+		markdownEscapeFunction: mdEscape,
+	})
+
+	if err := f.checkDepth(trees); err != nil {
+		return nil, nil, err
+	}
+
+	if err := f.checkMixedPlaceholders(trees); err != nil {
+		return nil, nil, err
+	}
+
+	if f.trimWhitespace {
+		trimTrees(trees)
+	}
+
+	if f.pseudoLocalization {
+		pseudoLocalizeTrees(trees)
+	}
+
+	transformFields(trees)
+	transformFormattable(trees)
+
+	if f.safeMarkdown {
+		transformMarkdown(trees)
+	}
+
+	if err := checkFunctions(trees, functions); err != nil {
+		return nil, nil, err
+	}
+
+	return trees, functions, nil
+}
+
+// sourceTrees returns parsed message with overrides and included templates.
+// Trees of compiled message are copied instead of parsing it again.
+func (f *config) sourceTrees(message string) (map[string]*parse.Tree, error) {
+	if (f.compiled != nil) && (f.compiled.message == message) {
+		return copyTrees(f.compiled.trees), nil
+	}
+
+	return f.parseSource(message)
+}
+
+// parseSource parses message with overrides and included templates. Trees
+// don't depend on arguments.
+func (f *config) parseSource(message string) (map[string]*parse.Tree, error) {
+	trees, err := parseTrees(escapeDelimiters(message, f.leftDelimiter, f.rightDelimiter),
+		f.leftDelimiter, f.rightDelimiter)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.addOverrideTrees(trees); err != nil {
+		return nil, err
+	}
+
+	if err := f.addTemplateTrees(trees); err != nil {
+		return nil, err
+	}
+
+	return trees, nil
+}
+
+func copyTrees(trees map[string]*parse.Tree) map[string]*parse.Tree {
+	copied := make(map[string]*parse.Tree, len(trees))
+
+	for name, tree := range trees {
+		copied[name] = tree.Copy()
+	}
+
+	return copied
+}
+
+// executeTrees executes parsed trees. Execution is aborted when context is
+// done or execution timeout expires.
+func (f *config) executeTrees(ctx context.Context, writer io.Writer, trees map[string]*parse.Tree,
+	functions []template.FuncMap, object interface{}) error {
+	return f.withContext(ctx, writer, func(ctx context.Context, writer io.Writer) error {
+		if ctx.Done() != nil {
+			functions = append(functions[:len(functions):len(functions)], contextFunctions(ctx))
+		}
+
+		return f.execute(writer, trees, functions, object)
+	})
+}
+
+// execute executes parsed trees. Panic during execution is returned as
+// ExecError.
+func (f *config) execute(writer io.Writer, trees map[string]*parse.Tree, functions []template.FuncMap, object interface{}) (err error) {
+	defer recoverExecError(&err)
+
+	return f.executionEngine().Execute(writer, trees, functions, object)
+}
+
+func (f *config) escape(text string) string {
+	switch {
+	case f.safeHTML:
+		return htmltemplate.HTMLEscapeString(text)
+	case f.safeMarkdown:
+		return string(mdEscape(text))
+	default:
+		return text
+	}
+}
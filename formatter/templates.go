@@ -0,0 +1,133 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"text/template/parse"
+)
+
+// Templates defines a map of named message fragments.
+type Templates map[string]string
+
+// AddTemplate adds named message fragment that messages can include with
+// {template "name"}. Fragment uses the same placeholders, functions and
+// delimiters like message. Template defined in message takes precedence over
+// fragment with the same name. Fragment is parsed when message uses it.
+func (f *Formatter) AddTemplate(name, text string) *Formatter {
+	return f.AddTemplates(Templates{name: text})
+}
+
+// AddTemplates adds named message fragments.
+func (f *Formatter) AddTemplates(templates Templates) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.templates = copyTemplates(f.templates)
+
+	for name, text := range templates {
+		f.templates[name] = text
+	}
+
+	return f
+}
+
+// GetTemplate returns named message fragment.
+func (f *Formatter) GetTemplate(name string) (text string, ok bool) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	text, ok = f.templates[name]
+
+	return text, ok
+}
+
+// GetTemplates returns a copy of named message fragments.
+func (f *Formatter) GetTemplates() Templates {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return copyTemplates(f.templates)
+}
+
+// RemoveTemplate removes named message fragment.
+func (f *Formatter) RemoveTemplate(name string) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.templates = copyTemplates(f.templates)
+
+	delete(f.templates, name)
+
+	return f
+}
+
+// addTemplateTrees parses fragments included by trees, also indirectly, and
+// adds them to trees.
+func (f *config) addTemplateTrees(trees map[string]*parse.Tree) error {
+	if len(f.templates) == 0 {
+		return nil
+	}
+
+	for pending := includedTemplates(trees); len(pending) > 0; {
+		name := pending[0]
+		pending = pending[1:]
+
+		text, ok := f.templates[name]
+
+		if _, defined := trees[name]; defined || !ok {
+			continue
+		}
+
+		fragment := make(map[string]*parse.Tree)
+
+		if err := parseTree(name, escapeDelimiters(text, f.leftDelimiter, f.rightDelimiter),
+			f.leftDelimiter, f.rightDelimiter, fragment); err != nil {
+			return err
+		}
+
+		for fragmentName, tree := range fragment {
+			if _, defined := trees[fragmentName]; !defined {
+				trees[fragmentName] = tree
+			}
+		}
+
+		pending = append(pending, includedTemplates(fragment)...)
+	}
+
+	return nil
+}
+
+// includedTemplates returns names of templates included by trees.
+func includedTemplates(trees map[string]*parse.Tree) []string {
+	var names []string
+
+	walkTrees(trees, func(_ *parse.Tree, node parse.Node) {
+		if n, ok := node.(*parse.TemplateNode); ok {
+			names = append(names, n.Name)
+		}
+	})
+
+	return names
+}
+
+func copyTemplates(templates Templates) Templates {
+	copied := make(Templates, len(templates))
+
+	for name, text := range templates {
+		copied[name] = text
+	}
+
+	return copied
+}
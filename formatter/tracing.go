@@ -0,0 +1,145 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"context"
+	"hash/fnv"
+	"io"
+	"strconv"
+	"text/template"
+	"text/template/parse"
+)
+
+// These constants define names of spans started by formatter.
+const (
+	ParseSpan   = "formatter.parse"
+	ExecuteSpan = "formatter.execute"
+)
+
+// These constants define keys of span attributes set by formatter.
+const (
+	TemplateKeyAttribute = "formatter.template_key"
+	OutputSizeAttribute  = "formatter.output_size"
+)
+
+// Tracer starts spans around parsing and execution of messages. It can be
+// used to adapt tracing libraries like OpenTelemetry.
+type Tracer interface {
+	// Start starts span with provided name. Returned context is used for
+	// operations traced by span.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span defines traced operation.
+type Span interface {
+	// SetAttribute sets span attribute.
+	SetAttribute(key string, value interface{})
+
+	// End ends span with error status if error is not nil.
+	End(err error)
+}
+
+// SetTracer sets tracer that starts spans around parsing and execution of
+// messages. Spans have template key attribute and execution span has also
+// output size attribute. Simple messages with only placeholders are not
+// parsed, so they have only execution span. Plain messages without
+// replacement fields are not traced. Nil disables it.
+func (f *Formatter) SetTracer(tracer Tracer) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.tracer = tracer
+
+	return f
+}
+
+// GetTracer returns tracer that starts spans around parsing and execution of
+// messages.
+func (f *Formatter) GetTracer() Tracer {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.tracer
+}
+
+// TemplateKey returns key that identifies message in spans without revealing
+// its content. It is a hexadecimal FNV-1a hash of message.
+func TemplateKey(message string) string {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(message))
+
+	return strconv.FormatUint(hash.Sum64(), 16)
+}
+
+// parseTraced parses message as parse does. Parsing is traced and parse
+// error is reported to metrics sink.
+func (f *config) parseTraced(ctx context.Context, key, message string,
+	functions []template.FuncMap) (map[string]*parse.Tree, []template.FuncMap, error) {
+	_, span := f.startSpan(ctx, ParseSpan, key)
+
+	trees, functions, err := f.parse(message, functions)
+
+	if (err == nil) && f.isContextChecked(ctx) {
+		checkContextTrees(trees)
+	}
+
+	if span != nil {
+		span.End(err)
+	}
+
+	f.reportParseError(err)
+
+	return trees, functions, err
+}
+
+// executeTraced executes parsed trees as executeTrees does. Execution is
+// traced with output size.
+func (f *config) executeTraced(ctx context.Context, key string, writer io.Writer, trees map[string]*parse.Tree,
+	functions []template.FuncMap, object interface{}) error {
+	return f.traceExecute(ctx, key, writer, func(ctx context.Context, writer io.Writer) error {
+		return f.executeTrees(ctx, writer, trees, functions, object)
+	})
+}
+
+// traceExecute calls execute in execution span with output size.
+func (f *config) traceExecute(ctx context.Context, key string, writer io.Writer,
+	execute func(ctx context.Context, writer io.Writer) error) error {
+	ctx, span := f.startSpan(ctx, ExecuteSpan, key)
+
+	if span == nil {
+		return execute(ctx, writer)
+	}
+
+	counter := &countingWriter{writer: writer}
+
+	err := execute(ctx, counter)
+
+	span.SetAttribute(OutputSizeAttribute, counter.size)
+	span.End(err)
+
+	return err
+}
+
+func (f *config) startSpan(ctx context.Context, name, key string) (context.Context, Span) {
+	if f.tracer == nil {
+		return ctx, nil
+	}
+
+	ctx, span := f.tracer.Start(ctx, name)
+	span.SetAttribute(TemplateKeyAttribute, key)
+
+	return ctx, span
+}
@@ -0,0 +1,176 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"sort"
+	"text/template"
+	"text/template/parse"
+)
+
+var gTemplateFunctions = map[string]bool{ // nolint: gochecknoglobals
+	"and":      true,
+	"call":     true,
+	"html":     true,
+	"index":    true,
+	"slice":    true,
+	"js":       true,
+	"len":      true,
+	"not":      true,
+	"or":       true,
+	"print":    true,
+	"printf":   true,
+	"println":  true,
+	"urlquery": true,
+	"eq":       true,
+	"ge":       true,
+	"gt":       true,
+	"le":       true,
+	"lt":       true,
+	"ne":       true,
+}
+
+// parseTrees parses message without checking if functions are defined.
+func parseTrees(message, left, right string) (map[string]*parse.Tree, error) {
+	trees := make(map[string]*parse.Tree)
+
+	if err := parseTree("", message, left, right, trees); err != nil {
+		return nil, err
+	}
+
+	return trees, nil
+}
+
+// parseTree parses message under provided name and adds it together with
+// defined templates to trees.
+func parseTree(name, message, left, right string, trees map[string]*parse.Tree) error {
+	tree := parse.New(name)
+	tree.Mode = parse.SkipFuncCheck
+
+	if _, err := tree.Parse(message, left, right, trees); err != nil {
+		return err
+	}
+
+	trees[tree.Name] = tree
+
+	return nil
+}
+
+// walk calls visit for node and all its descendants.
+func walk(node parse.Node, visit func(node parse.Node)) {
+	if node == nil {
+		return
+	}
+
+	visit(node)
+
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n != nil {
+			for _, child := range n.Nodes {
+				walk(child, visit)
+			}
+		}
+	case *parse.ActionNode:
+		walk(n.Pipe, visit)
+	case *parse.IfNode:
+		walkBranch(&n.BranchNode, visit)
+	case *parse.RangeNode:
+		walkBranch(&n.BranchNode, visit)
+	case *parse.WithNode:
+		walkBranch(&n.BranchNode, visit)
+	case *parse.TemplateNode:
+		walk(n.Pipe, visit)
+	case *parse.PipeNode:
+		if n != nil {
+			for _, command := range n.Cmds {
+				walk(command, visit)
+			}
+		}
+	case *parse.CommandNode:
+		for _, argument := range n.Args {
+			walk(argument, visit)
+		}
+	case *parse.ChainNode:
+		walk(n.Node, visit)
+	}
+}
+
+func walkBranch(branch *parse.BranchNode, visit func(node parse.Node)) {
+	walk(branch.Pipe, visit)
+
+	if branch.List != nil {
+		walk(branch.List, visit)
+	}
+
+	if branch.ElseList != nil {
+		walk(branch.ElseList, visit)
+	}
+}
+
+// walkTrees calls visit for all nodes in all trees.
+func walkTrees(trees map[string]*parse.Tree, visit func(tree *parse.Tree, node parse.Node)) {
+	names := make([]string, 0, len(trees))
+
+	for name := range trees {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		tree := trees[name]
+
+		if tree.Root == nil {
+			continue
+		}
+
+		walk(tree.Root, func(node parse.Node) {
+			visit(tree, node)
+		})
+	}
+}
+
+// isDefined returns true if function is defined in one of function maps or
+// it is a text/template built-in function.
+func isDefined(name string, functions []template.FuncMap) bool {
+	if gTemplateFunctions[name] {
+		return true
+	}
+
+	for _, funcs := range functions {
+		if _, ok := funcs[name]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkFunctions returns an error for the first function that is not defined.
+func checkFunctions(trees map[string]*parse.Tree, functions []template.FuncMap) (err error) {
+	walkTrees(trees, func(tree *parse.Tree, node parse.Node) {
+		if identifier, ok := node.(*parse.IdentifierNode); ok && (err == nil) && !isDefined(identifier.Ident, functions) {
+			location, _ := tree.ErrorContext(identifier)
+			err = &UndefinedFunctionError{
+				Name:        identifier.Ident,
+				Location:    location,
+				Suggestions: suggestions(identifier.Ident, functionNames(functions)),
+			}
+		}
+	})
+
+	return err
+}
@@ -0,0 +1,111 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"bytes"
+	"text/template/parse"
+)
+
+// SetTrimWhitespace enables trimming of whitespace around control actions
+// like if, else, range, with and end. Spaces and tabs before control action at
+// the beginning of line and newline just after it are removed, so multi-line
+// messages don't produce stray blank lines and spaces. Trim markers like
+// {- p -} can be always used to trim whitespace around any action.
+func (f *Formatter) SetTrimWhitespace(enabled bool) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.trimWhitespace = enabled
+
+	return f
+}
+
+// IsTrimWhitespace returns true if trimming of whitespace around control
+// actions is enabled.
+func (f *Formatter) IsTrimWhitespace() bool {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.trimWhitespace
+}
+
+// trimTrees trims whitespace around control actions in all trees.
+func trimTrees(trees map[string]*parse.Tree) {
+	for _, tree := range trees {
+		trimList(tree.Root)
+	}
+}
+
+func trimList(list *parse.ListNode) {
+	if list == nil {
+		return
+	}
+
+	for index, node := range list.Nodes {
+		var branches []*parse.ListNode
+
+		switch n := node.(type) {
+		case *parse.IfNode:
+			branches = []*parse.ListNode{n.List, n.ElseList}
+		case *parse.RangeNode:
+			branches = []*parse.ListNode{n.List, n.ElseList}
+		case *parse.WithNode:
+			branches = []*parse.ListNode{n.List, n.ElseList}
+		case *parse.BreakNode, *parse.ContinueNode:
+		default:
+			continue
+		}
+
+		if index > 0 {
+			trimLineEnd(list.Nodes[index-1])
+		}
+
+		if index+1 < len(list.Nodes) {
+			trimLineStart(list.Nodes[index+1])
+		}
+
+		for _, branch := range branches {
+			if (branch != nil) && (len(branch.Nodes) > 0) {
+				trimLineStart(branch.Nodes[0])
+				trimLineEnd(branch.Nodes[len(branch.Nodes)-1])
+				trimList(branch)
+			}
+		}
+	}
+}
+
+// trimLineStart removes whitespace up to and including the first newline
+// from text node if there is only whitespace before it.
+func trimLineStart(node parse.Node) {
+	if text, ok := node.(*parse.TextNode); ok {
+		trimmed := bytes.TrimLeft(text.Text, " \t")
+
+		if bytes.HasPrefix(trimmed, []byte("\r\n")) {
+			text.Text = trimmed[2:]
+		} else if bytes.HasPrefix(trimmed, []byte("\n")) {
+			text.Text = trimmed[1:]
+		}
+	}
+}
+
+// trimLineEnd removes spaces and tabs after the last newline from text node.
+func trimLineEnd(node parse.Node) {
+	if text, ok := node.(*parse.TextNode); ok {
+		if index := bytes.LastIndexByte(text.Text, '\n'); (index >= 0) && (len(bytes.Trim(text.Text[index+1:], " \t")) == 0) {
+			text.Text = text.Text[:index+1]
+		}
+	}
+}
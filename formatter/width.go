@@ -0,0 +1,256 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+// These constants define algorithms used to measure width of text by
+// functions like wrap, pad and center and by tables.
+const (
+	// DisplayWidth counts columns used to display grapheme clusters. East
+	// Asian wide characters and emoji use two columns, combining marks and
+	// joined emoji sequences don't use additional columns.
+	DisplayWidth WidthAlgorithm = iota
+
+	// RuneWidth counts runes.
+	RuneWidth
+
+	// ByteWidth counts bytes.
+	ByteWidth
+)
+
+const (
+	zeroWidthJoiner   = '\u200D'
+	emojiPresentation = '\uFE0F'
+)
+
+// WidthAlgorithm defines how width of text is measured.
+type WidthAlgorithm int
+
+// gWideRanges defines East Asian wide and fullwidth characters and emoji
+// displayed using two columns. Ranges are sorted.
+var gWideRanges = []struct{ first, last rune }{ // nolint: gochecknoglobals
+	{0x1100, 0x115F},
+	{0x231A, 0x231B},
+	{0x2329, 0x232A},
+	{0x23E9, 0x23EC},
+	{0x23F0, 0x23F0},
+	{0x23F3, 0x23F3},
+	{0x25FD, 0x25FE},
+	{0x2614, 0x2615},
+	{0x2648, 0x2653},
+	{0x267F, 0x267F},
+	{0x2693, 0x2693},
+	{0x26A1, 0x26A1},
+	{0x26AA, 0x26AB},
+	{0x26BD, 0x26BE},
+	{0x26C4, 0x26C5},
+	{0x26CE, 0x26CE},
+	{0x26D4, 0x26D4},
+	{0x26EA, 0x26EA},
+	{0x26F2, 0x26F3},
+	{0x26F5, 0x26F5},
+	{0x26FA, 0x26FA},
+	{0x26FD, 0x26FD},
+	{0x2705, 0x2705},
+	{0x270A, 0x270B},
+	{0x2728, 0x2728},
+	{0x274C, 0x274C},
+	{0x274E, 0x274E},
+	{0x2753, 0x2755},
+	{0x2757, 0x2757},
+	{0x2795, 0x2797},
+	{0x27B0, 0x27B0},
+	{0x27BF, 0x27BF},
+	{0x2B1B, 0x2B1C},
+	{0x2B50, 0x2B50},
+	{0x2B55, 0x2B55},
+	{0x2E80, 0x303E},
+	{0x3041, 0x33FF},
+	{0x3400, 0x4DBF},
+	{0x4E00, 0x9FFF},
+	{0xA000, 0xA4CF},
+	{0xA960, 0xA97F},
+	{0xAC00, 0xD7A3},
+	{0xF900, 0xFAFF},
+	{0xFE10, 0xFE19},
+	{0xFE30, 0xFE6F},
+	{0xFF00, 0xFF60},
+	{0xFFE0, 0xFFE6},
+	{0x16FE0, 0x16FE4},
+	{0x17000, 0x18AFF},
+	{0x1B000, 0x1B2FF},
+	{0x1F004, 0x1F004},
+	{0x1F0CF, 0x1F0CF},
+	{0x1F18E, 0x1F18E},
+	{0x1F191, 0x1F19A},
+	{0x1F200, 0x1F2FF},
+	{0x1F300, 0x1F64F},
+	{0x1F680, 0x1F6FF},
+	{0x1F7E0, 0x1F7EB},
+	{0x1F90C, 0x1F9FF},
+	{0x1FA70, 0x1FAFF},
+	{0x20000, 0x3FFFD},
+}
+
+// gWidthFunctions defines functions that measure width of text for each
+// width algorithm.
+var gWidthFunctions = map[WidthAlgorithm]template.FuncMap{ // nolint: gochecknoglobals
+	DisplayWidth: widthFunctions(textWidth),
+	RuneWidth:    widthFunctions(utf8.RuneCountInString),
+	ByteWidth:    widthFunctions(byteWidth),
+}
+
+// SetWidthAlgorithm sets algorithm used to measure width of text by functions
+// like wrap, pad and center and by tables. Default is DisplayWidth.
+func (f *Formatter) SetWidthAlgorithm(algorithm WidthAlgorithm) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.widthAlgorithm = algorithm
+
+	return f
+}
+
+// GetWidthAlgorithm returns algorithm used to measure width of text.
+func (f *Formatter) GetWidthAlgorithm() WidthAlgorithm {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.widthAlgorithm
+}
+
+// measure returns function that measures width of text. Unknown algorithms
+// fall back to DisplayWidth.
+func (a WidthAlgorithm) measure() func(string) int {
+	switch a {
+	case RuneWidth:
+		return utf8.RuneCountInString
+	case ByteWidth:
+		return byteWidth
+	default:
+		return textWidth
+	}
+}
+
+// functions returns width aware functions for algorithm.
+func (a WidthAlgorithm) functions() template.FuncMap {
+	if functions, ok := gWidthFunctions[a]; ok {
+		return functions
+	}
+
+	return gWidthFunctions[DisplayWidth]
+}
+
+func widthFunctions(measure func(string) int) template.FuncMap {
+	return template.FuncMap{
+		"width": measure,
+		"wrap": func(width int, text string) string {
+			return wrapText(width, text, measure)
+		},
+		"pad": func(width int, text string) string {
+			return alignText(text, width, AlignLeft, measure)
+		},
+		"padLeft": func(width int, text string) string {
+			return alignText(text, width, AlignRight, measure)
+		},
+		"center": func(width int, text string) string {
+			return alignText(text, width, AlignCenter, measure)
+		},
+	}
+}
+
+// textWidth returns number of columns used to display text. Runes joined with
+// zero width joiner, emoji modifiers and combining marks form grapheme
+// cluster with preceding rune. Pair of regional indicators forms a flag.
+func textWidth(text string) int {
+	width, cluster := 0, 0
+	joined, regional := false, false
+
+	for _, r := range text {
+		switch {
+		case joined:
+			joined = false
+		case r == zeroWidthJoiner:
+			joined = true
+		case r == emojiPresentation:
+			if cluster == 1 {
+				width++
+				cluster = 2
+			}
+		case isRegionalIndicator(r):
+			if !regional {
+				width += 2
+				cluster = 2
+			}
+
+			regional = !regional
+
+			continue
+		default:
+			if w := runeWidth(r); w > 0 {
+				width += w
+				cluster = w
+			}
+		}
+
+		regional = false
+	}
+
+	return width
+}
+
+// runeWidth returns number of columns used to display rune. Control
+// characters, combining marks, format characters, variation selectors and
+// emoji modifiers don't use any column, wide characters use two.
+func runeWidth(r rune) int {
+	switch {
+	case unicode.In(r, unicode.Cc, unicode.Mn, unicode.Me, unicode.Cf),
+		(r >= 0x1160) && (r <= 0x11FF),
+		(r >= 0xFE00) && (r <= 0xFE0F),
+		(r >= 0x1F3FB) && (r <= 0x1F3FF):
+		return 0
+	case isWide(r):
+		return 2
+	default:
+		return 1
+	}
+}
+
+func isWide(r rune) bool {
+	for _, wide := range gWideRanges {
+		if r < wide.first {
+			return false
+		}
+
+		if r <= wide.last {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isRegionalIndicator(r rune) bool {
+	return (r >= 0x1F1E6) && (r <= 0x1F1FF)
+}
+
+func byteWidth(text string) int {
+	return len(text)
+}
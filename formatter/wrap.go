@@ -0,0 +1,85 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"strings"
+	"unicode"
+)
+
+// wrapText wraps text to lines not longer than width columns measured with
+// provided function. Lines are broken between words, words longer than width
+// are placed in separate lines. Existing line breaks and leading whitespace of
+// lines are preserved.
+func wrapText(width int, text string, measure func(string) int) string {
+	if width <= 0 {
+		return text
+	}
+
+	lines := strings.Split(text, "\n")
+	wrapped := make([]string, 0, len(lines))
+
+	for _, line := range lines {
+		prefix := line[:len(line)-len(strings.TrimLeftFunc(line, unicode.IsSpace))]
+		words := strings.Fields(line)
+
+		if len(words) == 0 {
+			wrapped = append(wrapped, "")
+			continue
+		}
+
+		current, used := prefix+words[0], measure(prefix+words[0])
+
+		for _, word := range words[1:] {
+			if used+1+measure(word) > width {
+				wrapped = append(wrapped, current)
+				current, used = prefix+word, measure(prefix+word)
+
+				continue
+			}
+
+			current += " " + word
+			used += 1 + measure(word)
+		}
+
+		wrapped = append(wrapped, current)
+	}
+
+	return strings.Join(wrapped, "\n")
+}
+
+// indent indents all non-empty lines of text with spaces.
+func indent(width int, text string) string {
+	return indentLines(width, text, 0)
+}
+
+// hangingIndent indents all non-empty lines of text except the first one
+// with spaces.
+func hangingIndent(width int, text string) string {
+	return indentLines(width, text, 1)
+}
+
+func indentLines(width int, text string, skip int) string {
+	prefix := strings.Repeat(" ", maxInt(width, 0))
+	lines := strings.Split(text, "\n")
+
+	for index := skip; index < len(lines); index++ {
+		if lines[index] != "" {
+			lines[index] = prefix + lines[index]
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
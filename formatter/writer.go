@@ -0,0 +1,109 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+)
+
+// DefaultWriteBufferSize defines default size of buffer used to write
+// formatted output to unbuffered writers.
+const DefaultWriteBufferSize = 4096
+
+var gWriteBuffers sync.Pool // nolint: gochecknoglobals
+
+// SetWriteBufferSize sets size of buffer used by FormatWriter and
+// FormatWriterContext to write formatted output to writers that are not
+// buffered, like files or network connections. Output is written in chunks of
+// this size, so there are less system calls. Writers like bytes.Buffer,
+// strings.Builder and bufio.Writer are not buffered again. Zero or negative
+// size disables buffering.
+func (f *Formatter) SetWriteBufferSize(size int) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.writeBufferSize = size
+
+	return f
+}
+
+// GetWriteBufferSize returns size of buffer used to write formatted output to
+// writers that are not buffered.
+func (f *Formatter) GetWriteBufferSize() int {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.writeBufferSize
+}
+
+// buffered calls write with writer buffered by write buffer if provided
+// writer is not buffered. Buffered output is flushed also on error, so writer
+// gets the same output as without buffering.
+func (f *config) buffered(writer io.Writer, write func(writer io.Writer) error) error {
+	if (f.writeBufferSize <= 0) || isBuffered(writer) {
+		return write(writer)
+	}
+
+	buffer := getWriteBuffer(writer, f.writeBufferSize)
+	defer putWriteBuffer(buffer)
+
+	err := write(buffer)
+
+	if flushErr := flush(buffer); err == nil {
+		err = flushErr
+	}
+
+	return err
+}
+
+// flush flushes write buffer. Panic of writer is returned as ExecError like
+// during execution.
+func flush(buffer *bufio.Writer) (err error) {
+	defer recoverExecError(&err)
+
+	return buffer.Flush()
+}
+
+func isBuffered(writer io.Writer) bool {
+	switch writer.(type) {
+	case *bytes.Buffer, *strings.Builder, *bufio.Writer, *bufio.ReadWriter:
+		return true
+	default:
+		return false
+	}
+}
+
+// getWriteBuffer returns write buffer with provided size from the pool.
+func getWriteBuffer(writer io.Writer, size int) *bufio.Writer {
+	if buffer, ok := gWriteBuffers.Get().(*bufio.Writer); ok && (buffer.Size() == size) {
+		buffer.Reset(writer)
+		return buffer
+	}
+
+	return bufio.NewWriterSize(writer, size)
+}
+
+// putWriteBuffer returns write buffer to the pool. Large buffers are dropped
+// like in putBuffer.
+func putWriteBuffer(buffer *bufio.Writer) {
+	if buffer.Size() <= maxPooledBufferSize {
+		buffer.Reset(nil)
+		gWriteBuffers.Put(buffer)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatter
+
+import (
+	"reflect"
+)
+
+// ZeroRenderer renders zero value of registered type, for example empty
+// string or zero time.
+type ZeroRenderer func(value interface{}) string
+
+// SetNilText sets text rendered instead of <nil> for nil values, nil pointers
+// and nil interfaces. Empty text restores default rendering.
+func (f *Formatter) SetNilText(text string) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.nilText = text
+
+	return f
+}
+
+// GetNilText returns text rendered for nil values.
+func (f *Formatter) GetNilText() string {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.nilText
+}
+
+// SetZeroRenderer sets renderer used for zero values of provided type. It
+// takes precedence over nil text for nil values of pointer types. Nil removes
+// renderer.
+func (f *Formatter) SetZeroRenderer(t reflect.Type, renderer ZeroRenderer) *Formatter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	renderers := make(map[reflect.Type]ZeroRenderer, len(f.zeroRenderers)+1)
+
+	for key, value := range f.zeroRenderers {
+		renderers[key] = value
+	}
+
+	if renderer != nil {
+		renderers[t] = renderer
+	} else {
+		delete(renderers, t)
+	}
+
+	f.zeroRenderers = renderers
+
+	return f
+}
+
+// GetZeroRenderer returns renderer used for zero values of provided type or
+// nil.
+func (f *Formatter) GetZeroRenderer(t reflect.Type) ZeroRenderer {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.zeroRenderers[t]
+}
+
+// renderZero returns rendered nil or zero value. It returns false if value is
+// rendered as usual.
+func (f *config) renderZero(value interface{}) (string, bool) {
+	valueOf := reflect.ValueOf(value)
+
+	if !valueOf.IsValid() {
+		return f.nilText, f.nilText != ""
+	}
+
+	if renderer, ok := f.zeroRenderers[valueOf.Type()]; ok && valueOf.IsZero() {
+		return renderer(value), true
+	}
+
+	switch valueOf.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return f.nilText, (f.nilText != "") && valueOf.IsNil()
+	default:
+		return "", false
+	}
+}
@@ -14,9 +14,16 @@
 
 module gitlab.com/tymonx/go-formatter
 
-go 1.14
+go 1.21
 
 require (
+	github.com/BurntSushi/toml v1.2.1
 	github.com/golang/mock v1.4.4
 	github.com/stretchr/testify v1.6.1
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
 )
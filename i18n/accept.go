@@ -0,0 +1,63 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseAcceptLanguage returns languages from the Accept-Language HTTP header
+// ordered by quality value. Wildcard and languages with zero quality are
+// skipped. Result can be passed directly to Bundle.Formatter.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		language string
+		quality  float64
+	}
+
+	var parsed []weighted
+
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		language := strings.TrimSpace(fields[0])
+		quality := 1.0
+
+		for _, parameter := range fields[1:] {
+			if value := strings.TrimSpace(parameter); strings.HasPrefix(value, "q=") {
+				if q, err := strconv.ParseFloat(value[2:], 64); err == nil {
+					quality = q
+				}
+			}
+		}
+
+		if (language != "") && (language != "*") && (quality > 0) {
+			parsed = append(parsed, weighted{language: Normalize(language), quality: quality})
+		}
+	}
+
+	sort.SliceStable(parsed, func(i, j int) bool {
+		return parsed[i].quality > parsed[j].quality
+	})
+
+	languages := make([]string, 0, len(parsed))
+
+	for _, p := range parsed {
+		languages = append(languages, p.language)
+	}
+
+	return languages
+}
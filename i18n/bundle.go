@@ -0,0 +1,321 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"gitlab.com/tymonx/go-formatter/catalog"
+	"gitlab.com/tymonx/go-formatter/formatter"
+)
+
+// Bundle defines a set of message catalogs for multiple languages. Bundle is
+// safe for concurrent use.
+type Bundle struct {
+	mutex           sync.RWMutex
+	defaultLanguage string
+	catalogs        map[string]*catalog.Catalog
+}
+
+// NewBundle creates a new empty bundle with provided default language used
+// as the last fallback.
+func NewBundle(defaultLanguage string) *Bundle {
+	return &Bundle{
+		defaultLanguage: Normalize(defaultLanguage),
+		catalogs:        make(map[string]*catalog.Catalog),
+	}
+}
+
+// Load creates a new bundle with message catalogs loaded from all files
+// matching pattern in file system.
+func Load(defaultLanguage string, fsys fs.FS, pattern string) (*Bundle, error) {
+	b := NewBundle(defaultLanguage)
+
+	if err := b.Load(fsys, pattern); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// Load loads message catalogs from all files matching pattern in file system.
+// Language is taken from the last dot separated part of file name without
+// extension, for example en.yaml or messages.pl-PL.json. Files for the same
+// language are merged, message key defined more than once in them is
+// reported as an error. Catalogs created for new languages use formatter with
+// locale set to language.
+func (b *Bundle) Load(fsys fs.FS, pattern string) error {
+	names, err := fs.Glob(fsys, pattern)
+
+	if err != nil {
+		return err
+	}
+
+	if len(names) == 0 {
+		return fmt.Errorf("no files match pattern %q", pattern)
+	}
+
+	files := make(map[string][]string)
+
+	for _, name := range names {
+		language := fileLanguage(name)
+		files[language] = append(files[language], name)
+	}
+
+	catalogs := make(map[string]*catalog.Catalog, len(files))
+
+	for language, names := range files {
+		loaded, err := catalog.LoadFiles(fsys, names...)
+
+		if err != nil {
+			return err
+		}
+
+		catalogs[language] = loaded
+	}
+
+	for language, loaded := range catalogs {
+		b.languageCatalog(language).Merge(loaded)
+	}
+
+	return nil
+}
+
+// languageCatalog returns message catalog for provided language. Catalog with
+// formatter with locale set to language is created if there is no catalog
+// for it.
+func (b *Bundle) languageCatalog(language string) *catalog.Catalog {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	language = Normalize(language)
+
+	c, ok := b.catalogs[language]
+
+	if !ok {
+		c = catalog.New().SetFormatter(formatter.New(formatter.WithLocale(language)))
+		b.catalogs[language] = c
+	}
+
+	return c
+}
+
+// AddCatalog adds message catalog for provided language. Existing catalog is
+// replaced.
+func (b *Bundle) AddCatalog(language string, c *catalog.Catalog) *Bundle {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.catalogs[Normalize(language)] = c
+
+	return b
+}
+
+// Catalog returns message catalog for provided language or nil if there is
+// no catalog for it.
+func (b *Bundle) Catalog(language string) *catalog.Catalog {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	return b.catalogs[Normalize(language)]
+}
+
+// Languages returns sorted languages of all catalogs in bundle.
+func (b *Bundle) Languages() []string {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	languages := make([]string, 0, len(b.catalogs))
+
+	for language := range b.catalogs {
+		languages = append(languages, language)
+	}
+
+	sort.Strings(languages)
+
+	return languages
+}
+
+// GetDefaultLanguage returns default language used as the last fallback.
+func (b *Bundle) GetDefaultLanguage() string {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	return b.defaultLanguage
+}
+
+// Lint compares catalogs of all languages against catalog of default
+// language using catalog.Lint. It returns nil if there is no catalog for
+// default language.
+func (b *Bundle) Lint() []catalog.Issue {
+	source := b.Catalog(b.GetDefaultLanguage())
+
+	if source == nil {
+		return nil
+	}
+
+	translations := make(map[string]*catalog.Catalog)
+
+	for _, language := range b.Languages() {
+		if language != b.GetDefaultLanguage() {
+			translations[language] = b.Catalog(language)
+		}
+	}
+
+	return catalog.Lint(source, translations)
+}
+
+// Formatter returns formatter that renders messages in the first available
+// of provided languages. Languages are ordered by preference like in the
+// Accept-Language header.
+func (b *Bundle) Formatter(languages ...string) *Formatter {
+	return &Formatter{bundle: b, languages: b.fallbacks(languages)}
+}
+
+// fallbacks returns available languages in fallback order.
+func (b *Bundle) fallbacks(languages []string) []string {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	var chain []string
+
+	added := make(map[string]bool)
+
+	add := func(language string) {
+		if _, ok := b.catalogs[language]; ok && !added[language] {
+			added[language] = true
+			chain = append(chain, language)
+		}
+	}
+
+	for _, language := range languages {
+		language = Normalize(language)
+
+		for {
+			add(language)
+
+			index := strings.LastIndexByte(language, '-')
+
+			if index < 0 {
+				break
+			}
+
+			language = language[:index]
+		}
+	}
+
+	add(b.defaultLanguage)
+
+	return chain
+}
+
+// Formatter renders messages from bundle using language fallback chain.
+type Formatter struct {
+	bundle    *Bundle
+	languages []string
+}
+
+// Languages returns languages in fallback order.
+func (f *Formatter) Languages() []string {
+	return append([]string(nil), f.languages...)
+}
+
+// Language returns the most preferred available language.
+func (f *Formatter) Language() string {
+	if len(f.languages) == 0 {
+		return ""
+	}
+
+	return f.languages[0]
+}
+
+// Format renders message under provided key. When message key is missing or
+// message uses undefined function or placeholder, the next language from
+// fallback chain is used.
+func (f *Formatter) Format(key string, arguments ...interface{}) (string, error) {
+	return f.format(key, func(c *catalog.Catalog) (string, error) {
+		return c.Format(key, arguments...)
+	})
+}
+
+// FormatPlural renders plural form of message under provided key selected
+// for count using plural rule of language. Fallback works like in Format.
+func (f *Formatter) FormatPlural(key string, count int64, arguments ...interface{}) (string, error) {
+	return f.format(key, func(c *catalog.Catalog) (string, error) {
+		return c.FormatPlural(key, count, arguments...)
+	})
+}
+
+func (f *Formatter) format(key string, render func(c *catalog.Catalog) (string, error)) (string, error) {
+	var last error
+
+	for _, language := range f.languages {
+		c := f.bundle.Catalog(language)
+
+		if (c == nil) || !c.Has(key) {
+			continue
+		}
+
+		formatted, err := render(c)
+
+		var undefined *formatter.UndefinedFunctionError
+
+		if errors.As(err, &undefined) {
+			last = err
+			continue
+		}
+
+		return formatted, err
+	}
+
+	if last != nil {
+		return "", last
+	}
+
+	return "", fmt.Errorf("message %q not found in languages %s", key, strings.Join(f.languages, ", "))
+}
+
+// MustFormat is like Format but panics if message cannot be rendered.
+func (f *Formatter) MustFormat(key string, arguments ...interface{}) string {
+	formatted, err := f.Format(key, arguments...)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return formatted
+}
+
+// Normalize returns language tag in lower case with hyphens, for example
+// pl_PL is normalized to pl-pl.
+func Normalize(language string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(language), "_", "-"))
+}
+
+func fileLanguage(name string) string {
+	base := strings.TrimSuffix(path.Base(name), path.Ext(name))
+
+	if index := strings.LastIndexByte(base, '.'); index >= 0 {
+		base = base[index+1:]
+	}
+
+	return Normalize(base)
+}
@@ -0,0 +1,175 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/tymonx/go-formatter/catalog"
+	"gitlab.com/tymonx/go-formatter/formatter"
+	"gitlab.com/tymonx/go-formatter/i18n"
+)
+
+func testFiles() fstest.MapFS {
+	return fstest.MapFS{
+		"locales/en.yaml":          {Data: []byte("greeting: Hello {name}!\nfarewell: Bye {name}!\ncount: \"{count} files\"\n")},
+		"locales/pl.yaml":          {Data: []byte("greeting: Cześć {name}!\ncount: \"{count} {files}\"\n")},
+		"locales/messages.pl.json": {Data: []byte(`{"date": "{p | date \"2 January\"}"}`)},
+		"locales/files.pl.yaml":    {Data: []byte("files: [\"{p} plik\", \"{p} pliki\", \"{p} plików\"]\n")},
+	}
+}
+
+func ExampleBundle_Formatter() {
+	bundle, err := i18n.Load("en", testFiles(), "locales/*")
+
+	if err != nil {
+		panic(err)
+	}
+
+	f := bundle.Formatter(i18n.ParseAcceptLanguage("pl-PL,pl;q=0.9,en;q=0.8")...)
+
+	fmt.Println(f.MustFormat("greeting", formatter.Named{"name": "Bob"}))
+	fmt.Println(f.MustFormat("farewell", formatter.Named{"name": "Bob"}))
+	// Output:
+	// Cześć Bob!
+	// Bye Bob!
+}
+
+func TestBundleLoad(test *testing.T) {
+	bundle, err := i18n.Load("EN", testFiles(), "locales/*")
+
+	assert.NoError(test, err)
+	assert.Equal(test, []string{"en", "pl"}, bundle.Languages())
+	assert.Equal(test, "en", bundle.GetDefaultLanguage())
+	assert.True(test, bundle.Catalog("pl").Has("date"))
+	assert.Equal(test, "pl", bundle.Catalog("PL").GetFormatter().GetLocale())
+
+	_, err = i18n.Load("en", testFiles(), "missing/*")
+
+	assert.Error(test, err)
+
+	_, err = i18n.Load("en", fstest.MapFS{"en.txt": {}}, "*")
+
+	assert.Error(test, err)
+
+	bundle, err = i18n.Load("en", fstest.MapFS{"msgs[1].en.yaml": {Data: []byte("greeting: Hello\n")}}, "*.yaml")
+
+	assert.NoError(test, err)
+	assert.True(test, bundle.Catalog("en").Has("greeting"))
+
+	_, err = i18n.Load("en", fstest.MapFS{
+		"a.pl.yaml": {Data: []byte("greeting: Cześć\n")},
+		"b.pl.yaml": {Data: []byte("greeting: Hej\n")},
+	}, "*.yaml")
+
+	assert.Error(test, err)
+	assert.Contains(test, err.Error(), `message "greeting" is already defined`)
+}
+
+func TestBundleLoadConcurrent(test *testing.T) {
+	bundle := i18n.NewBundle("en")
+
+	var group sync.WaitGroup
+
+	for index := 0; index < 10; index++ {
+		name := fmt.Sprintf("messages%d.pl.yaml", index)
+		data := fmt.Sprintf("key%d: value\n", index)
+
+		group.Add(1)
+
+		go func() {
+			defer group.Done()
+
+			assert.NoError(test, bundle.Load(fstest.MapFS{name: {Data: []byte(data)}}, "*.yaml"))
+		}()
+	}
+
+	group.Wait()
+
+	assert.Len(test, bundle.Catalog("pl").Keys(), 10)
+}
+
+func TestBundleFormatter(test *testing.T) {
+	bundle, err := i18n.Load("en", testFiles(), "locales/*")
+
+	assert.NoError(test, err)
+
+	f := bundle.Formatter("pl_PL", "de")
+
+	assert.Equal(test, []string{"pl", "en"}, f.Languages())
+	assert.Equal(test, "pl", f.Language())
+
+	formatted, err := f.Format("date", time.Date(2020, time.March, 5, 0, 0, 0, 0, time.UTC))
+
+	assert.NoError(test, err)
+	assert.Equal(test, "5 marca", formatted)
+
+	formatted, err = f.Format("count", formatter.Named{"count": 3})
+
+	assert.NoError(test, err)
+	assert.Equal(test, "3 files", formatted)
+
+	formatted, err = f.FormatPlural("files", 5, 5)
+
+	assert.NoError(test, err)
+	assert.Equal(test, "5 plików", formatted)
+
+	_, err = f.Format("missing")
+
+	assert.EqualError(test, err, `message "missing" not found in languages pl, en`)
+	assert.Panics(test, func() { f.MustFormat("missing") })
+
+	bundle.AddCatalog("de", catalog.New().Add("count", "{count} Dateien {unknown}"))
+
+	_, err = bundle.Formatter("de").Format("count", formatter.Named{"count": 3})
+
+	assert.NoError(test, err)
+
+	_, err = i18n.NewBundle("de").AddCatalog("de", catalog.New().Add("key", "{unknown}")).Formatter().Format("key")
+
+	assert.Error(test, err)
+	assert.Empty(test, i18n.NewBundle("en").Formatter("pl").Language())
+}
+
+func TestParseAcceptLanguage(test *testing.T) {
+	assert.Equal(test, []string{"pl-pl", "en", "de"}, i18n.ParseAcceptLanguage("de;q=0.5, *;q=0.1, pl-PL, en;q=0.8, fr;q=0"))
+	assert.Empty(test, i18n.ParseAcceptLanguage(""))
+}
+
+func TestBundleLint(test *testing.T) {
+	bundle, err := i18n.Load("en", testFiles(), "locales/*")
+
+	assert.NoError(test, err)
+
+	var issues []string
+
+	for _, issue := range bundle.Lint() {
+		issues = append(issues, issue.String())
+	}
+
+	assert.Equal(test, []string{
+		`pl: "count": extra placeholders files`,
+		`pl: "date": message is not defined in source`,
+		`pl: "farewell": message is missing`,
+		`pl: "files": message is not defined in source`,
+	}, issues)
+
+	assert.Nil(test, i18n.NewBundle("en").Lint())
+}
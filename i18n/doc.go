@@ -0,0 +1,27 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package i18n implements bundles of message catalogs for multiple languages.
+
+Bundle holds a message catalog per language. Formatter returned by bundle
+renders messages in requested language and falls back to more generic
+languages (pl-PL → pl) and finally to the default language when message key
+or function used by message is missing in requested language. Example:
+
+	bundle, err := i18n.Load("en", os.DirFS("locales"), "*.yaml")
+
+	formatted, err := bundle.Formatter("pl-PL").Format("greeting", formatter.Named{"name": "Bob"})
+*/
+package i18n
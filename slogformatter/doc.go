@@ -0,0 +1,29 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package slogformatter integrates formatter with the log/slog package.
+
+Handler wraps another slog handler and formats record messages using
+placeholders resolved from record attributes. Attributes added in groups are
+available under group names like {request.id}. Example:
+
+	logger := slog.New(slogformatter.NewHandler(slog.NewTextHandler(os.Stderr, nil), nil))
+
+	logger.Info("user {user_id} logged in", "user_id", 42)
+
+Attributes are kept in record. Message that cannot be formatted is logged
+unchanged with additional attribute describing the formatting error.
+*/
+package slogformatter
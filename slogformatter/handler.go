@@ -0,0 +1,184 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slogformatter
+
+import (
+	"context"
+	"log/slog"
+
+	"gitlab.com/tymonx/go-formatter/formatter"
+)
+
+// ErrorKey is key of attribute added to record when message cannot be
+// formatted.
+const ErrorKey = "formatError"
+
+// Handler formats record messages using placeholders resolved from record
+// attributes and passes records to the next handler.
+type Handler struct {
+	next      slog.Handler
+	formatter *formatter.Formatter
+	named     formatter.Named
+	groups    []string
+}
+
+// NewHandler creates a new handler that formats messages with provided
+// formatter and passes records to next handler. Default formatter is used
+// if formatter is nil.
+func NewHandler(next slog.Handler, f *formatter.Formatter) *Handler {
+	if f == nil {
+		f = formatter.New()
+	}
+
+	return &Handler{
+		next:      next,
+		formatter: f,
+		named:     formatter.Named{},
+	}
+}
+
+// Enabled reports whether next handler handles records at given level.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle formats record message and passes record to next handler.
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	named := copyGroups(h.named, h.groups)
+	group := lookupGroup(named, h.groups)
+
+	record.Attrs(func(attr slog.Attr) bool {
+		addAttr(group, attr)
+		return true
+	})
+
+	if message, err := h.formatter.Format(record.Message, named); err == nil {
+		record.Message = message
+	} else {
+		record = record.Clone()
+		record.AddAttrs(slog.String(ErrorKey, err.Error()))
+	}
+
+	return h.next.Handle(ctx, record)
+}
+
+// WithAttrs returns a new handler with provided attributes.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	named := copyGroups(h.named, h.groups)
+	group := lookupGroup(named, h.groups)
+
+	for _, attr := range attrs {
+		addAttr(group, attr)
+	}
+
+	return &Handler{
+		next:      h.next.WithAttrs(attrs),
+		formatter: h.formatter,
+		named:     named,
+		groups:    h.groups,
+	}
+}
+
+// WithGroup returns a new handler with provided group name.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &Handler{
+		next:      h.next.WithGroup(name),
+		formatter: h.formatter,
+		named:     h.named,
+		groups:    append(append([]string(nil), h.groups...), name),
+	}
+}
+
+// Attrs returns attributes as named arguments. Group attributes become nested
+// maps.
+func Attrs(attrs ...slog.Attr) formatter.Named {
+	named := formatter.Named{}
+
+	for _, attr := range attrs {
+		addAttr(named, attr)
+	}
+
+	return named
+}
+
+func addAttr(named map[string]interface{}, attr slog.Attr) {
+	value := attr.Value.Resolve()
+
+	if value.Kind() != slog.KindGroup {
+		if attr.Key != "" {
+			named[attr.Key] = value.Any()
+		}
+
+		return
+	}
+
+	group := named
+
+	if attr.Key != "" {
+		original, _ := named[attr.Key].(map[string]interface{})
+		group = make(map[string]interface{}, len(original))
+
+		for key, value := range original {
+			group[key] = value
+		}
+
+		named[attr.Key] = group
+	}
+
+	for _, groupAttr := range value.Group() {
+		addAttr(group, groupAttr)
+	}
+}
+
+// copyGroups returns copy of named arguments with copied nested maps for
+// provided groups, so they can be modified.
+func copyGroups(named formatter.Named, groups []string) formatter.Named {
+	root := make(formatter.Named, len(named))
+
+	for key, value := range named {
+		root[key] = value
+	}
+
+	current := map[string]interface{}(root)
+
+	for _, name := range groups {
+		original, _ := current[name].(map[string]interface{})
+		nested := make(map[string]interface{}, len(original))
+
+		for key, value := range original {
+			nested[key] = value
+		}
+
+		current[name] = nested
+		current = nested
+	}
+
+	return root
+}
+
+// lookupGroup returns nested map for provided groups. Groups must exist.
+func lookupGroup(named formatter.Named, groups []string) map[string]interface{} {
+	current := map[string]interface{}(named)
+
+	for _, name := range groups {
+		current = current[name].(map[string]interface{})
+	}
+
+	return current
+}
@@ -0,0 +1,87 @@
+// Copyright 2020 Tymoteusz Blazejczyk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slogformatter_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/tymonx/go-formatter/formatter"
+	"gitlab.com/tymonx/go-formatter/slogformatter"
+)
+
+func removeTime(groups []string, attr slog.Attr) slog.Attr {
+	if (len(groups) == 0) && (attr.Key == slog.TimeKey) {
+		return slog.Attr{}
+	}
+
+	return attr
+}
+
+func newLogger(buffer *bytes.Buffer, f *formatter.Formatter) *slog.Logger {
+	return slog.New(slogformatter.NewHandler(slog.NewTextHandler(buffer, &slog.HandlerOptions{
+		ReplaceAttr: removeTime,
+	}), f))
+}
+
+func ExampleNewHandler() {
+	logger := slog.New(slogformatter.NewHandler(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		ReplaceAttr: removeTime,
+	}), nil))
+
+	logger.Info("user {user_id} logged in", "user_id", 42)
+	// Output: level=INFO msg="user 42 logged in" user_id=42
+}
+
+func TestHandler(test *testing.T) {
+	var buffer bytes.Buffer
+
+	logger := newLogger(&buffer, nil)
+
+	logger.Info("Plain message")
+	assert.Equal(test, "level=INFO msg=\"Plain message\"\n", buffer.String())
+
+	buffer.Reset()
+	logger.With("service", "api").Warn("{service}: {count} requests", "count", 3)
+	assert.Equal(test, "level=WARN msg=\"api: 3 requests\" service=api count=3\n", buffer.String())
+
+	buffer.Reset()
+	logger.WithGroup("request").With("id", 7).Info("request {request.id} from {request.user.name}",
+		slog.Group("user", "name", "Bob"))
+	assert.Equal(test, "level=INFO msg=\"request 7 from Bob\" request.id=7 request.user.name=Bob\n", buffer.String())
+
+	buffer.Reset()
+	logger.Info("missing {unknown}")
+	assert.Contains(test, buffer.String(), "msg=\"missing {unknown}\" formatError=")
+
+	buffer.Reset()
+	newLogger(&buffer, formatter.New(formatter.WithDelimiters("<", ">"))).Info("value <v>", "v", 1.5)
+	assert.Equal(test, "level=INFO msg=\"value 1.5\" v=1.5\n", buffer.String())
+
+	assert.False(test, logger.Handler().Enabled(context.Background(), slog.LevelDebug))
+	assert.Equal(test, logger.Handler(), logger.Handler().WithGroup(""))
+}
+
+func TestAttrs(test *testing.T) {
+	assert.Equal(test, formatter.Named{
+		"id":   int64(1),
+		"user": map[string]interface{}{"name": "Bob"},
+		"flat": "inline",
+	}, slogformatter.Attrs(slog.Int("id", 1), slog.Group("user", "name", "Bob"), slog.Group("", "flat", "inline")))
+}